@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/config"
+	"github.com/GhostVox/ghostvox.io-backend/internal/handlers"
+	"github.com/google/uuid"
+)
+
+// importPollFile is the bulk import shared with the admin endpoint
+type importPollFile func(ctx context.Context, file io.Reader, format string, userUUID uuid.UUID) (handlers.ImportReport, error)
+
+// runImport is the command line path of the bulk poll import:
+//
+//	ghostvox import -creator <admin user ID> [-format csv|json] <file>
+//
+// The format defaults to the file's extension. The report is written to
+// stdout, and the exit code is 1 when the import or any row failed.
+func runImport(cfg *config.APIConfig, importPolls importPollFile, args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	creator := flags.String("creator", "", "ID of the admin the imported polls belong to")
+	format := flags.String("format", "", "csv or json, taken from the file extension when empty")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: ghostvox import -creator <admin user ID> [-format csv|json] <file>")
+		return 2
+	}
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	if *format != "csv" && *format != "json" {
+		fmt.Fprintln(os.Stderr, "format must be csv or json")
+		return 2
+	}
+
+	creatorUUID, err := uuid.Parse(*creator)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "creator must be a user ID")
+		return 2
+	}
+	user, err := cfg.Queries.GetUserById(context.Background(), creatorUUID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading creator: %v\n", err)
+		return 1
+	}
+	if !strings.EqualFold(user.Role, "admin") {
+		fmt.Fprintln(os.Stderr, "creator must be an admin")
+		return 1
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening import file: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+
+	report, err := importPolls(context.Background(), file, *format, creatorUUID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error importing polls: %v\n", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Imported %d of %d polls, %d failed\n", report.Created, report.Total, report.Failed)
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// GuestVoterCookie holds the signed voter ID of a logged-out visitor on polls
+// that accept guest votes.
+const GuestVoterCookie = "guestVoter"
+
+// MakeGuestToken signs a guest voter ID so it can be handed to the browser.
+// The token is the ID followed by an HMAC-SHA256 of it.
+func MakeGuestToken(guestID uuid.UUID, secretKey string) string {
+	return guestID.String() + "." + signGuestID(guestID, secretKey)
+}
+
+// ValidateGuestToken checks the signature on a guest token and returns the
+// guest voter ID it carries.
+func ValidateGuestToken(token, secretKey string) (uuid.UUID, error) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, errors.New("guest token malformed")
+	}
+	guestID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("guest token malformed: %w", err)
+	}
+	if !hmac.Equal([]byte(signature), []byte(signGuestID(guestID, secretKey))) {
+		return uuid.Nil, errors.New("invalid guest token")
+	}
+	return guestID, nil
+}
+
+func signGuestID(guestID uuid.UUID, secretKey string) string {
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write([]byte("guest:" + guestID.String()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateGuestToken(t *testing.T) {
+	secret := "test_secret"
+	guestID := uuid.New()
+	token := MakeGuestToken(guestID, secret)
+
+	t.Run("Valid token", func(t *testing.T) {
+		got, err := ValidateGuestToken(token, secret)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if got != guestID {
+			t.Fatalf("expected %s, got %s", guestID, got)
+		}
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		if _, err := ValidateGuestToken(token, "other_secret"); err == nil {
+			t.Fatalf("expected an error for a token signed with another secret")
+		}
+	})
+
+	t.Run("Swapped ID", func(t *testing.T) {
+		forged := uuid.New().String() + token[len(guestID.String()):]
+		if _, err := ValidateGuestToken(forged, secret); err == nil {
+			t.Fatalf("expected an error for a forged guest ID")
+		}
+	})
+
+	t.Run("Malformed token", func(t *testing.T) {
+		if _, err := ValidateGuestToken("not-a-token", secret); err == nil {
+			t.Fatalf("expected an error for a malformed token")
+		}
+	})
+}
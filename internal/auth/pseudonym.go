@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+)
+
+// pseudonymLength is how many hex characters of the HMAC a pseudonym keeps
+const pseudonymLength = 16
+
+// MakePseudonym replaces a user or guest ID in exported data. The same person
+// gets the same pseudonym throughout one poll, but pseudonyms from different
+// polls cannot be linked to each other or back to the ID without the secret.
+func MakePseudonym(id, pollID uuid.UUID, secretKey string) string {
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write([]byte("pseudonym:" + pollID.String() + ":" + id.String()))
+	return hex.EncodeToString(mac.Sum(nil))[:pseudonymLength]
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestMakePseudonym(t *testing.T) {
+	secret := "test_secret"
+	voter := uuid.New()
+	pollID := uuid.New()
+	pseudonym := MakePseudonym(voter, pollID, secret)
+
+	t.Run("Stable within a poll", func(t *testing.T) {
+		if again := MakePseudonym(voter, pollID, secret); again != pseudonym {
+			t.Fatalf("expected %q, got %q", pseudonym, again)
+		}
+		if len(pseudonym) != pseudonymLength {
+			t.Fatalf("expected %d characters, got %d", pseudonymLength, len(pseudonym))
+		}
+	})
+
+	t.Run("Unlinkable across polls", func(t *testing.T) {
+		if MakePseudonym(voter, uuid.New(), secret) == pseudonym {
+			t.Fatalf("expected a different pseudonym on another poll")
+		}
+	})
+
+	t.Run("Distinct voters", func(t *testing.T) {
+		if MakePseudonym(uuid.New(), pollID, secret) == pseudonym {
+			t.Fatalf("expected a different pseudonym for another voter")
+		}
+	})
+}
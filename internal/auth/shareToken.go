@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/google/uuid"
+)
+
+// MakeShareToken signs a poll ID so an unlisted poll can be opened by anyone
+// holding the link. The token is an HMAC-SHA256 of the poll ID, the ID itself
+// travels in the URL path.
+func MakeShareToken(pollID uuid.UUID, secretKey string) string {
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write([]byte("share:" + pollID.String()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateShareToken reports whether token was issued for pollID.
+func ValidateShareToken(token string, pollID uuid.UUID, secretKey string) bool {
+	return token != "" && hmac.Equal([]byte(token), []byte(MakeShareToken(pollID, secretKey)))
+}
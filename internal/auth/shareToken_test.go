@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateShareToken(t *testing.T) {
+	secret := "test_secret"
+	pollID := uuid.New()
+	token := MakeShareToken(pollID, secret)
+
+	t.Run("Valid token", func(t *testing.T) {
+		if !ValidateShareToken(token, pollID, secret) {
+			t.Fatalf("expected the token to be valid")
+		}
+	})
+
+	t.Run("Other poll", func(t *testing.T) {
+		if ValidateShareToken(token, uuid.New(), secret) {
+			t.Fatalf("expected a token for another poll to be rejected")
+		}
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		if ValidateShareToken(token, pollID, "other_secret") {
+			t.Fatalf("expected a token signed with another secret to be rejected")
+		}
+	})
+
+	t.Run("Empty token", func(t *testing.T) {
+		if ValidateShareToken("", pollID, secret) {
+			t.Fatalf("expected an empty token to be rejected")
+		}
+	})
+}
@@ -8,19 +8,20 @@ import (
 )
 
 type APIConfig struct {
-	DB                *sql.DB
-	Queries           *database.Queries
-	Platform          string
-	Port              string
-	AccessTokenExp    time.Duration
-	RefreshTokenExp   time.Duration
-	GhostvoxSecretKey string
-	Mode              string
-	UseHTTPS          string
-	AccessOrigin      string
-	AwsS3Bucket       string
-	AwsRegion         string
-	DOMAIN            string
+	DB                   *sql.DB
+	Queries              *database.Queries
+	Platform             string
+	Port                 string
+	AccessTokenExp       time.Duration
+	RefreshTokenExp      time.Duration
+	GhostvoxSecretKey    string
+	Mode                 string
+	UseHTTPS             string
+	AccessOrigin         string
+	AwsS3Bucket          string
+	AwsRegion            string
+	DOMAIN               string
+	DeletedPollRetention time.Duration
 }
 type OAuthUser struct {
 	Email        string `json:"email,omitempty"`
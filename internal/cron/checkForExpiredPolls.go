@@ -8,12 +8,24 @@ import (
 	"time"
 
 	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/GhostVox/ghostvox.io-backend/internal/tally"
 	"github.com/GhostVox/ghostvox.io-backend/internal/utils"
+	"github.com/google/uuid"
 )
 
 const jobName = "checkForExpiredPolls"
 
-func UpdateExpiredPolls(ctx context.Context, q *database.Queries, logger *utils.Logger) {
+// expiredPollResult is what closing an expired poll did to it
+type expiredPollResult int
+
+const (
+	pollArchived expiredPollResult = iota
+	pollExtendedForQuorum
+	// The poll changed after it was listed, e.g. its creator extended it
+	pollSkipped
+)
+
+func UpdateExpiredPolls(ctx context.Context, db *sql.DB, q *database.Queries, logger *utils.Logger) {
 	if logger == nil {
 		fmt.Println("Logger is nil")
 		return
@@ -21,6 +33,8 @@ func UpdateExpiredPolls(ctx context.Context, q *database.Queries, logger *utils.
 
 	successCount := 0
 	failureCount := 0
+	extendedCount := 0
+	skippedCount := 0
 	expiredPolls, err := q.GetExpiredPollsToUpdate(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -33,20 +47,81 @@ func UpdateExpiredPolls(ctx context.Context, q *database.Queries, logger *utils.
 	}
 
 	for _, poll := range expiredPolls {
-		_, err := q.UpdatePollStatus(ctx, database.UpdatePollStatusParams{
-			ID:     poll.ID,
-			Status: database.PollStatus(database.PollStatusArchived),
-		})
+		result, err := closeExpiredPoll(ctx, db, q, poll.ID)
 		if err != nil {
-			logger.LogError(fmt.Errorf("poll %s failed to update: %v", poll.ID.String(), err))
+			logger.LogError(fmt.Errorf("poll %s failed to close: %v", poll.ID.String(), err))
 			failureCount++
 			continue
 		}
-		successCount++
+		switch result {
+		case pollArchived:
+			successCount++
+		case pollExtendedForQuorum:
+			extendedCount++
+		case pollSkipped:
+			skippedCount++
+		}
 	}
-	logger.LogJob(jobName, fmt.Sprintf("Processed %d polls: %d updated successfully, %d failed",
-		len(expiredPolls), successCount, failureCount))
+	logger.LogJob(jobName, fmt.Sprintf("Processed %d polls: %d updated successfully, %d extended for quorum, %d skipped, %d failed",
+		len(expiredPolls), successCount, extendedCount, skippedCount, failureCount))
 
 	logger.WriteToFile(fmt.Sprintf("%s-updatepolls", time.Now().Format("2006-01-02")))
 
 }
+
+// closeExpiredPoll archives an expired poll, or extends it once when it fell
+// short of its quorum and the creator opted in. The poll row is locked for
+// the whole close so no ballot or lifecycle change lands between counting
+// the voters, freezing a ranked result and archiving.
+func closeExpiredPoll(ctx context.Context, db *sql.DB, q *database.Queries, pollID uuid.UUID) (expiredPollResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	qtx := q.WithTx(tx)
+
+	poll, err := qtx.GetPollForVote(ctx, pollID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pollSkipped, nil
+		}
+		return 0, err
+	}
+	if poll.Status != database.PollStatusActive || poll.ExpiresAt.After(time.Now()) {
+		return pollSkipped, nil
+	}
+
+	outcome, err := tally.PollOutcome(ctx, qtx, poll)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count voters: %w", err)
+	}
+
+	// Creators can opt in to one extension for a poll short of its quorum
+	if outcome == database.PollOutcomeNoQuorum && poll.QuorumExtensionDays > 0 && !poll.QuorumExtended {
+		_, err := qtx.ExtendPollForQuorum(ctx, database.ExtendPollForQuorumParams{
+			ID:        poll.ID,
+			ExpiresAt: time.Now().AddDate(0, 0, int(poll.QuorumExtensionDays)),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to extend: %w", err)
+		}
+		return pollExtendedForQuorum, tx.Commit()
+	}
+
+	if poll.PollType == database.PollTypeRanked {
+		if err := tally.FreezeRanked(ctx, qtx, poll.ID); err != nil {
+			return 0, fmt.Errorf("failed to freeze result: %w", err)
+		}
+	}
+
+	_, err = qtx.UpdatePollStatus(ctx, database.UpdatePollStatusParams{
+		ID:      poll.ID,
+		Status:  database.PollStatus(database.PollStatusArchived),
+		Outcome: database.NullPollOutcome{PollOutcome: outcome, Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to update: %w", err)
+	}
+	return pollArchived, tx.Commit()
+}
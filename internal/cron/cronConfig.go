@@ -16,9 +16,11 @@ type CronConfig struct {
 	Scheduler            *cron.Cron
 	logger               *utils.Logger
 	CheckForExpiredPolls string
+	OpenScheduledPolls   string
+	PurgeDeletedPolls    string
 }
 
-func NewCronConfig(checkForExpiredPolls string) *CronConfig {
+func NewCronConfig(checkForExpiredPolls, openScheduledPolls, purgeDeletedPolls string) *CronConfig {
 	buffer := bytes.NewBuffer([]byte{})
 
 	return &CronConfig{
@@ -29,6 +31,8 @@ func NewCronConfig(checkForExpiredPolls string) *CronConfig {
 		)),
 		logger:               utils.NewLogger(buffer),
 		CheckForExpiredPolls: checkForExpiredPolls,
+		OpenScheduledPolls:   openScheduledPolls,
+		PurgeDeletedPolls:    purgeDeletedPolls,
 	}
 }
 
@@ -45,7 +49,7 @@ func (c *CronConfig) StartCronJobs(ctx context.Context, cfg *config.APIConfig) {
 	updatePollJobID, err := c.Scheduler.AddFunc(c.CheckForExpiredPolls, func() {
 		jobCtx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
 		defer cancel()
-		UpdateExpiredPolls(jobCtx, cfg.Queries, c.logger)
+		UpdateExpiredPolls(jobCtx, cfg.DB, cfg.Queries, c.logger)
 	})
 	if err != nil {
 		c.logger.LogError(err)
@@ -53,6 +57,28 @@ func (c *CronConfig) StartCronJobs(ctx context.Context, cfg *config.APIConfig) {
 	}
 	c.Jobs["updatePolls"] = updatePollJobID
 
+	openPollsJobID, err := c.Scheduler.AddFunc(c.OpenScheduledPolls, func() {
+		jobCtx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+		defer cancel()
+		OpenScheduledPolls(jobCtx, cfg.Queries, c.logger)
+	})
+	if err != nil {
+		c.logger.LogError(err)
+		return
+	}
+	c.Jobs["openPolls"] = openPollsJobID
+
+	purgePollsJobID, err := c.Scheduler.AddFunc(c.PurgeDeletedPolls, func() {
+		jobCtx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+		defer cancel()
+		PurgeDeletedPolls(jobCtx, cfg.Queries, cfg.DeletedPollRetention, c.logger)
+	})
+	if err != nil {
+		c.logger.LogError(err)
+		return
+	}
+	c.Jobs["purgePolls"] = purgePollsJobID
+
 	c.Scheduler.Start()
 
 }
@@ -0,0 +1,34 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/GhostVox/ghostvox.io-backend/internal/utils"
+)
+
+const openPollsJobName = "openScheduledPolls"
+
+// OpenScheduledPolls activates polls that were created with a future start
+// time once that time has passed.
+func OpenScheduledPolls(ctx context.Context, q *database.Queries, logger *utils.Logger) {
+	if logger == nil {
+		fmt.Println("Logger is nil")
+		return
+	}
+
+	opened, err := q.OpenScheduledPolls(ctx)
+	if err != nil {
+		logger.LogError(err)
+		return
+	}
+	if len(opened) == 0 {
+		return
+	}
+
+	logger.LogJob(openPollsJobName, fmt.Sprintf("Opened %d scheduled polls", len(opened)))
+
+	logger.WriteToFile(fmt.Sprintf("%s-openpolls", time.Now().Format("2006-01-02")))
+}
@@ -0,0 +1,35 @@
+package cron
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/GhostVox/ghostvox.io-backend/internal/utils"
+)
+
+const purgePollsJobName = "purgeDeletedPolls"
+
+// PurgeDeletedPolls removes polls that have been in the trash longer than
+// retention, along with their options, votes and comments.
+func PurgeDeletedPolls(ctx context.Context, q *database.Queries, retention time.Duration, logger *utils.Logger) {
+	if logger == nil {
+		fmt.Println("Logger is nil")
+		return
+	}
+
+	purged, err := q.PurgeDeletedPolls(ctx, sql.NullTime{Time: time.Now().Add(-retention), Valid: true})
+	if err != nil {
+		logger.LogError(err)
+		return
+	}
+	if purged == 0 {
+		return
+	}
+
+	logger.LogJob(purgePollsJobName, fmt.Sprintf("Purged %d deleted polls", purged))
+
+	logger.WriteToFile(fmt.Sprintf("%s-purgepolls", time.Now().Format("2006-01-02")))
+}
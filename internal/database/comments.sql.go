@@ -60,14 +60,23 @@ func (q *Queries) DeleteComment(ctx context.Context, arg DeleteCommentParams) er
 }
 
 const getAllCommentsByPollID = `-- name: GetAllCommentsByPollID :many
- -- in Use in commenthandler.GetAllPollComments
+ -- in Use in commenthandler.GetAllPollComments, pages by (created_at, id) starting after the cursor
 SELECT comments.id, comments.user_id, comments.poll_id, comments.content, comments.created_at, comments.updated_at, users.user_name as userName, users.picture_url as avatar_url
 FROM comments
 JOIN users ON comments.user_id = users.id
 WHERE poll_id = $1
-Order By comments.created_at DESC
+    AND ($2::timestamp IS NULL OR (comments.created_at, comments.id) < ($2::timestamp, $3::uuid))
+Order By comments.created_at DESC, comments.id DESC
+LIMIT $4
 `
 
+type GetAllCommentsByPollIDParams struct {
+	PollID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageSize        int32
+}
+
 type GetAllCommentsByPollIDRow struct {
 	ID        uuid.UUID
 	UserID    uuid.UUID
@@ -79,8 +88,13 @@ type GetAllCommentsByPollIDRow struct {
 	AvatarUrl sql.NullString
 }
 
-func (q *Queries) GetAllCommentsByPollID(ctx context.Context, pollID uuid.UUID) ([]GetAllCommentsByPollIDRow, error) {
-	rows, err := q.db.QueryContext(ctx, getAllCommentsByPollID, pollID)
+func (q *Queries) GetAllCommentsByPollID(ctx context.Context, arg GetAllCommentsByPollIDParams) ([]GetAllCommentsByPollIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAllCommentsByPollID,
+		arg.PollID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -7,18 +7,62 @@ package database
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type PollOutcome string
+
+const (
+	PollOutcomeDecided  PollOutcome = "decided"
+	PollOutcomeNoQuorum PollOutcome = "no_quorum"
+)
+
+func (e *PollOutcome) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PollOutcome(s)
+	case string:
+		*e = PollOutcome(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PollOutcome: %T", src)
+	}
+	return nil
+}
+
+type NullPollOutcome struct {
+	PollOutcome PollOutcome
+	Valid       bool // Valid is true if PollOutcome is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPollOutcome) Scan(value interface{}) error {
+	if value == nil {
+		ns.PollOutcome, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PollOutcome.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPollOutcome) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PollOutcome), nil
+}
+
 type PollStatus string
 
 const (
 	PollStatusActive   PollStatus = "Active"
 	PollStatusInactive PollStatus = "Inactive"
 	PollStatusArchived PollStatus = "Archived"
+	PollStatusDraft    PollStatus = "Draft"
 )
 
 func (e *PollStatus) Scan(src interface{}) error {
@@ -56,6 +100,187 @@ func (ns NullPollStatus) Value() (driver.Value, error) {
 	return string(ns.PollStatus), nil
 }
 
+type PollType string
+
+const (
+	PollTypeStandard PollType = "Standard"
+	PollTypeRanked   PollType = "Ranked"
+	PollTypeRating   PollType = "Rating"
+)
+
+func (e *PollType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PollType(s)
+	case string:
+		*e = PollType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PollType: %T", src)
+	}
+	return nil
+}
+
+type NullPollType struct {
+	PollType PollType
+	Valid    bool // Valid is true if PollType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPollType) Scan(value interface{}) error {
+	if value == nil {
+		ns.PollType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PollType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPollType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PollType), nil
+}
+
+type PollVisibility string
+
+const (
+	PollVisibilityPublic   PollVisibility = "public"
+	PollVisibilityUnlisted PollVisibility = "unlisted"
+	PollVisibilityPrivate  PollVisibility = "private"
+)
+
+func (e *PollVisibility) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PollVisibility(s)
+	case string:
+		*e = PollVisibility(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PollVisibility: %T", src)
+	}
+	return nil
+}
+
+type NullPollVisibility struct {
+	PollVisibility PollVisibility
+	Valid          bool // Valid is true if PollVisibility is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPollVisibility) Scan(value interface{}) error {
+	if value == nil {
+		ns.PollVisibility, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PollVisibility.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPollVisibility) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PollVisibility), nil
+}
+
+type ResultsVisibility string
+
+const (
+	ResultsVisibilityAlways     ResultsVisibility = "always"
+	ResultsVisibilityAfterVote  ResultsVisibility = "after_vote"
+	ResultsVisibilityAfterClose ResultsVisibility = "after_close"
+)
+
+func (e *ResultsVisibility) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ResultsVisibility(s)
+	case string:
+		*e = ResultsVisibility(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ResultsVisibility: %T", src)
+	}
+	return nil
+}
+
+type NullResultsVisibility struct {
+	ResultsVisibility ResultsVisibility
+	Valid             bool // Valid is true if ResultsVisibility is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullResultsVisibility) Scan(value interface{}) error {
+	if value == nil {
+		ns.ResultsVisibility, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ResultsVisibility.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullResultsVisibility) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ResultsVisibility), nil
+}
+
+type TieBreak string
+
+const (
+	TieBreakNone         TieBreak = "none"
+	TieBreakEarliestVote TieBreak = "earliest_vote"
+	TieBreakCreator      TieBreak = "creator"
+)
+
+func (e *TieBreak) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TieBreak(s)
+	case string:
+		*e = TieBreak(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TieBreak: %T", src)
+	}
+	return nil
+}
+
+type NullTieBreak struct {
+	TieBreak TieBreak
+	Valid    bool // Valid is true if TieBreak is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTieBreak) Scan(value interface{}) error {
+	if value == nil {
+		ns.TieBreak, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TieBreak.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTieBreak) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TieBreak), nil
+}
+
+type Category struct {
+	Slug        string
+	Name        string
+	Description string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
 type Comment struct {
 	ID        uuid.UUID
 	UserID    uuid.UUID
@@ -72,18 +297,137 @@ type Option struct {
 	Count     int32
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	Position  int32
 }
 
 type Poll struct {
-	ID          uuid.UUID
-	UserID      uuid.UUID
-	Title       string
-	Description string
-	Category    string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	ExpiresAt   time.Time
-	Status      PollStatus
+	ID                  uuid.UUID
+	UserID              uuid.UUID
+	Title               string
+	Description         string
+	Category            string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	ExpiresAt           time.Time
+	Status              PollStatus
+	MaxChoices          int32
+	PollType            PollType
+	RatingMax           int32
+	VotesLocked         bool
+	AllowGuestVotes     bool
+	StartsAt            time.Time
+	Visibility          PollVisibility
+	ResultsVisibility   ResultsVisibility
+	TieBreak            TieBreak
+	TieWinnerOptionID   uuid.NullUUID
+	Quorum              int32
+	QuorumExtensionDays int32
+	QuorumExtended      bool
+	Outcome             NullPollOutcome
+	DeletedAt           sql.NullTime
+	ClonedFrom          uuid.NullUUID
+}
+
+type PollAllowlist struct {
+	ID        uuid.UUID
+	PollID    uuid.UUID
+	UserID    uuid.NullUUID
+	Email     sql.NullString
+	CreatedAt time.Time
+}
+
+type PollDetail struct {
+	ID                uuid.UUID
+	Title             string
+	Category          string
+	Description       string
+	ExpiresAt         time.Time
+	Status            PollStatus
+	MaxChoices        int32
+	PollType          PollType
+	RatingMax         int32
+	VotesLocked       bool
+	AllowGuestVotes   bool
+	StartsAt          time.Time
+	Visibility        PollVisibility
+	ResultsVisibility ResultsVisibility
+	CreatorID         uuid.UUID
+	TieBreak          TieBreak
+	TieWinner         uuid.NullUUID
+	Quorum            int32
+	Outcome           NullPollOutcome
+	ClonedFrom        uuid.NullUUID
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	CreatorFirstName  string
+	CreatorLastName   sql.NullString
+	Votes             int64
+	Comments          int64
+	Voters            int64
+	Options           json.RawMessage
+	RatingStats       json.RawMessage
+	FinalWinner       uuid.NullUUID
+	ReachedAt         json.RawMessage
+	Tags              []string
+}
+
+type PollEditor struct {
+	PollID    uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type PollRatingStat struct {
+	PollID       uuid.UUID
+	OptionID     uuid.UUID
+	Ratings      int64
+	Mean         float64
+	Median       float64
+	Distribution json.RawMessage
+}
+
+type PollResult struct {
+	PollID         uuid.UUID
+	WinnerOptionID uuid.NullUUID
+	Tally          json.RawMessage
+	CreatedAt      time.Time
+}
+
+type PollSearch struct {
+	PollID   uuid.UUID
+	Body     string
+	Document interface{}
+}
+
+type PollTag struct {
+	PollID uuid.UUID
+	TagID  uuid.UUID
+}
+
+type PollTemplate struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Name         string
+	TitlePattern string
+	Description  string
+	Category     string
+	Options      []string
+	DurationDays int32
+	PollType     PollType
+	MaxChoices   int32
+	RatingMax    int32
+	IsGlobal     bool
+	CreatedAt    time.Time
+}
+
+type Rating struct {
+	ID        uuid.UUID
+	PollID    uuid.UUID
+	OptionID  uuid.UUID
+	UserID    uuid.NullUUID
+	Score     int32
+	CreatedAt time.Time
+	GuestID   uuid.NullUUID
 }
 
 type RefreshToken struct {
@@ -100,6 +444,12 @@ type Restrictedword struct {
 	UpdatedAt time.Time
 }
 
+type Tag struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedAt time.Time
+}
+
 type User struct {
 	ID             uuid.UUID
 	CreatedAt      time.Time
@@ -120,5 +470,7 @@ type Vote struct {
 	PollID    uuid.UUID
 	OptionID  uuid.UUID
 	CreatedAt time.Time
-	UserID    uuid.UUID
+	UserID    uuid.NullUUID
+	Rank      sql.NullInt32
+	GuestID   uuid.NullUUID
 }
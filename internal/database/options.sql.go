@@ -13,9 +13,55 @@ import (
 	"github.com/lib/pq"
 )
 
+const countOptionsInPoll = `-- name: CountOptionsInPoll :one
+SELECT COUNT(*) FROM options
+WHERE poll_id = $1 AND id = ANY($2::uuid[])
+`
+
+type CountOptionsInPollParams struct {
+	PollID  uuid.UUID
+	Column2 []uuid.UUID
+}
+
+// in use by transaction createVotesAndUpdateOptionCounts
+func (q *Queries) CountOptionsInPoll(ctx context.Context, arg CountOptionsInPollParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOptionsInPoll, arg.PollID, pq.Array(arg.Column2))
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createOption = `-- name: CreateOption :one
+INSERT INTO options (poll_id, name, position)
+VALUES ($1, $2, (SELECT COALESCE(MAX(position), 0) + 1 FROM options WHERE poll_id = $1))
+RETURNING id, name, poll_id, count, created_at, updated_at, position
+`
+
+type CreateOptionParams struct {
+	PollID uuid.UUID
+	Name   string
+}
+
+// in use by transaction AddOptionToPoll, appends the option after the existing ones
+func (q *Queries) CreateOption(ctx context.Context, arg CreateOptionParams) (Option, error) {
+	row := q.db.QueryRowContext(ctx, createOption, arg.PollID, arg.Name)
+	var i Option
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.PollID,
+		&i.Count,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Position,
+	)
+	return i, err
+}
+
 const createOptions = `-- name: CreateOptions :execrows
-INSERT INTO options (poll_id, name)
-VALUES ($1, UNNEST($2::text[]))
+INSERT INTO options (poll_id, name, position)
+SELECT $1, new_options.name, new_options.position
+FROM UNNEST($2::text[]) WITH ORDINALITY AS new_options (name, position)
 RETURNING id, name, created_at, updated_at, poll_id
 `
 
@@ -24,7 +70,7 @@ type CreateOptionsParams struct {
 	Column2 []string
 }
 
-// in use by transaction createPollWithOptions
+// in use by transaction createPollWithOptions, positions follow the order given
 func (q *Queries) CreateOptions(ctx context.Context, arg CreateOptionsParams) (int64, error) {
 	result, err := q.db.ExecContext(ctx, createOptions, arg.PollID, pq.Array(arg.Column2))
 	if err != nil {
@@ -33,20 +79,70 @@ func (q *Queries) CreateOptions(ctx context.Context, arg CreateOptionsParams) (i
 	return result.RowsAffected()
 }
 
+const decrementOptionCount = `-- name: DecrementOptionCount :exec
+UPDATE options
+SET count = count - 1, updated_at = now()
+WHERE id = $1 AND count > 0
+`
+
+// in use by transaction retractBallot
+func (q *Queries) DecrementOptionCount(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, decrementOptionCount, id)
+	return err
+}
+
 const deleteOption = `-- name: DeleteOption :exec
 DELETE FROM options
 WHERE id = $1
 `
 
-// used by optionHandler.deleteOption
+// in use by transaction RemovePollOption
 func (q *Queries) DeleteOption(ctx context.Context, id uuid.UUID) error {
 	_, err := q.db.ExecContext(ctx, deleteOption, id)
 	return err
 }
 
+const deletePollOptions = `-- name: DeletePollOptions :exec
+DELETE FROM options
+WHERE poll_id = $1
+`
+
+// in use by transaction SaveDraft, drafts have no votes so their options are replaced as a whole
+func (q *Queries) DeletePollOptions(ctx context.Context, pollID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deletePollOptions, pollID)
+	return err
+}
+
+const getOptionForPoll = `-- name: GetOptionForPoll :one
+SELECT id, name, poll_id, count, created_at, updated_at, position FROM options
+WHERE id = $1 AND poll_id = $2
+`
+
+type GetOptionForPollParams struct {
+	ID     uuid.UUID
+	PollID uuid.UUID
+}
+
+// in use by the option edit transactions
+func (q *Queries) GetOptionForPoll(ctx context.Context, arg GetOptionForPollParams) (Option, error) {
+	row := q.db.QueryRowContext(ctx, getOptionForPoll, arg.ID, arg.PollID)
+	var i Option
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.PollID,
+		&i.Count,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Position,
+	)
+	return i, err
+}
+
 const getOptionsByPollIDs = `-- name: GetOptionsByPollIDs :many
-SELECT id, name, poll_id, count, created_at, updated_at FROM options
+SELECT id, name, poll_id, count, created_at, updated_at, position FROM options
 WHERE poll_id = ANY($1::uuid[])
+ORDER BY poll_id, position
 `
 
 // used by pollhandler.processPollData
@@ -66,6 +162,7 @@ func (q *Queries) GetOptionsByPollIDs(ctx context.Context, dollar_1 []uuid.UUID)
 			&i.Count,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Position,
 		); err != nil {
 			return nil, err
 		}
@@ -80,13 +177,94 @@ func (q *Queries) GetOptionsByPollIDs(ctx context.Context, dollar_1 []uuid.UUID)
 	return items, nil
 }
 
+const optionHasVotes = `-- name: OptionHasVotes :one
+SELECT (
+    EXISTS (SELECT 1 FROM votes WHERE votes.option_id = $1)
+    OR EXISTS (SELECT 1 FROM ratings WHERE ratings.option_id = $1)
+)::boolean as has_votes
+`
+
+// in use by the option edit transactions. Looks at the ballots themselves,
+// options.count only holds first preferences on ranked polls
+func (q *Queries) OptionHasVotes(ctx context.Context, optionID uuid.UUID) (bool, error) {
+	row := q.db.QueryRowContext(ctx, optionHasVotes, optionID)
+	var has_votes bool
+	err := row.Scan(&has_votes)
+	return has_votes, err
+}
+
+const recountOptions = `-- name: RecountOptions :exec
+UPDATE options
+SET
+    count = (SELECT COUNT(*) FROM votes WHERE votes.option_id = options.id AND (votes.rank IS NULL OR votes.rank = 1)),
+    updated_at = now()
+WHERE poll_id = $1
+`
+
+// in use by transaction RemovePollOption after ranked ballots are re-ranked,
+// counts first preferences like castBallot does
+func (q *Queries) RecountOptions(ctx context.Context, pollID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, recountOptions, pollID)
+	return err
+}
+
+const renameOption = `-- name: RenameOption :one
+UPDATE options
+SET name = $2, updated_at = now()
+WHERE id = $1
+RETURNING id, name, poll_id, count, created_at, updated_at, position
+`
+
+type RenameOptionParams struct {
+	ID   uuid.UUID
+	Name string
+}
+
+// in use by transaction RenamePollOption
+func (q *Queries) RenameOption(ctx context.Context, arg RenameOptionParams) (Option, error) {
+	row := q.db.QueryRowContext(ctx, renameOption, arg.ID, arg.Name)
+	var i Option
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.PollID,
+		&i.Count,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Position,
+	)
+	return i, err
+}
+
+const setOptionPosition = `-- name: SetOptionPosition :exec
+UPDATE options
+SET position = $2, updated_at = now()
+WHERE id = $1
+`
+
+type SetOptionPositionParams struct {
+	ID       uuid.UUID
+	Position int32
+}
+
+// in use by transaction ReorderPollOptions
+func (q *Queries) SetOptionPosition(ctx context.Context, arg SetOptionPositionParams) error {
+	_, err := q.db.ExecContext(ctx, setOptionPosition, arg.ID, arg.Position)
+	return err
+}
+
 const updateOptionCount = `-- name: UpdateOptionCount :one
 UPDATE options
 SET count = count + 1, updated_at = now()
-WHERE id = $1
+WHERE id = $1 AND poll_id = $2
 RETURNING id, name, created_at, updated_at, poll_id
 `
 
+type UpdateOptionCountParams struct {
+	ID     uuid.UUID
+	PollID uuid.UUID
+}
+
 type UpdateOptionCountRow struct {
 	ID        uuid.UUID
 	Name      string
@@ -95,9 +273,9 @@ type UpdateOptionCountRow struct {
 	PollID    uuid.UUID
 }
 
-// in use by transaction createVoteAndUpdateOptionCount
-func (q *Queries) UpdateOptionCount(ctx context.Context, id uuid.UUID) (UpdateOptionCountRow, error) {
-	row := q.db.QueryRowContext(ctx, updateOptionCount, id)
+// in use by transaction createVotesAndUpdateOptionCounts
+func (q *Queries) UpdateOptionCount(ctx context.Context, arg UpdateOptionCountParams) (UpdateOptionCountRow, error) {
+	row := q.db.QueryRowContext(ctx, updateOptionCount, arg.ID, arg.PollID)
 	var i UpdateOptionCountRow
 	err := row.Scan(
 		&i.ID,
@@ -12,24 +12,72 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const clampMaxChoices = `-- name: ClampMaxChoices :exec
+UPDATE
+    polls
+SET
+    max_choices = (SELECT COUNT(*) FROM options WHERE options.poll_id = $1),
+    updated_at = now()
+WHERE
+    id = $1 AND max_choices > (SELECT COUNT(*) FROM options WHERE options.poll_id = $1)
+`
+
+// used by transaction RemovePollOption, ballots can't choose more options
+// than the poll has left
+func (q *Queries) ClampMaxChoices(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, clampMaxChoices, id)
+	return err
+}
+
+const countPollVoters = `-- name: CountPollVoters :one
+SELECT
+    COUNT(DISTINCT voter)
+FROM (
+    SELECT COALESCE(votes.user_id, votes.guest_id) as voter FROM votes WHERE votes.poll_id = $1
+    UNION
+    SELECT COALESCE(ratings.user_id, ratings.guest_id) FROM ratings WHERE ratings.poll_id = $1
+) voters
+`
+
+// distinct voters across ballots and ratings, compared against the quorum
+func (q *Queries) CountPollVoters(ctx context.Context, pollID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPollVoters, pollID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPoll = `-- name: CreatePoll :one
 INSERT INTO
-    polls (user_id, title, category, description, expires_at, status)
+    polls (user_id, title, category, description, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, quorum, quorum_extension_days, cloned_from)
 VALUES
-    ($1, $2, $3, $4, $5, $6)
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 RETURNING
-    id, user_id, title, description, category, created_at, updated_at, expires_at, status
+    id, user_id, title, description, category, created_at, updated_at, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, tie_winner_option_id, quorum, quorum_extension_days, quorum_extended, outcome, deleted_at, cloned_from
 `
 
 type CreatePollParams struct {
-	UserID      uuid.UUID
-	Title       string
-	Category    string
-	Description string
-	ExpiresAt   time.Time
-	Status      PollStatus
+	UserID              uuid.UUID
+	Title               string
+	Category            string
+	Description         string
+	ExpiresAt           time.Time
+	Status              PollStatus
+	MaxChoices          int32
+	PollType            PollType
+	RatingMax           int32
+	VotesLocked         bool
+	AllowGuestVotes     bool
+	StartsAt            time.Time
+	Visibility          PollVisibility
+	ResultsVisibility   ResultsVisibility
+	TieBreak            TieBreak
+	Quorum              int32
+	QuorumExtensionDays int32
+	ClonedFrom          uuid.NullUUID
 }
 
 // used by transactions createPollWithOptions
@@ -41,6 +89,18 @@ func (q *Queries) CreatePoll(ctx context.Context, arg CreatePollParams) (Poll, e
 		arg.Description,
 		arg.ExpiresAt,
 		arg.Status,
+		arg.MaxChoices,
+		arg.PollType,
+		arg.RatingMax,
+		arg.VotesLocked,
+		arg.AllowGuestVotes,
+		arg.StartsAt,
+		arg.Visibility,
+		arg.ResultsVisibility,
+		arg.TieBreak,
+		arg.Quorum,
+		arg.QuorumExtensionDays,
+		arg.ClonedFrom,
 	)
 	var i Poll
 	err := row.Scan(
@@ -53,27 +113,103 @@ func (q *Queries) CreatePoll(ctx context.Context, arg CreatePollParams) (Poll, e
 		&i.UpdatedAt,
 		&i.ExpiresAt,
 		&i.Status,
+		&i.MaxChoices,
+		&i.PollType,
+		&i.RatingMax,
+		&i.VotesLocked,
+		&i.AllowGuestVotes,
+		&i.StartsAt,
+		&i.Visibility,
+		&i.ResultsVisibility,
+		&i.TieBreak,
+		&i.TieWinnerOptionID,
+		&i.Quorum,
+		&i.QuorumExtensionDays,
+		&i.QuorumExtended,
+		&i.Outcome,
+		&i.DeletedAt,
+		&i.ClonedFrom,
 	)
 	return i, err
 }
 
-const deletePoll = `-- name: DeletePoll :exec
-DELETE FROM
+const deletePoll = `-- name: DeletePoll :execrows
+UPDATE
     polls
+SET
+    deleted_at = now(),
+    updated_at = now()
 WHERE
-    id = $1 RETURNING id, user_id, title, description, category, created_at, updated_at, expires_at, status
+    id = $1 AND deleted_at IS NULL
 `
 
-func (q *Queries) DeletePoll(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deletePoll, id)
-	return err
+// used by pollhandler.DeletePoll, the poll stays in the trash until
+// PurgeDeletedPolls removes it with its options, votes and comments
+func (q *Queries) DeletePoll(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePoll, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const extendPollForQuorum = `-- name: ExtendPollForQuorum :one
+UPDATE
+    polls
+SET
+    expires_at = $2,
+    quorum_extended = true,
+    updated_at = now()
+WHERE
+    id = $1 RETURNING id, user_id, title, description, category, created_at, updated_at, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, tie_winner_option_id, quorum, quorum_extension_days, quorum_extended, outcome, deleted_at, cloned_from
+`
+
+type ExtendPollForQuorumParams struct {
+	ID        uuid.UUID
+	ExpiresAt time.Time
+}
+
+// used by cron, a poll short of its quorum is extended at most once
+func (q *Queries) ExtendPollForQuorum(ctx context.Context, arg ExtendPollForQuorumParams) (Poll, error) {
+	row := q.db.QueryRowContext(ctx, extendPollForQuorum, arg.ID, arg.ExpiresAt)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Description,
+		&i.Category,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.Status,
+		&i.MaxChoices,
+		&i.PollType,
+		&i.RatingMax,
+		&i.VotesLocked,
+		&i.AllowGuestVotes,
+		&i.StartsAt,
+		&i.Visibility,
+		&i.ResultsVisibility,
+		&i.TieBreak,
+		&i.TieWinnerOptionID,
+		&i.Quorum,
+		&i.QuorumExtensionDays,
+		&i.QuorumExtended,
+		&i.Outcome,
+		&i.DeletedAt,
+		&i.ClonedFrom,
+	)
+	return i, err
 }
 
 const getAllPolls = `-- name: GetAllPolls :many
 SELECT
-    id, user_id, title, description, category, created_at, updated_at, expires_at, status
+    id, user_id, title, description, category, created_at, updated_at, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, tie_winner_option_id, quorum, quorum_extension_days, quorum_extended, outcome, deleted_at, cloned_from
 FROM
     polls
+WHERE
+    visibility = 'public' AND deleted_at IS NULL AND status <> 'Draft'
 `
 
 // not used yet
@@ -96,6 +232,22 @@ func (q *Queries) GetAllPolls(ctx context.Context) ([]Poll, error) {
 			&i.UpdatedAt,
 			&i.ExpiresAt,
 			&i.Status,
+			&i.MaxChoices,
+			&i.PollType,
+			&i.RatingMax,
+			&i.VotesLocked,
+			&i.AllowGuestVotes,
+			&i.StartsAt,
+			&i.Visibility,
+			&i.ResultsVisibility,
+			&i.TieBreak,
+			&i.TieWinnerOptionID,
+			&i.Quorum,
+			&i.QuorumExtensionDays,
+			&i.QuorumExtended,
+			&i.Outcome,
+			&i.DeletedAt,
+			&i.ClonedFrom,
 		); err != nil {
 			return nil, err
 		}
@@ -111,93 +263,277 @@ func (q *Queries) GetAllPolls(ctx context.Context) ([]Poll, error) {
 }
 
 const getAllPollsByStatusList = `-- name: GetAllPollsByStatusList :many
+WITH feed AS (
+    SELECT
+        polls.id,
+        (CASE $1::text
+            WHEN 'newest' THEN extract(epoch FROM polls.created_at)
+            WHEN 'ending_soon' THEN -extract(epoch FROM polls.expires_at)
+            WHEN 'most_voted' THEN (SELECT COUNT(*) FROM votes WHERE votes.poll_id = polls.id)
+            WHEN 'most_commented' THEN (SELECT COUNT(*) FROM comments WHERE comments.poll_id = polls.id)
+            WHEN 'trending' THEN (SELECT COUNT(*) FROM votes WHERE votes.poll_id = polls.id AND votes.created_at > $2::timestamp)
+            ELSE extract(epoch FROM polls.expires_at)
+        END)::float8 as sort_key
+    FROM
+        polls
+    WHERE
+        polls.status = $3 AND ($4::text IS NULL OR polls.category = $4)
+        AND polls.deleted_at IS NULL
+        AND (polls.visibility = 'public' OR polls.user_id = $5
+            OR (polls.visibility = 'private' AND poll_allows_viewer(polls.id, $5)))
+        AND (cardinality($6::text[]) = 0 OR (
+            SELECT COUNT(*) FROM poll_tags JOIN tags ON tags.id = poll_tags.tag_id
+            WHERE poll_tags.poll_id = polls.id AND tags.name = ANY($6::text[])
+        ) >= CASE WHEN $7::boolean THEN cardinality($6::text[]) ELSE 1 END)
+)
+SELECT
+    poll_details.id, poll_details.title, poll_details.category, poll_details.description, poll_details.expires_at, poll_details.status, poll_details.max_choices, poll_details.poll_type, poll_details.rating_max, poll_details.votes_locked, poll_details.allow_guest_votes, poll_details.starts_at, poll_details.visibility, poll_details.results_visibility, poll_details.creator_id, poll_details.tie_break, poll_details.tie_winner, poll_details.quorum, poll_details.outcome, poll_details.cloned_from, poll_details.created_at, poll_details.updated_at, poll_details.creator_first_name, poll_details.creator_last_name, poll_details.votes, poll_details.comments, poll_details.voters, poll_details.options, poll_details.rating_stats, poll_details.final_winner, poll_details.reached_at, poll_details.tags,
+    COALESCE((SELECT array_agg(votes.option_id ORDER BY votes.rank) FROM votes WHERE votes.poll_id = poll_details.id AND votes.user_id = $5), '{}')::uuid[] as UserVote,
+    COALESCE((SELECT json_object_agg(ratings.option_id, ratings.score) FROM ratings WHERE ratings.poll_id = poll_details.id AND ratings.user_id = $5), '{}')::json as UserRatings,
+    feed.sort_key as SortKey
+FROM
+    poll_details
+JOIN feed ON feed.id = poll_details.id
+WHERE
+    $8::float8 IS NULL OR (feed.sort_key, poll_details.id) < ($8::float8, $9::uuid)
+ORDER BY feed.sort_key DESC, poll_details.id DESC
+LIMIT $10
+`
+
+type GetAllPollsByStatusListParams struct {
+	Sort          string
+	TrendingSince time.Time
+	Status        PollStatus
+	Category      sql.NullString
+	UserID        uuid.NullUUID
+	Tags          []string
+	MatchAllTags  bool
+	CursorKey     sql.NullFloat64
+	CursorID      uuid.NullUUID
+	PageSize      int32
+}
+
+type GetAllPollsByStatusListRow struct {
+	PollDetail  PollDetail
+	Uservote    []uuid.UUID
+	Userratings json.RawMessage
+	Sortkey     float64
+}
+
+// used by pollhandler.GetAllfinishedpolls and pollhandler.GetAllActivePolls, ordered by the
+// requested sort mode and paged by (sort key, id) starting after the cursor. Unlisted
+// polls are left out unless the viewer created them.
+func (q *Queries) GetAllPollsByStatusList(ctx context.Context, arg GetAllPollsByStatusListParams) ([]GetAllPollsByStatusListRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAllPollsByStatusList,
+		arg.Sort,
+		arg.TrendingSince,
+		arg.Status,
+		arg.Category,
+		arg.UserID,
+		pq.Array(arg.Tags),
+		arg.MatchAllTags,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAllPollsByStatusListRow
+	for rows.Next() {
+		var i GetAllPollsByStatusListRow
+		if err := rows.Scan(
+			&i.PollDetail.ID,
+			&i.PollDetail.Title,
+			&i.PollDetail.Category,
+			&i.PollDetail.Description,
+			&i.PollDetail.ExpiresAt,
+			&i.PollDetail.Status,
+			&i.PollDetail.MaxChoices,
+			&i.PollDetail.PollType,
+			&i.PollDetail.RatingMax,
+			&i.PollDetail.VotesLocked,
+			&i.PollDetail.AllowGuestVotes,
+			&i.PollDetail.StartsAt,
+			&i.PollDetail.Visibility,
+			&i.PollDetail.ResultsVisibility,
+			&i.PollDetail.CreatorID,
+			&i.PollDetail.TieBreak,
+			&i.PollDetail.TieWinner,
+			&i.PollDetail.Quorum,
+			&i.PollDetail.Outcome,
+			&i.PollDetail.ClonedFrom,
+			&i.PollDetail.CreatedAt,
+			&i.PollDetail.UpdatedAt,
+			&i.PollDetail.CreatorFirstName,
+			&i.PollDetail.CreatorLastName,
+			&i.PollDetail.Votes,
+			&i.PollDetail.Comments,
+			&i.PollDetail.Voters,
+			&i.PollDetail.Options,
+			&i.PollDetail.RatingStats,
+			&i.PollDetail.FinalWinner,
+			&i.PollDetail.ReachedAt,
+			pq.Array(&i.PollDetail.Tags),
+			pq.Array(&i.Uservote),
+			&i.Userratings,
+			&i.Sortkey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedPolls = `-- name: GetDeletedPolls :many
 SELECT
     polls.id as PollId,
     polls.title as Title,
     polls.category as Category,
-    polls.description as Description,
-    polls.expires_at as ExpiresAt,
     polls.status as Status,
-    polls.created_at as CreatedAt,
-    polls.updated_at as UpdatedAt,
+    polls.user_id as CreatorId,
     users.first_name as CreatorFirstName,
     users.last_name as CreatorLastName,
-    COUNT(DISTINCT votes.id) as votes,
-    COUNT(DISTINCT comments.id) as comments,
-    (SELECT json_agg(options.*) FROM options WHERE options.poll_id = polls.id) as Options,
-    (SELECT votes.option_id FROM votes WHERE votes.poll_id = polls.id AND votes.user_id = $5 LIMIT 1) as UserVote
+    polls.created_at as CreatedAt,
+    polls.deleted_at::timestamp as DeletedAt
 FROM
     polls
 JOIN users ON polls.user_id = users.id
-LEFT JOIN votes ON polls.id = votes.poll_id
-LEFT JOIN comments ON polls.id = comments.poll_id
 WHERE
-    polls.status = $1 AND polls.category LIKE($2)
-GROUP BY
-    polls.id,
-    users.id,
-    users.first_name,
-    users.last_name
-ORDER BY polls.expires_at DESC
-LIMIT $3 OFFSET $4
+    polls.deleted_at IS NOT NULL
+    AND ($1::timestamp IS NULL
+        OR (polls.deleted_at, polls.id) < ($1::timestamp, $2::uuid))
+ORDER BY polls.deleted_at DESC, polls.id DESC
+LIMIT $3
 `
 
-type GetAllPollsByStatusListParams struct {
-	Status   PollStatus
-	Category string
-	Limit    int32
-	Offset   int32
-	UserID   uuid.UUID
+type GetDeletedPollsParams struct {
+	CursorDeletedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageSize        int32
 }
 
-type GetAllPollsByStatusListRow struct {
+type GetDeletedPollsRow struct {
 	Pollid           uuid.UUID
 	Title            string
 	Category         string
-	Description      string
-	Expiresat        time.Time
 	Status           PollStatus
-	Createdat        time.Time
-	Updatedat        time.Time
+	Creatorid        uuid.UUID
 	Creatorfirstname string
 	Creatorlastname  sql.NullString
-	Votes            int64
-	Comments         int64
-	Options          json.RawMessage
-	Uservote         uuid.NullUUID
+	Createdat        time.Time
+	Deletedat        time.Time
 }
 
-// used by pollhandler.GetAllfinishedpolls and pollhandler.GetAllActivePolls
-func (q *Queries) GetAllPollsByStatusList(ctx context.Context, arg GetAllPollsByStatusListParams) ([]GetAllPollsByStatusListRow, error) {
-	rows, err := q.db.QueryContext(ctx, getAllPollsByStatusList,
-		arg.Status,
-		arg.Category,
-		arg.Limit,
-		arg.Offset,
+// used by pollhandler.ListDeletedPolls, most recently deleted first and paged
+// by (deleted_at, id) starting after the cursor
+func (q *Queries) GetDeletedPolls(ctx context.Context, arg GetDeletedPollsParams) ([]GetDeletedPollsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDeletedPolls, arg.CursorDeletedAt, arg.CursorID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDeletedPollsRow
+	for rows.Next() {
+		var i GetDeletedPollsRow
+		if err := rows.Scan(
+			&i.Pollid,
+			&i.Title,
+			&i.Category,
+			&i.Status,
+			&i.Creatorid,
+			&i.Creatorfirstname,
+			&i.Creatorlastname,
+			&i.Createdat,
+			&i.Deletedat,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDraftsByUser = `-- name: GetDraftsByUser :many
+SELECT
+    polls.id as PollId,
+    polls.title as Title,
+    polls.description as Description,
+    polls.category as Category,
+    polls.poll_type as PollType,
+    COALESCE((SELECT array_agg(options.name ORDER BY options.position) FROM options WHERE options.poll_id = polls.id), '{}')::text[] as Options,
+    COALESCE((SELECT array_agg(tags.name ORDER BY tags.name) FROM poll_tags JOIN tags ON tags.id = poll_tags.tag_id WHERE poll_tags.poll_id = polls.id), '{}')::text[] as Tags,
+    polls.created_at as CreatedAt,
+    polls.updated_at as UpdatedAt
+FROM
+    polls
+WHERE
+    polls.user_id = $1 AND polls.status = 'Draft' AND polls.deleted_at IS NULL
+    AND ($2::timestamp IS NULL
+        OR (polls.updated_at, polls.id) < ($2::timestamp, $3::uuid))
+ORDER BY polls.updated_at DESC, polls.id DESC
+LIMIT $4
+`
+
+type GetDraftsByUserParams struct {
+	UserID          uuid.UUID
+	CursorUpdatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageSize        int32
+}
+
+type GetDraftsByUserRow struct {
+	Pollid      uuid.UUID
+	Title       string
+	Description string
+	Category    string
+	Polltype    PollType
+	Options     []string
+	Tags        []string
+	Createdat   time.Time
+	Updatedat   time.Time
+}
+
+// used by pollhandler.GetMyDrafts, most recently edited first and paged by
+// (updated_at, id) starting after the cursor
+func (q *Queries) GetDraftsByUser(ctx context.Context, arg GetDraftsByUserParams) ([]GetDraftsByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDraftsByUser,
 		arg.UserID,
+		arg.CursorUpdatedAt,
+		arg.CursorID,
+		arg.PageSize,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetAllPollsByStatusListRow
+	var items []GetDraftsByUserRow
 	for rows.Next() {
-		var i GetAllPollsByStatusListRow
+		var i GetDraftsByUserRow
 		if err := rows.Scan(
 			&i.Pollid,
 			&i.Title,
-			&i.Category,
 			&i.Description,
-			&i.Expiresat,
-			&i.Status,
+			&i.Category,
+			&i.Polltype,
+			pq.Array(&i.Options),
+			pq.Array(&i.Tags),
 			&i.Createdat,
 			&i.Updatedat,
-			&i.Creatorfirstname,
-			&i.Creatorlastname,
-			&i.Votes,
-			&i.Comments,
-			&i.Options,
-			&i.Uservote,
 		); err != nil {
 			return nil, err
 		}
@@ -213,7 +549,7 @@ func (q *Queries) GetAllPollsByStatusList(ctx context.Context, arg GetAllPollsBy
 }
 
 const getExpiredPollsToUpdate = `-- name: GetExpiredPollsToUpdate :many
-Select id, user_id, title, description, category, created_at, updated_at, expires_at, status from polls where expires_at < now() and status = 'Active'
+Select id, user_id, title, description, category, created_at, updated_at, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, tie_winner_option_id, quorum, quorum_extension_days, quorum_extended, outcome, deleted_at, cloned_from from polls where expires_at < now() and status = 'Active' and deleted_at IS NULL
 `
 
 // used by cron
@@ -236,6 +572,22 @@ func (q *Queries) GetExpiredPollsToUpdate(ctx context.Context) ([]Poll, error) {
 			&i.UpdatedAt,
 			&i.ExpiresAt,
 			&i.Status,
+			&i.MaxChoices,
+			&i.PollType,
+			&i.RatingMax,
+			&i.VotesLocked,
+			&i.AllowGuestVotes,
+			&i.StartsAt,
+			&i.Visibility,
+			&i.ResultsVisibility,
+			&i.TieBreak,
+			&i.TieWinnerOptionID,
+			&i.Quorum,
+			&i.QuorumExtensionDays,
+			&i.QuorumExtended,
+			&i.Outcome,
+			&i.DeletedAt,
+			&i.ClonedFrom,
 		); err != nil {
 			return nil, err
 		}
@@ -250,141 +602,233 @@ func (q *Queries) GetExpiredPollsToUpdate(ctx context.Context) ([]Poll, error) {
 	return items, nil
 }
 
+const getPoll = `-- name: GetPoll :one
+SELECT
+    id, user_id, title, description, category, created_at, updated_at, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, tie_winner_option_id, quorum, quorum_extension_days, quorum_extended, outcome, deleted_at, cloned_from
+FROM
+    polls
+WHERE
+    id = $1 AND deleted_at IS NULL
+`
+
+// used by pollhandler.ClonePoll and votehandler.resolveVoter, visibility is checked
+// by the caller with GetPollAccess
+func (q *Queries) GetPoll(ctx context.Context, id uuid.UUID) (Poll, error) {
+	row := q.db.QueryRowContext(ctx, getPoll, id)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Description,
+		&i.Category,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.Status,
+		&i.MaxChoices,
+		&i.PollType,
+		&i.RatingMax,
+		&i.VotesLocked,
+		&i.AllowGuestVotes,
+		&i.StartsAt,
+		&i.Visibility,
+		&i.ResultsVisibility,
+		&i.TieBreak,
+		&i.TieWinnerOptionID,
+		&i.Quorum,
+		&i.QuorumExtensionDays,
+		&i.QuorumExtended,
+		&i.Outcome,
+		&i.DeletedAt,
+		&i.ClonedFrom,
+	)
+	return i, err
+}
+
 const getPollByID = `-- name: GetPollByID :one
 SELECT
-  polls.id as PollId,
-  polls.title as Title,
-  polls.category as Category,
-  polls.description as Description,
-  polls.expires_at as ExpiresAt,
-  polls.status as Status,
-  polls.created_at as CreatedAt,
-  polls.updated_at as UpdatedAt,
-  users.first_name as CreatorFirstName,
-  users.last_name as CreatorLastName,
-  COUNT(DISTINCT votes.id) as votes,
-  COUNT(DISTINCT comments.id) as comments,
-  (SELECT json_agg(options.*) FROM options WHERE options.poll_id = polls.id) as Options,
-  (SELECT votes.option_id FROM votes WHERE votes.poll_id = polls.id AND votes.user_id = $2 LIMIT 1) as UserVote
+  poll_details.id, poll_details.title, poll_details.category, poll_details.description, poll_details.expires_at, poll_details.status, poll_details.max_choices, poll_details.poll_type, poll_details.rating_max, poll_details.votes_locked, poll_details.allow_guest_votes, poll_details.starts_at, poll_details.visibility, poll_details.results_visibility, poll_details.creator_id, poll_details.tie_break, poll_details.tie_winner, poll_details.quorum, poll_details.outcome, poll_details.cloned_from, poll_details.created_at, poll_details.updated_at, poll_details.creator_first_name, poll_details.creator_last_name, poll_details.votes, poll_details.comments, poll_details.voters, poll_details.options, poll_details.rating_stats, poll_details.final_winner, poll_details.reached_at, poll_details.tags,
+  COALESCE((SELECT array_agg(votes.option_id ORDER BY votes.rank) FROM votes WHERE votes.poll_id = poll_details.id AND votes.user_id = $2), '{}')::uuid[] as UserVote,
+  COALESCE((SELECT json_object_agg(ratings.option_id, ratings.score) FROM ratings WHERE ratings.poll_id = poll_details.id AND ratings.user_id = $2), '{}')::json as UserRatings
 FROM
-  polls
-  LEFT JOIN users ON polls.user_id = users.id
-  LEFT JOIN votes ON polls.id = votes.poll_id
-  LEFT JOIN comments ON polls.id = comments.poll_id
+  poll_details
 WHERE
-  polls.id = $1
-GROUP BY
-  polls.id,
-  users.id,
-  users.first_name,
-  users.last_name
+  poll_details.id = $1
 `
 
 type GetPollByIDParams struct {
 	ID     uuid.UUID
-	UserID uuid.UUID
+	UserID uuid.NullUUID
 }
 
 type GetPollByIDRow struct {
-	Pollid           uuid.UUID
-	Title            string
-	Category         string
-	Description      string
-	Expiresat        time.Time
-	Status           PollStatus
-	Createdat        time.Time
-	Updatedat        time.Time
-	Creatorfirstname sql.NullString
-	Creatorlastname  sql.NullString
-	Votes            int64
-	Comments         int64
-	Options          json.RawMessage
-	Uservote         uuid.NullUUID
+	PollDetail  PollDetail
+	Uservote    []uuid.UUID
+	Userratings json.RawMessage
 }
 
+// visibility is checked by the caller with GetPollAccess
 func (q *Queries) GetPollByID(ctx context.Context, arg GetPollByIDParams) (GetPollByIDRow, error) {
 	row := q.db.QueryRowContext(ctx, getPollByID, arg.ID, arg.UserID)
 	var i GetPollByIDRow
 	err := row.Scan(
-		&i.Pollid,
+		&i.PollDetail.ID,
+		&i.PollDetail.Title,
+		&i.PollDetail.Category,
+		&i.PollDetail.Description,
+		&i.PollDetail.ExpiresAt,
+		&i.PollDetail.Status,
+		&i.PollDetail.MaxChoices,
+		&i.PollDetail.PollType,
+		&i.PollDetail.RatingMax,
+		&i.PollDetail.VotesLocked,
+		&i.PollDetail.AllowGuestVotes,
+		&i.PollDetail.StartsAt,
+		&i.PollDetail.Visibility,
+		&i.PollDetail.ResultsVisibility,
+		&i.PollDetail.CreatorID,
+		&i.PollDetail.TieBreak,
+		&i.PollDetail.TieWinner,
+		&i.PollDetail.Quorum,
+		&i.PollDetail.Outcome,
+		&i.PollDetail.ClonedFrom,
+		&i.PollDetail.CreatedAt,
+		&i.PollDetail.UpdatedAt,
+		&i.PollDetail.CreatorFirstName,
+		&i.PollDetail.CreatorLastName,
+		&i.PollDetail.Votes,
+		&i.PollDetail.Comments,
+		&i.PollDetail.Voters,
+		&i.PollDetail.Options,
+		&i.PollDetail.RatingStats,
+		&i.PollDetail.FinalWinner,
+		&i.PollDetail.ReachedAt,
+		pq.Array(&i.PollDetail.Tags),
+		pq.Array(&i.Uservote),
+		&i.Userratings,
+	)
+	return i, err
+}
+
+const getPollForVote = `-- name: GetPollForVote :one
+SELECT
+    id, user_id, title, description, category, created_at, updated_at, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, tie_winner_option_id, quorum, quorum_extension_days, quorum_extended, outcome, deleted_at, cloned_from
+FROM
+    polls
+WHERE
+    id = $1 AND deleted_at IS NULL
+FOR UPDATE
+`
+
+// used by the vote and poll lifecycle transactions and the cron close, locks the poll row so
+// concurrent ballots and status changes are validated one at a time
+func (q *Queries) GetPollForVote(ctx context.Context, id uuid.UUID) (Poll, error) {
+	row := q.db.QueryRowContext(ctx, getPollForVote, id)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
 		&i.Title,
-		&i.Category,
 		&i.Description,
-		&i.Expiresat,
+		&i.Category,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
 		&i.Status,
-		&i.Createdat,
-		&i.Updatedat,
-		&i.Creatorfirstname,
-		&i.Creatorlastname,
-		&i.Votes,
-		&i.Comments,
-		&i.Options,
-		&i.Uservote,
+		&i.MaxChoices,
+		&i.PollType,
+		&i.RatingMax,
+		&i.VotesLocked,
+		&i.AllowGuestVotes,
+		&i.StartsAt,
+		&i.Visibility,
+		&i.ResultsVisibility,
+		&i.TieBreak,
+		&i.TieWinnerOptionID,
+		&i.Quorum,
+		&i.QuorumExtensionDays,
+		&i.QuorumExtended,
+		&i.Outcome,
+		&i.DeletedAt,
+		&i.ClonedFrom,
 	)
 	return i, err
 }
 
 const getPollsByUser = `-- name: GetPollsByUser :many
+WITH feed AS (
+    SELECT
+        polls.id,
+        (CASE $1::text
+            WHEN 'newest' THEN extract(epoch FROM polls.created_at)
+            WHEN 'ending_soon' THEN -extract(epoch FROM polls.expires_at)
+            WHEN 'most_voted' THEN (SELECT COUNT(*) FROM votes WHERE votes.poll_id = polls.id)
+            WHEN 'most_commented' THEN (SELECT COUNT(*) FROM comments WHERE comments.poll_id = polls.id)
+            WHEN 'trending' THEN (SELECT COUNT(*) FROM votes WHERE votes.poll_id = polls.id AND votes.created_at > $2::timestamp)
+            ELSE extract(epoch FROM polls.expires_at)
+        END)::float8 as sort_key
+    FROM
+        polls
+    WHERE
+        polls.user_id = $3 AND ($4::text IS NULL OR polls.category = $4)
+        AND polls.deleted_at IS NULL AND polls.status <> 'Draft'
+        AND (polls.visibility = 'public' OR polls.user_id = $5::uuid
+            OR (polls.visibility = 'private' AND poll_allows_viewer(polls.id, $5::uuid)))
+        AND (cardinality($6::text[]) = 0 OR (
+            SELECT COUNT(*) FROM poll_tags JOIN tags ON tags.id = poll_tags.tag_id
+            WHERE poll_tags.poll_id = polls.id AND tags.name = ANY($6::text[])
+        ) >= CASE WHEN $7::boolean THEN cardinality($6::text[]) ELSE 1 END)
+)
 SELECT
-    polls.id as PollId,
-    polls.title as Title,
-    polls.category as Category,
-    polls.description as Description,
-    polls.expires_at as ExpiresAt,
-    polls.status as Status,
-    polls.created_at as CreatedAt,
-    polls.updated_at as UpdatedAt,
-    users.first_name as CreatorFirstName,
-    users.last_name as CreatorLastName,
-    COUNT(DISTINCT votes.id) as votes,
-    COUNT(DISTINCT comments.id) as comments,
-    (SELECT json_agg(options.*) FROM options WHERE options.poll_id = polls.id) as Options,
-    (SELECT option_id FROM votes WHERE votes.poll_id = polls.id AND votes.user_id = $1 LIMIT 1) as UserVote
+    poll_details.id, poll_details.title, poll_details.category, poll_details.description, poll_details.expires_at, poll_details.status, poll_details.max_choices, poll_details.poll_type, poll_details.rating_max, poll_details.votes_locked, poll_details.allow_guest_votes, poll_details.starts_at, poll_details.visibility, poll_details.results_visibility, poll_details.creator_id, poll_details.tie_break, poll_details.tie_winner, poll_details.quorum, poll_details.outcome, poll_details.cloned_from, poll_details.created_at, poll_details.updated_at, poll_details.creator_first_name, poll_details.creator_last_name, poll_details.votes, poll_details.comments, poll_details.voters, poll_details.options, poll_details.rating_stats, poll_details.final_winner, poll_details.reached_at, poll_details.tags,
+    COALESCE((SELECT array_agg(votes.option_id ORDER BY votes.rank) FROM votes WHERE votes.poll_id = poll_details.id AND votes.user_id = $3), '{}')::uuid[] as UserVote,
+    COALESCE((SELECT json_object_agg(ratings.option_id, ratings.score) FROM ratings WHERE ratings.poll_id = poll_details.id AND ratings.user_id = $3), '{}')::json as UserRatings,
+    feed.sort_key as SortKey
 FROM
-    polls
-JOIN users ON polls.user_id = users.id
-LEFT JOIN votes ON polls.id = votes.poll_id
-LEFT JOIN comments ON polls.id = comments.poll_id
+    poll_details
+JOIN feed ON feed.id = poll_details.id
 WHERE
-    polls.user_id = $1 AND polls.category LIKE $2
-GROUP BY
-    polls.id,
-    users.first_name,
-    users.last_name
-LIMIT $3 OFFSET $4
+    $8::float8 IS NULL OR (feed.sort_key, poll_details.id) < ($8::float8, $9::uuid)
+ORDER BY feed.sort_key DESC, poll_details.id DESC
+LIMIT $10
 `
 
 type GetPollsByUserParams struct {
-	UserID   uuid.UUID
-	Category string
-	Limit    int32
-	Offset   int32
+	Sort          string
+	TrendingSince time.Time
+	UserID        uuid.UUID
+	Category      sql.NullString
+	ViewerID      uuid.NullUUID
+	Tags          []string
+	MatchAllTags  bool
+	CursorKey     sql.NullFloat64
+	CursorID      uuid.NullUUID
+	PageSize      int32
 }
 
 type GetPollsByUserRow struct {
-	Pollid           uuid.UUID
-	Title            string
-	Category         string
-	Description      string
-	Expiresat        time.Time
-	Status           PollStatus
-	Createdat        time.Time
-	Updatedat        time.Time
-	Creatorfirstname string
-	Creatorlastname  sql.NullString
-	Votes            int64
-	Comments         int64
-	Options          json.RawMessage
-	Uservote         uuid.NullUUID
+	PollDetail  PollDetail
+	Uservote    []uuid.UUID
+	Userratings json.RawMessage
+	Sortkey     float64
 }
 
-// used by pollhandler.GetPollsByUser
+// used by pollhandler.GetUsersPolls, ordered by the requested sort mode and paged by
+// (sort key, id) starting after the cursor. Other viewers only see the creator's
+// public polls and the private polls they are allowed on.
 func (q *Queries) GetPollsByUser(ctx context.Context, arg GetPollsByUserParams) ([]GetPollsByUserRow, error) {
 	rows, err := q.db.QueryContext(ctx, getPollsByUser,
+		arg.Sort,
+		arg.TrendingSince,
 		arg.UserID,
 		arg.Category,
-		arg.Limit,
-		arg.Offset,
+		arg.ViewerID,
+		pq.Array(arg.Tags),
+		arg.MatchAllTags,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.PageSize,
 	)
 	if err != nil {
 		return nil, err
@@ -394,20 +838,41 @@ func (q *Queries) GetPollsByUser(ctx context.Context, arg GetPollsByUserParams)
 	for rows.Next() {
 		var i GetPollsByUserRow
 		if err := rows.Scan(
-			&i.Pollid,
-			&i.Title,
-			&i.Category,
-			&i.Description,
-			&i.Expiresat,
-			&i.Status,
-			&i.Createdat,
-			&i.Updatedat,
-			&i.Creatorfirstname,
-			&i.Creatorlastname,
-			&i.Votes,
-			&i.Comments,
-			&i.Options,
-			&i.Uservote,
+			&i.PollDetail.ID,
+			&i.PollDetail.Title,
+			&i.PollDetail.Category,
+			&i.PollDetail.Description,
+			&i.PollDetail.ExpiresAt,
+			&i.PollDetail.Status,
+			&i.PollDetail.MaxChoices,
+			&i.PollDetail.PollType,
+			&i.PollDetail.RatingMax,
+			&i.PollDetail.VotesLocked,
+			&i.PollDetail.AllowGuestVotes,
+			&i.PollDetail.StartsAt,
+			&i.PollDetail.Visibility,
+			&i.PollDetail.ResultsVisibility,
+			&i.PollDetail.CreatorID,
+			&i.PollDetail.TieBreak,
+			&i.PollDetail.TieWinner,
+			&i.PollDetail.Quorum,
+			&i.PollDetail.Outcome,
+			&i.PollDetail.ClonedFrom,
+			&i.PollDetail.CreatedAt,
+			&i.PollDetail.UpdatedAt,
+			&i.PollDetail.CreatorFirstName,
+			&i.PollDetail.CreatorLastName,
+			&i.PollDetail.Votes,
+			&i.PollDetail.Comments,
+			&i.PollDetail.Voters,
+			&i.PollDetail.Options,
+			&i.PollDetail.RatingStats,
+			&i.PollDetail.FinalWinner,
+			&i.PollDetail.ReachedAt,
+			pq.Array(&i.PollDetail.Tags),
+			pq.Array(&i.Uservote),
+			&i.Userratings,
+			&i.Sortkey,
 		); err != nil {
 			return nil, err
 		}
@@ -423,49 +888,66 @@ func (q *Queries) GetPollsByUser(ctx context.Context, arg GetPollsByUserParams)
 }
 
 const getRecentPolls = `-- name: GetRecentPolls :many
+WITH feed AS (
+    SELECT
+        polls.id,
+        (CASE $1::text
+            WHEN 'newest' THEN extract(epoch FROM polls.created_at)
+            WHEN 'ending_soon' THEN -extract(epoch FROM polls.expires_at)
+            WHEN 'most_voted' THEN (SELECT COUNT(*) FROM votes WHERE votes.poll_id = polls.id)
+            WHEN 'most_commented' THEN (SELECT COUNT(*) FROM comments WHERE comments.poll_id = polls.id)
+            WHEN 'trending' THEN (SELECT COUNT(*) FROM votes WHERE votes.poll_id = polls.id AND votes.created_at > $2::timestamp)
+            ELSE extract(epoch FROM polls.expires_at)
+        END)::float8 as sort_key
+    FROM
+        polls
+    WHERE
+        polls.deleted_at IS NULL AND polls.status <> 'Draft'
+        AND (polls.visibility = 'public' OR polls.user_id = $3
+            OR (polls.visibility = 'private' AND poll_allows_viewer(polls.id, $3)))
+        AND (cardinality($4::text[]) = 0 OR (
+            SELECT COUNT(*) FROM poll_tags JOIN tags ON tags.id = poll_tags.tag_id
+            WHERE poll_tags.poll_id = polls.id AND tags.name = ANY($4::text[])
+        ) >= CASE WHEN $5::boolean THEN cardinality($4::text[]) ELSE 1 END)
+)
 SELECT
-    polls.id as PollId,
-    polls.title as Title,
-    polls.category as Category,
-    polls.description as Description,
-    polls.expires_at as ExpiresAt,
-    polls.status as Status,
-    polls.created_at as CreatedAt,
-    polls.updated_at as UpdatedAt,
-    users.first_name as CreatorFirstName,
-    users.last_name as CreatorLastName,
-    count(distinct votes.id) as votes,
-    count(distinct comments.id) as comments,
-    (SELECT json_agg(options.*) FROM options WHERE options.poll_id = polls.id) as Options,
-     (SELECT votes.option_id FROM votes WHERE votes.poll_id = polls.id AND votes.user_id = $1 LIMIT 1) as UserVote
-FROM polls
-JOIN users ON polls.user_id = users.id
-LEFT JOIN votes ON polls.id = votes.poll_id
-LEFT JOIN comments ON polls.id = comments.poll_id
-GROUP BY polls.id, users.first_name, users.last_name
-ORDER BY polls.expires_at DESC
-LIMIT 10
+    poll_details.id, poll_details.title, poll_details.category, poll_details.description, poll_details.expires_at, poll_details.status, poll_details.max_choices, poll_details.poll_type, poll_details.rating_max, poll_details.votes_locked, poll_details.allow_guest_votes, poll_details.starts_at, poll_details.visibility, poll_details.results_visibility, poll_details.creator_id, poll_details.tie_break, poll_details.tie_winner, poll_details.quorum, poll_details.outcome, poll_details.cloned_from, poll_details.created_at, poll_details.updated_at, poll_details.creator_first_name, poll_details.creator_last_name, poll_details.votes, poll_details.comments, poll_details.voters, poll_details.options, poll_details.rating_stats, poll_details.final_winner, poll_details.reached_at, poll_details.tags,
+    COALESCE((SELECT array_agg(votes.option_id ORDER BY votes.rank) FROM votes WHERE votes.poll_id = poll_details.id AND votes.user_id = $3), '{}')::uuid[] as UserVote,
+    COALESCE((SELECT json_object_agg(ratings.option_id, ratings.score) FROM ratings WHERE ratings.poll_id = poll_details.id AND ratings.user_id = $3), '{}')::json as UserRatings,
+    feed.sort_key as SortKey
+FROM
+    poll_details
+JOIN feed ON feed.id = poll_details.id
+ORDER BY feed.sort_key DESC, poll_details.id DESC
+LIMIT $6
 `
 
+type GetRecentPollsParams struct {
+	Sort          string
+	TrendingSince time.Time
+	UserID        uuid.NullUUID
+	Tags          []string
+	MatchAllTags  bool
+	PageSize      int32
+}
+
 type GetRecentPollsRow struct {
-	Pollid           uuid.UUID
-	Title            string
-	Category         string
-	Description      string
-	Expiresat        time.Time
-	Status           PollStatus
-	Createdat        time.Time
-	Updatedat        time.Time
-	Creatorfirstname string
-	Creatorlastname  sql.NullString
-	Votes            int64
-	Comments         int64
-	Options          json.RawMessage
-	Uservote         uuid.NullUUID
+	PollDetail  PollDetail
+	Uservote    []uuid.UUID
+	Userratings json.RawMessage
+	Sortkey     float64
 }
 
-func (q *Queries) GetRecentPolls(ctx context.Context, userID uuid.UUID) ([]GetRecentPollsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getRecentPolls, userID)
+// used by pollhandler.GetRecentPolls, ordered by the requested sort mode
+func (q *Queries) GetRecentPolls(ctx context.Context, arg GetRecentPollsParams) ([]GetRecentPollsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentPolls,
+		arg.Sort,
+		arg.TrendingSince,
+		arg.UserID,
+		pq.Array(arg.Tags),
+		arg.MatchAllTags,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -474,20 +956,98 @@ func (q *Queries) GetRecentPolls(ctx context.Context, userID uuid.UUID) ([]GetRe
 	for rows.Next() {
 		var i GetRecentPollsRow
 		if err := rows.Scan(
-			&i.Pollid,
+			&i.PollDetail.ID,
+			&i.PollDetail.Title,
+			&i.PollDetail.Category,
+			&i.PollDetail.Description,
+			&i.PollDetail.ExpiresAt,
+			&i.PollDetail.Status,
+			&i.PollDetail.MaxChoices,
+			&i.PollDetail.PollType,
+			&i.PollDetail.RatingMax,
+			&i.PollDetail.VotesLocked,
+			&i.PollDetail.AllowGuestVotes,
+			&i.PollDetail.StartsAt,
+			&i.PollDetail.Visibility,
+			&i.PollDetail.ResultsVisibility,
+			&i.PollDetail.CreatorID,
+			&i.PollDetail.TieBreak,
+			&i.PollDetail.TieWinner,
+			&i.PollDetail.Quorum,
+			&i.PollDetail.Outcome,
+			&i.PollDetail.ClonedFrom,
+			&i.PollDetail.CreatedAt,
+			&i.PollDetail.UpdatedAt,
+			&i.PollDetail.CreatorFirstName,
+			&i.PollDetail.CreatorLastName,
+			&i.PollDetail.Votes,
+			&i.PollDetail.Comments,
+			&i.PollDetail.Voters,
+			&i.PollDetail.Options,
+			&i.PollDetail.RatingStats,
+			&i.PollDetail.FinalWinner,
+			&i.PollDetail.ReachedAt,
+			pq.Array(&i.PollDetail.Tags),
+			pq.Array(&i.Uservote),
+			&i.Userratings,
+			&i.Sortkey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const openScheduledPolls = `-- name: OpenScheduledPolls :many
+UPDATE polls
+SET status = 'Active', updated_at = now()
+WHERE status = 'Inactive' AND starts_at <= now() AND deleted_at IS NULL
+RETURNING id, user_id, title, description, category, created_at, updated_at, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, tie_winner_option_id, quorum, quorum_extension_days, quorum_extended, outcome, deleted_at, cloned_from
+`
+
+// used by cron, opens Inactive polls whose start time has passed
+func (q *Queries) OpenScheduledPolls(ctx context.Context) ([]Poll, error) {
+	rows, err := q.db.QueryContext(ctx, openScheduledPolls)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Poll
+	for rows.Next() {
+		var i Poll
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
 			&i.Title,
-			&i.Category,
 			&i.Description,
-			&i.Expiresat,
+			&i.Category,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ExpiresAt,
 			&i.Status,
-			&i.Createdat,
-			&i.Updatedat,
-			&i.Creatorfirstname,
-			&i.Creatorlastname,
-			&i.Votes,
-			&i.Comments,
-			&i.Options,
-			&i.Uservote,
+			&i.MaxChoices,
+			&i.PollType,
+			&i.RatingMax,
+			&i.VotesLocked,
+			&i.AllowGuestVotes,
+			&i.StartsAt,
+			&i.Visibility,
+			&i.ResultsVisibility,
+			&i.TieBreak,
+			&i.TieWinnerOptionID,
+			&i.Quorum,
+			&i.QuorumExtensionDays,
+			&i.QuorumExtended,
+			&i.Outcome,
+			&i.DeletedAt,
+			&i.ClonedFrom,
 		); err != nil {
 			return nil, err
 		}
@@ -502,40 +1062,434 @@ func (q *Queries) GetRecentPolls(ctx context.Context, userID uuid.UUID) ([]GetRe
 	return items, nil
 }
 
+const purgeDeletedPolls = `-- name: PurgeDeletedPolls :execrows
+DELETE FROM
+    polls
+WHERE
+    deleted_at < $1
+`
+
+// used by cron, removes polls deleted before the retention cutoff for good
+func (q *Queries) PurgeDeletedPolls(ctx context.Context, deletedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedPolls, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const raiseRankedMaxChoices = `-- name: RaiseRankedMaxChoices :exec
+UPDATE
+    polls
+SET
+    max_choices = max_choices + 1,
+    updated_at = now()
+WHERE
+    id = $1 AND poll_type = 'Ranked'
+    AND max_choices = (SELECT COUNT(*) FROM options WHERE options.poll_id = $1) - 1
+`
+
+// used by transaction AddOptionToPoll after the option is added, ranked polls
+// that let voters rank every option keep doing so
+func (q *Queries) RaiseRankedMaxChoices(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, raiseRankedMaxChoices, id)
+	return err
+}
+
+const restorePoll = `-- name: RestorePoll :execrows
+UPDATE
+    polls
+SET
+    deleted_at = NULL,
+    updated_at = now()
+WHERE
+    id = $1 AND deleted_at IS NOT NULL
+`
+
+// used by pollhandler.RestorePoll
+func (q *Queries) RestorePoll(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restorePoll, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchPolls = `-- name: SearchPolls :many
+WITH matches AS (
+    SELECT
+        poll_search.poll_id,
+        ts_rank(poll_search.document, query) as rank,
+        ts_headline('english', poll_search.body, query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') as snippet
+    FROM
+        poll_search,
+        websearch_to_tsquery('english', $1) query
+    WHERE
+        poll_search.document @@ query
+)
+SELECT
+    poll_details.id, poll_details.title, poll_details.category, poll_details.description, poll_details.expires_at, poll_details.status, poll_details.max_choices, poll_details.poll_type, poll_details.rating_max, poll_details.votes_locked, poll_details.allow_guest_votes, poll_details.starts_at, poll_details.visibility, poll_details.results_visibility, poll_details.creator_id, poll_details.tie_break, poll_details.tie_winner, poll_details.quorum, poll_details.outcome, poll_details.cloned_from, poll_details.created_at, poll_details.updated_at, poll_details.creator_first_name, poll_details.creator_last_name, poll_details.votes, poll_details.comments, poll_details.voters, poll_details.options, poll_details.rating_stats, poll_details.final_winner, poll_details.reached_at, poll_details.tags,
+    COALESCE((SELECT array_agg(votes.option_id ORDER BY votes.rank) FROM votes WHERE votes.poll_id = poll_details.id AND votes.user_id = $2), '{}')::uuid[] as UserVote,
+    COALESCE((SELECT json_object_agg(ratings.option_id, ratings.score) FROM ratings WHERE ratings.poll_id = poll_details.id AND ratings.user_id = $2), '{}')::json as UserRatings,
+    matches.rank as Rank,
+    matches.snippet as Snippet
+FROM
+    poll_details
+JOIN matches ON matches.poll_id = poll_details.id
+WHERE
+    ($3::text IS NULL OR poll_details.category = $3)
+    AND poll_details.status <> 'Draft'
+    AND (poll_details.visibility = 'public' OR poll_details.creator_id = $2
+        OR (poll_details.visibility = 'private' AND poll_allows_viewer(poll_details.id, $2)))
+    AND ($4::poll_status IS NULL OR poll_details.status = $4)
+    AND (cardinality($5::text[]) = 0 OR (
+        SELECT COUNT(*) FROM poll_tags JOIN tags ON tags.id = poll_tags.tag_id
+        WHERE poll_tags.poll_id = poll_details.id AND tags.name = ANY($5::text[])
+    ) >= CASE WHEN $6::boolean THEN cardinality($5::text[]) ELSE 1 END)
+ORDER BY matches.rank DESC, poll_details.expires_at DESC
+LIMIT $7 OFFSET $8
+`
+
+type SearchPollsParams struct {
+	Query        string
+	UserID       uuid.NullUUID
+	Category     sql.NullString
+	Status       NullPollStatus
+	Tags         []string
+	MatchAllTags bool
+	PageSize     int32
+	PageOffset   int32
+}
+
+type SearchPollsRow struct {
+	PollDetail  PollDetail
+	Uservote    []uuid.UUID
+	Userratings json.RawMessage
+	Rank        float32
+	Snippet     string
+}
+
+// used by pollhandler.SearchPolls, best matches first with the matching text highlighted
+func (q *Queries) SearchPolls(ctx context.Context, arg SearchPollsParams) ([]SearchPollsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPolls,
+		arg.Query,
+		arg.UserID,
+		arg.Category,
+		arg.Status,
+		pq.Array(arg.Tags),
+		arg.MatchAllTags,
+		arg.PageSize,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPollsRow
+	for rows.Next() {
+		var i SearchPollsRow
+		if err := rows.Scan(
+			&i.PollDetail.ID,
+			&i.PollDetail.Title,
+			&i.PollDetail.Category,
+			&i.PollDetail.Description,
+			&i.PollDetail.ExpiresAt,
+			&i.PollDetail.Status,
+			&i.PollDetail.MaxChoices,
+			&i.PollDetail.PollType,
+			&i.PollDetail.RatingMax,
+			&i.PollDetail.VotesLocked,
+			&i.PollDetail.AllowGuestVotes,
+			&i.PollDetail.StartsAt,
+			&i.PollDetail.Visibility,
+			&i.PollDetail.ResultsVisibility,
+			&i.PollDetail.CreatorID,
+			&i.PollDetail.TieBreak,
+			&i.PollDetail.TieWinner,
+			&i.PollDetail.Quorum,
+			&i.PollDetail.Outcome,
+			&i.PollDetail.ClonedFrom,
+			&i.PollDetail.CreatedAt,
+			&i.PollDetail.UpdatedAt,
+			&i.PollDetail.CreatorFirstName,
+			&i.PollDetail.CreatorLastName,
+			&i.PollDetail.Votes,
+			&i.PollDetail.Comments,
+			&i.PollDetail.Voters,
+			&i.PollDetail.Options,
+			&i.PollDetail.RatingStats,
+			&i.PollDetail.FinalWinner,
+			&i.PollDetail.ReachedAt,
+			pq.Array(&i.PollDetail.Tags),
+			pq.Array(&i.Uservote),
+			&i.Userratings,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPollTieWinner = `-- name: SetPollTieWinner :one
+UPDATE
+    polls
+SET
+    tie_winner_option_id = $2,
+    updated_at = now()
+WHERE
+    id = $1 RETURNING id, user_id, title, description, category, created_at, updated_at, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, tie_winner_option_id, quorum, quorum_extension_days, quorum_extended, outcome, deleted_at, cloned_from
+`
+
+type SetPollTieWinnerParams struct {
+	ID                uuid.UUID
+	TieWinnerOptionID uuid.NullUUID
+}
+
+// records the creator's pick among tied leaders, the caller checks the option is tied
+func (q *Queries) SetPollTieWinner(ctx context.Context, arg SetPollTieWinnerParams) (Poll, error) {
+	row := q.db.QueryRowContext(ctx, setPollTieWinner, arg.ID, arg.TieWinnerOptionID)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Description,
+		&i.Category,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.Status,
+		&i.MaxChoices,
+		&i.PollType,
+		&i.RatingMax,
+		&i.VotesLocked,
+		&i.AllowGuestVotes,
+		&i.StartsAt,
+		&i.Visibility,
+		&i.ResultsVisibility,
+		&i.TieBreak,
+		&i.TieWinnerOptionID,
+		&i.Quorum,
+		&i.QuorumExtensionDays,
+		&i.QuorumExtended,
+		&i.Outcome,
+		&i.DeletedAt,
+		&i.ClonedFrom,
+	)
+	return i, err
+}
+
+const updateDraft = `-- name: UpdateDraft :one
+UPDATE
+    polls
+SET
+    title = $2,
+    description = $3,
+    category = $4,
+    status = $5,
+    starts_at = $6,
+    expires_at = $7,
+    max_choices = $8,
+    poll_type = $9,
+    rating_max = $10,
+    votes_locked = $11,
+    allow_guest_votes = $12,
+    visibility = $13,
+    results_visibility = $14,
+    tie_break = $15,
+    quorum = $16,
+    quorum_extension_days = $17,
+    updated_at = now()
+WHERE
+    id = $1 AND status = 'Draft' AND deleted_at IS NULL RETURNING id, user_id, title, description, category, created_at, updated_at, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, tie_winner_option_id, quorum, quorum_extension_days, quorum_extended, outcome, deleted_at, cloned_from
+`
+
+type UpdateDraftParams struct {
+	ID                  uuid.UUID
+	Title               string
+	Description         string
+	Category            string
+	Status              PollStatus
+	StartsAt            time.Time
+	ExpiresAt           time.Time
+	MaxChoices          int32
+	PollType            PollType
+	RatingMax           int32
+	VotesLocked         bool
+	AllowGuestVotes     bool
+	Visibility          PollVisibility
+	ResultsVisibility   ResultsVisibility
+	TieBreak            TieBreak
+	Quorum              int32
+	QuorumExtensionDays int32
+}
+
+// used by the SaveDraft and PublishDraft transactions, a draft is saved as a
+// whole and publishing sets its status
+func (q *Queries) UpdateDraft(ctx context.Context, arg UpdateDraftParams) (Poll, error) {
+	row := q.db.QueryRowContext(ctx, updateDraft,
+		arg.ID,
+		arg.Title,
+		arg.Description,
+		arg.Category,
+		arg.Status,
+		arg.StartsAt,
+		arg.ExpiresAt,
+		arg.MaxChoices,
+		arg.PollType,
+		arg.RatingMax,
+		arg.VotesLocked,
+		arg.AllowGuestVotes,
+		arg.Visibility,
+		arg.ResultsVisibility,
+		arg.TieBreak,
+		arg.Quorum,
+		arg.QuorumExtensionDays,
+	)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Description,
+		&i.Category,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.Status,
+		&i.MaxChoices,
+		&i.PollType,
+		&i.RatingMax,
+		&i.VotesLocked,
+		&i.AllowGuestVotes,
+		&i.StartsAt,
+		&i.Visibility,
+		&i.ResultsVisibility,
+		&i.TieBreak,
+		&i.TieWinnerOptionID,
+		&i.Quorum,
+		&i.QuorumExtensionDays,
+		&i.QuorumExtended,
+		&i.Outcome,
+		&i.DeletedAt,
+		&i.ClonedFrom,
+	)
+	return i, err
+}
+
 const updatePoll = `-- name: UpdatePoll :one
 UPDATE
     polls
 SET
-    user_id = coalesce($1, user_id),
     title = coalesce($2, title),
-    category = coalesce($3, category),
+    category = coalesce(NULLIF($3, ''), category),
     description = coalesce($4, description),
     expires_at = coalesce($5, expires_at),
-    status = coalesce($6, status),
+    visibility = coalesce(NULLIF($6::text, '')::poll_visibility, visibility),
+    results_visibility = coalesce(NULLIF($7::text, '')::results_visibility, results_visibility),
+    tie_break = coalesce(NULLIF($8::text, '')::tie_break, tie_break),
+    quorum = CASE WHEN $9::int < 0 THEN quorum ELSE $9::int END,
+    quorum_extension_days = CASE WHEN $10::int < 0 THEN quorum_extension_days ELSE $10::int END,
     updated_at = now()
 WHERE
-    id = $7 RETURNING id, user_id, title, description, category, created_at, updated_at, expires_at, status
+    id = $1 AND deleted_at IS NULL RETURNING id, user_id, title, description, category, created_at, updated_at, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, tie_winner_option_id, quorum, quorum_extension_days, quorum_extended, outcome, deleted_at, cloned_from
 `
 
 type UpdatePollParams struct {
-	UserID      uuid.UUID
+	ID          uuid.UUID
 	Title       string
 	Category    string
 	Description string
 	ExpiresAt   time.Time
-	Status      PollStatus
-	ID          uuid.UUID
+	Column6     string
+	Column7     string
+	Column8     string
+	Column9     int32
+	Column10    int32
 }
 
+// used by transaction UpdatePollDetails, status changes go through UpdatePollLifecycle
 func (q *Queries) UpdatePoll(ctx context.Context, arg UpdatePollParams) (Poll, error) {
 	row := q.db.QueryRowContext(ctx, updatePoll,
-		arg.UserID,
+		arg.ID,
 		arg.Title,
 		arg.Category,
 		arg.Description,
 		arg.ExpiresAt,
-		arg.Status,
+		arg.Column6,
+		arg.Column7,
+		arg.Column8,
+		arg.Column9,
+		arg.Column10,
+	)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Description,
+		&i.Category,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.Status,
+		&i.MaxChoices,
+		&i.PollType,
+		&i.RatingMax,
+		&i.VotesLocked,
+		&i.AllowGuestVotes,
+		&i.StartsAt,
+		&i.Visibility,
+		&i.ResultsVisibility,
+		&i.TieBreak,
+		&i.TieWinnerOptionID,
+		&i.Quorum,
+		&i.QuorumExtensionDays,
+		&i.QuorumExtended,
+		&i.Outcome,
+		&i.DeletedAt,
+		&i.ClonedFrom,
+	)
+	return i, err
+}
+
+const updatePollLifecycle = `-- name: UpdatePollLifecycle :one
+UPDATE
+    polls
+SET
+    status = $2,
+    expires_at = $3,
+    outcome = $4,
+    tie_winner_option_id = CASE WHEN $2 = 'Archived' THEN tie_winner_option_id END,
+    updated_at = now()
+WHERE
+    id = $1 RETURNING id, user_id, title, description, category, created_at, updated_at, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, tie_winner_option_id, quorum, quorum_extension_days, quorum_extended, outcome, deleted_at, cloned_from
+`
+
+type UpdatePollLifecycleParams struct {
+	ID        uuid.UUID
+	Status    PollStatus
+	ExpiresAt time.Time
+	Outcome   NullPollOutcome
+}
+
+// used by the close, reopen and extend transactions, a reopened poll drops
+// the creator's tie-break pick and its outcome since the leaders may change
+func (q *Queries) UpdatePollLifecycle(ctx context.Context, arg UpdatePollLifecycleParams) (Poll, error) {
+	row := q.db.QueryRowContext(ctx, updatePollLifecycle,
 		arg.ID,
+		arg.Status,
+		arg.ExpiresAt,
+		arg.Outcome,
 	)
 	var i Poll
 	err := row.Scan(
@@ -548,6 +1502,22 @@ func (q *Queries) UpdatePoll(ctx context.Context, arg UpdatePollParams) (Poll, e
 		&i.UpdatedAt,
 		&i.ExpiresAt,
 		&i.Status,
+		&i.MaxChoices,
+		&i.PollType,
+		&i.RatingMax,
+		&i.VotesLocked,
+		&i.AllowGuestVotes,
+		&i.StartsAt,
+		&i.Visibility,
+		&i.ResultsVisibility,
+		&i.TieBreak,
+		&i.TieWinnerOptionID,
+		&i.Quorum,
+		&i.QuorumExtensionDays,
+		&i.QuorumExtended,
+		&i.Outcome,
+		&i.DeletedAt,
+		&i.ClonedFrom,
 	)
 	return i, err
 }
@@ -557,19 +1527,21 @@ UPDATE
     polls
 SET
     status = $2,
+    outcome = $3,
     updated_at = now()
 WHERE
-    id = $1 RETURNING id, user_id, title, description, category, created_at, updated_at, expires_at, status
+    id = $1 RETURNING id, user_id, title, description, category, created_at, updated_at, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, tie_winner_option_id, quorum, quorum_extension_days, quorum_extended, outcome, deleted_at, cloned_from
 `
 
 type UpdatePollStatusParams struct {
-	ID     uuid.UUID
-	Status PollStatus
+	ID      uuid.UUID
+	Status  PollStatus
+	Outcome NullPollOutcome
 }
 
-// used by cron
+// used by cron, archiving records whether the poll reached its quorum
 func (q *Queries) UpdatePollStatus(ctx context.Context, arg UpdatePollStatusParams) (Poll, error) {
-	row := q.db.QueryRowContext(ctx, updatePollStatus, arg.ID, arg.Status)
+	row := q.db.QueryRowContext(ctx, updatePollStatus, arg.ID, arg.Status, arg.Outcome)
 	var i Poll
 	err := row.Scan(
 		&i.ID,
@@ -581,6 +1553,22 @@ func (q *Queries) UpdatePollStatus(ctx context.Context, arg UpdatePollStatusPara
 		&i.UpdatedAt,
 		&i.ExpiresAt,
 		&i.Status,
+		&i.MaxChoices,
+		&i.PollType,
+		&i.RatingMax,
+		&i.VotesLocked,
+		&i.AllowGuestVotes,
+		&i.StartsAt,
+		&i.Visibility,
+		&i.ResultsVisibility,
+		&i.TieBreak,
+		&i.TieWinnerOptionID,
+		&i.Quorum,
+		&i.QuorumExtensionDays,
+		&i.QuorumExtended,
+		&i.Outcome,
+		&i.DeletedAt,
+		&i.ClonedFrom,
 	)
 	return i, err
 }
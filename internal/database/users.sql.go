@@ -188,9 +188,11 @@ func (q *Queries) GetUserByProviderAndProviderId(ctx context.Context, arg GetUse
 
 const getUserStats = `-- name: GetUserStats :one
 SELECT
-    (SELECT COUNT(*) FROM polls WHERE polls.user_id = $1) as total_polls,
-    (SELECT COUNT(*) FROM comments WHERE comments.poll_id IN (SELECT id FROM polls WHERE polls.user_id = $1)) as total_comments,
-    (SELECT COUNT(*) FROM votes WHERE votes.poll_id IN (SELECT id FROM polls WHERE polls.user_id = $1)) as total_votes
+    (SELECT COUNT(*) FROM polls WHERE polls.user_id = $1 AND polls.deleted_at IS NULL AND polls.status <> 'Draft') as total_polls,
+    (SELECT COUNT(*) FROM comments WHERE comments.poll_id IN (SELECT id FROM polls WHERE polls.user_id = $1 AND polls.deleted_at IS NULL)) as total_comments,
+    (SELECT COUNT(*) FROM votes WHERE votes.poll_id IN (SELECT id FROM polls WHERE polls.user_id = $1 AND polls.deleted_at IS NULL)) as total_votes,
+    (SELECT COUNT(*) FROM polls WHERE polls.user_id = $1 AND polls.deleted_at IS NULL AND polls.outcome = 'decided') as decided_polls,
+    (SELECT COUNT(*) FROM polls WHERE polls.user_id = $1 AND polls.deleted_at IS NULL AND polls.outcome = 'no_quorum') as no_quorum_polls
 FROM users
 WHERE users.id = $1
 `
@@ -199,12 +201,20 @@ type GetUserStatsRow struct {
 	TotalPolls    int64
 	TotalComments int64
 	TotalVotes    int64
+	DecidedPolls  int64
+	NoQuorumPolls int64
 }
 
 func (q *Queries) GetUserStats(ctx context.Context, userID uuid.UUID) (GetUserStatsRow, error) {
 	row := q.db.QueryRowContext(ctx, getUserStats, userID)
 	var i GetUserStatsRow
-	err := row.Scan(&i.TotalPolls, &i.TotalComments, &i.TotalVotes)
+	err := row.Scan(
+		&i.TotalPolls,
+		&i.TotalComments,
+		&i.TotalVotes,
+		&i.DecidedPolls,
+		&i.NoQuorumPolls,
+	)
 	return i, err
 }
 
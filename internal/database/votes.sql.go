@@ -7,25 +7,53 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
+const countVoterVotesByPollID = `-- name: CountVoterVotesByPollID :one
+SELECT COUNT(*) FROM votes
+WHERE poll_id = $1 AND (user_id = $2::uuid OR guest_id = $2::uuid)
+`
+
+type CountVoterVotesByPollIDParams struct {
+	PollID  uuid.UUID
+	VoterID uuid.UUID
+}
+
+// in use in transaction CreateVotesAndUpdateOptionCounts, voter_id is a user or guest ID
+func (q *Queries) CountVoterVotesByPollID(ctx context.Context, arg CountVoterVotesByPollIDParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countVoterVotesByPollID, arg.PollID, arg.VoterID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createVote = `-- name: CreateVote :one
-INSERT INTO votes (poll_id, option_id, user_id)
-VALUES ($1, $2, $3) RETURNING id, poll_id, option_id, created_at, user_id
+INSERT INTO votes (poll_id, option_id, user_id, guest_id, rank)
+VALUES ($1, $2, $3, $4, $5) RETURNING id, poll_id, option_id, created_at, user_id, rank, guest_id
 `
 
 type CreateVoteParams struct {
 	PollID   uuid.UUID
 	OptionID uuid.UUID
-	UserID   uuid.UUID
+	UserID   uuid.NullUUID
+	GuestID  uuid.NullUUID
+	Rank     sql.NullInt32
 }
 
-// in use in transaction CreateVoteAndUpdateOptionCount
+// in use in transaction castBallot, exactly one of user_id and guest_id is set
 func (q *Queries) CreateVote(ctx context.Context, arg CreateVoteParams) (Vote, error) {
-	row := q.db.QueryRowContext(ctx, createVote, arg.PollID, arg.OptionID, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createVote,
+		arg.PollID,
+		arg.OptionID,
+		arg.UserID,
+		arg.GuestID,
+		arg.Rank,
+	)
 	var i Vote
 	err := row.Scan(
 		&i.ID,
@@ -33,10 +61,223 @@ func (q *Queries) CreateVote(ctx context.Context, arg CreateVoteParams) (Vote, e
 		&i.OptionID,
 		&i.CreatedAt,
 		&i.UserID,
+		&i.Rank,
+		&i.GuestID,
 	)
 	return i, err
 }
 
+const deleteVoterVotesByPollID = `-- name: DeleteVoterVotesByPollID :many
+DELETE FROM votes
+WHERE poll_id = $1 AND (user_id = $2::uuid OR guest_id = $2::uuid)
+RETURNING id, poll_id, option_id, created_at, user_id, rank, guest_id
+`
+
+type DeleteVoterVotesByPollIDParams struct {
+	PollID  uuid.UUID
+	VoterID uuid.UUID
+}
+
+// in use in transaction retractBallot, returns the removed rows so option counts can be decremented
+func (q *Queries) DeleteVoterVotesByPollID(ctx context.Context, arg DeleteVoterVotesByPollIDParams) ([]Vote, error) {
+	rows, err := q.db.QueryContext(ctx, deleteVoterVotesByPollID, arg.PollID, arg.VoterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Vote
+	for rows.Next() {
+		var i Vote
+		if err := rows.Scan(
+			&i.ID,
+			&i.PollID,
+			&i.OptionID,
+			&i.CreatedAt,
+			&i.UserID,
+			&i.Rank,
+			&i.GuestID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPollBallotsPage = `-- name: GetPollBallotsPage :many
+SELECT
+    ballots.id,
+    ballots.voter_id,
+    ballots.option_id,
+    ballots.rank,
+    ballots.score,
+    ballots.created_at
+FROM (
+    SELECT votes.id, COALESCE(votes.user_id, votes.guest_id)::uuid as voter_id, votes.option_id, COALESCE(votes.rank, 0)::int as rank, 0::int as score, votes.created_at
+    FROM votes WHERE votes.poll_id = $1
+    UNION ALL
+    SELECT ratings.id, COALESCE(ratings.user_id, ratings.guest_id)::uuid, ratings.option_id, 0, ratings.score, ratings.created_at
+    FROM ratings WHERE ratings.poll_id = $1
+) ballots
+WHERE
+    $2::timestamp IS NULL
+    OR (ballots.created_at, ballots.id) > ($2::timestamp, $3::uuid)
+ORDER BY
+    ballots.created_at,
+    ballots.id
+LIMIT $4
+`
+
+type GetPollBallotsPageParams struct {
+	PollID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageSize        int32
+}
+
+type GetPollBallotsPageRow struct {
+	ID        uuid.UUID
+	VoterID   uuid.UUID
+	OptionID  uuid.UUID
+	Rank      int32
+	Score     int32
+	CreatedAt time.Time
+}
+
+// used by pollhandler.ExportPoll, pages votes and ratings by (created_at, id) after the cursor.
+// rank is 0 for unranked votes and ratings, score is 0 for votes
+func (q *Queries) GetPollBallotsPage(ctx context.Context, arg GetPollBallotsPageParams) ([]GetPollBallotsPageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPollBallotsPage,
+		arg.PollID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPollBallotsPageRow
+	for rows.Next() {
+		var i GetPollBallotsPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.VoterID,
+			&i.OptionID,
+			&i.Rank,
+			&i.Score,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPollTimeline = `-- name: GetPollTimeline :many
+SELECT
+    date_trunc($1::text, ballots.created_at)::timestamp as bucket,
+    ballots.option_id,
+    COUNT(*) as votes
+FROM (
+    SELECT votes.option_id, votes.created_at FROM votes
+    WHERE votes.poll_id = $2 AND (votes.rank IS NULL OR votes.rank = 1)
+    UNION ALL
+    SELECT ratings.option_id, ratings.created_at FROM ratings
+    WHERE ratings.poll_id = $2
+) ballots
+GROUP BY
+    bucket,
+    ballots.option_id
+ORDER BY
+    bucket,
+    ballots.option_id
+`
+
+type GetPollTimelineParams struct {
+	Bucket string
+	PollID uuid.UUID
+}
+
+type GetPollTimelineRow struct {
+	Bucket   time.Time
+	OptionID uuid.UUID
+	Votes    int64
+}
+
+// used by pollhandler.GetPollTimeline, ranked ballots count their first preference
+// and rating polls each rating. Retracted votes are gone so they are not replayed
+func (q *Queries) GetPollTimeline(ctx context.Context, arg GetPollTimelineParams) ([]GetPollTimelineRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPollTimeline, arg.Bucket, arg.PollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPollTimelineRow
+	for rows.Next() {
+		var i GetPollTimelineRow
+		if err := rows.Scan(&i.Bucket, &i.OptionID, &i.Votes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRankedBallotsByPollID = `-- name: GetRankedBallotsByPollID :many
+SELECT COALESCE(user_id, guest_id)::uuid as voter_id, option_id FROM votes
+WHERE poll_id = $1
+ORDER BY voter_id, rank
+`
+
+type GetRankedBallotsByPollIDRow struct {
+	VoterID  uuid.UUID
+	OptionID uuid.UUID
+}
+
+// used by tally.CountRanked, one row per ranked choice in ballot order
+func (q *Queries) GetRankedBallotsByPollID(ctx context.Context, pollID uuid.UUID) ([]GetRankedBallotsByPollIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRankedBallotsByPollID, pollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRankedBallotsByPollIDRow
+	for rows.Next() {
+		var i GetRankedBallotsByPollIDRow
+		if err := rows.Scan(&i.VoterID, &i.OptionID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTotalVotesByPollID = `-- name: GetTotalVotesByPollID :one
 SELECT COUNT(*) FROM votes WHERE poll_id = $1
 `
@@ -85,12 +326,12 @@ func (q *Queries) GetTotalVotesByPollIDs(ctx context.Context, dollar_1 []uuid.UU
 }
 
 const getUserVoteByPollID = `-- name: GetUserVoteByPollID :one
-SELECT id, poll_id, option_id, created_at, user_id FROM votes WHERE poll_id = $1 AND user_id = $2
+SELECT id, poll_id, option_id, created_at, user_id, rank, guest_id FROM votes WHERE poll_id = $1 AND user_id = $2
 `
 
 type GetUserVoteByPollIDParams struct {
 	PollID uuid.UUID
-	UserID uuid.UUID
+	UserID uuid.NullUUID
 }
 
 func (q *Queries) GetUserVoteByPollID(ctx context.Context, arg GetUserVoteByPollIDParams) (Vote, error) {
@@ -102,12 +343,14 @@ func (q *Queries) GetUserVoteByPollID(ctx context.Context, arg GetUserVoteByPoll
 		&i.OptionID,
 		&i.CreatedAt,
 		&i.UserID,
+		&i.Rank,
+		&i.GuestID,
 	)
 	return i, err
 }
 
 const getVotesByOptionID = `-- name: GetVotesByOptionID :many
-SELECT id, poll_id, option_id, created_at, user_id FROM votes WHERE option_id = $1
+SELECT id, poll_id, option_id, created_at, user_id, rank, guest_id FROM votes WHERE option_id = $1
 `
 
 func (q *Queries) GetVotesByOptionID(ctx context.Context, optionID uuid.UUID) ([]Vote, error) {
@@ -125,6 +368,8 @@ func (q *Queries) GetVotesByOptionID(ctx context.Context, optionID uuid.UUID) ([
 			&i.OptionID,
 			&i.CreatedAt,
 			&i.UserID,
+			&i.Rank,
+			&i.GuestID,
 		); err != nil {
 			return nil, err
 		}
@@ -140,10 +385,10 @@ func (q *Queries) GetVotesByOptionID(ctx context.Context, optionID uuid.UUID) ([
 }
 
 const getVotesByUserID = `-- name: GetVotesByUserID :many
-SELECT id, poll_id, option_id, created_at, user_id FROM votes WHERE user_id = $1
+SELECT id, poll_id, option_id, created_at, user_id, rank, guest_id FROM votes WHERE user_id = $1
 `
 
-func (q *Queries) GetVotesByUserID(ctx context.Context, userID uuid.UUID) ([]Vote, error) {
+func (q *Queries) GetVotesByUserID(ctx context.Context, userID uuid.NullUUID) ([]Vote, error) {
 	rows, err := q.db.QueryContext(ctx, getVotesByUserID, userID)
 	if err != nil {
 		return nil, err
@@ -158,6 +403,8 @@ func (q *Queries) GetVotesByUserID(ctx context.Context, userID uuid.UUID) ([]Vot
 			&i.OptionID,
 			&i.CreatedAt,
 			&i.UserID,
+			&i.Rank,
+			&i.GuestID,
 		); err != nil {
 			return nil, err
 		}
@@ -171,3 +418,21 @@ func (q *Queries) GetVotesByUserID(ctx context.Context, userID uuid.UUID) ([]Vot
 	}
 	return items, nil
 }
+
+const rerankBallots = `-- name: RerankBallots :exec
+UPDATE votes
+SET rank = ranked.new_rank
+FROM (
+    SELECT votes.id, (row_number() OVER (PARTITION BY COALESCE(votes.user_id, votes.guest_id) ORDER BY votes.rank))::int as new_rank
+    FROM votes
+    WHERE votes.poll_id = $1 AND votes.rank IS NOT NULL
+) ranked
+WHERE votes.id = ranked.id AND votes.rank <> ranked.new_rank
+`
+
+// in use by transaction RemovePollOption, closes the gap a removed option
+// leaves in ranked ballots so every ballot's first choice has rank 1 again
+func (q *Queries) RerankBallots(ctx context.Context, pollID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, rerankBallots, pollID)
+	return err
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/config"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/lib/pq"
+)
+
+var ErrUnknownCategory = errors.New("unknown or inactive category")
+
+// Category is a poll category with the number of polls filed under it
+type Category struct {
+	Slug          string `json:"slug"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	Active        bool   `json:"active"`
+	ActivePolls   int64  `json:"activePolls"`
+	FinishedPolls int64  `json:"finishedPolls"`
+}
+
+// categoryRequest is the body of the admin create and update endpoints.
+// Omitted fields keep their current value on update.
+type categoryRequest struct {
+	Slug        string  `json:"slug"`
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Active      *bool   `json:"active"`
+}
+
+type categoryHandler struct {
+	cfg *config.APIConfig
+}
+
+func NewCategoryHandler(cfg *config.APIConfig) *categoryHandler {
+	return &categoryHandler{cfg: cfg}
+}
+
+// ListCategories returns the active categories with their poll counts.
+func (h *categoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	h.listCategories(w, r, false)
+}
+
+// AdminListCategories returns every category, including inactive ones.
+func (h *categoryHandler) AdminListCategories(w http.ResponseWriter, r *http.Request) {
+	h.listCategories(w, r, true)
+}
+
+func (h *categoryHandler) listCategories(w http.ResponseWriter, r *http.Request, includeInactive bool) {
+	rows, err := h.cfg.Queries.ListCategoriesWithCounts(r.Context(), includeInactive)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to retrieve categories", err)
+		return
+	}
+
+	categories := make([]Category, len(rows))
+	for i, row := range rows {
+		categories[i] = Category{
+			Slug:          row.Slug,
+			Name:          row.Name,
+			Description:   row.Description,
+			Active:        row.Active,
+			ActivePolls:   row.Activepolls,
+			FinishedPolls: row.Finishedpolls,
+		}
+	}
+	respondWithJSON(w, http.StatusOK, categories)
+}
+
+// CreateCategory adds a category. The slug is derived from the name when it
+// is not given.
+func (h *categoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req categoryRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid request body", err)
+		return
+	}
+	if req.Name == nil || strings.TrimSpace(*req.Name) == "" {
+		respondWithError(w, http.StatusBadRequest, "name", "Name is required", nil)
+		return
+	}
+
+	slug := req.Slug
+	if slug == "" {
+		slug = *req.Name
+	}
+	slug = slugify(slug)
+	if slug == "" {
+		respondWithError(w, http.StatusBadRequest, "slug", "Slug must contain letters or numbers", nil)
+		return
+	}
+
+	params := database.CreateCategoryParams{
+		Slug:   slug,
+		Name:   strings.TrimSpace(*req.Name),
+		Active: true,
+	}
+	if req.Description != nil {
+		params.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Active != nil {
+		params.Active = *req.Active
+	}
+
+	category, err := h.cfg.Queries.CreateCategory(r.Context(), params)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			respondWithError(w, http.StatusConflict, "slug", "A category with this slug already exists", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to create category", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toCategory(category))
+}
+
+// UpdateCategory changes a category's name, description or active flag.
+// Deactivated categories keep their polls but can't be used for new ones.
+func (h *categoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	current, err := h.cfg.Queries.GetCategoryBySlug(r.Context(), r.PathValue("slug"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "Category not found", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+		return
+	}
+
+	var req categoryRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid request body", err)
+		return
+	}
+
+	params := database.UpdateCategoryParams{
+		Slug:        current.Slug,
+		Name:        current.Name,
+		Description: current.Description,
+		Active:      current.Active,
+	}
+	if req.Name != nil {
+		params.Name = strings.TrimSpace(*req.Name)
+		if params.Name == "" {
+			respondWithError(w, http.StatusBadRequest, "name", "Name is required", nil)
+			return
+		}
+	}
+	if req.Description != nil {
+		params.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Active != nil {
+		params.Active = *req.Active
+	}
+
+	category, err := h.cfg.Queries.UpdateCategory(r.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to update category", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toCategory(category))
+}
+
+// DeleteCategory removes a category no poll uses. Categories with polls can
+// only be deactivated.
+func (h *categoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	deleted, err := h.cfg.Queries.DeleteCategory(r.Context(), r.PathValue("slug"))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			respondWithError(w, http.StatusConflict, "slug", "Category still has polls, deactivate it instead", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to delete category", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "Category not found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func respondWithCategoryError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrUnknownCategory) {
+		respondWithError(w, http.StatusBadRequest, "category", "Unknown or inactive category", err)
+		return
+	}
+	respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+}
+
+func toCategory(category database.Category) Category {
+	return Category{
+		Slug:        category.Slug,
+		Name:        category.Name,
+		Description: category.Description,
+		Active:      category.Active,
+	}
+}
+
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify normalizes category and tag names, "Tech & Science" becomes
+// "tech-science".
+func slugify(name string) string {
+	return strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// resolveCategory finds the active category a new or edited poll is filed
+// under. The input is slugified first, so "Tech" finds the "tech" category.
+func resolveCategory(ctx context.Context, q *database.Queries, input string) (database.Category, error) {
+	category, err := q.GetCategoryBySlug(ctx, slugify(input))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.Category{}, ErrUnknownCategory
+		}
+		return database.Category{}, err
+	}
+	if !category.Active {
+		return database.Category{}, ErrUnknownCategory
+	}
+	return category, nil
+}
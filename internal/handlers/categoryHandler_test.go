@@ -0,0 +1,17 @@
+package handlers
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Tech":             "tech",
+		"Tech & Science":   "tech-science",
+		"  --Movies/TV-- ": "movies-tv",
+		"!!!":              "",
+	}
+	for name, want := range tests {
+		if got := slugify(name); got != want {
+			t.Fatalf("slugify(%q): expected %q, got %q", name, want, got)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// ClonePollRequest is the optional body of the clone endpoint. ExpiresAt is
+// the copy's duration in days, the source poll's duration when left out.
+type ClonePollRequest struct {
+	ExpiresAt  string `json:"expiresAt"`
+	Draft      bool   `json:"draft"`
+	LinkSource bool   `json:"linkSource"`
+}
+
+// ClonePoll copies a poll the caller can see into a new poll they own. The
+// copy keeps the source's title, description, category, options and voting
+// rules, and opens now with a fresh expiry unless it is saved as a draft.
+func (h *pollHandler) ClonePoll(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+	isAdmin := claims.Role == "admin"
+
+	var req ClonePollRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid request body", err)
+		return
+	}
+
+	viewer := uuid.NullUUID{UUID: userUUID, Valid: true}
+	_, err := authorizePollView(r.Context(), h.cfg, pollUUID, viewer, isAdmin, r.URL.Query().Get(shareTokenParam))
+	if err != nil {
+		respondWithLifecycleError(w, err)
+		return
+	}
+	source, err := h.cfg.Queries.GetPoll(r.Context(), pollUUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = ErrPollNotFound
+		}
+		respondWithLifecycleError(w, err)
+		return
+	}
+	options, err := h.cfg.Queries.GetOptionsByPollIDs(r.Context(), []uuid.UUID{pollUUID})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to retrieve options", err)
+		return
+	}
+
+	clone := clonePoll(source, options, req)
+	validate := h.validateNewPoll
+	if req.Draft {
+		validate = h.validateDraft
+	}
+	if field, err := validate(r.Context(), &clone); err != nil {
+		respondWithPollValidationError(w, field, err)
+		return
+	}
+
+	cloneID, err := CreatePollWithOptions(r.Context(), h.cfg, clone, userUUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to clone poll", err)
+		return
+	}
+
+	pollResponse, err := h.loadPollResponse(r.Context(), cloneID, userUUID, isAdmin)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, pollResponse)
+}
+
+// clonePoll builds the body of a copy of source. Who can see, vote on and edit
+// the source is not copied, the copy starts with the defaults of a new poll.
+func clonePoll(source database.Poll, options []database.Option, req ClonePollRequest) poll {
+	clone := poll{
+		Title:       source.Title,
+		Description: source.Description,
+		Category:    source.Category,
+		ExpiresAt:   req.ExpiresAt,
+		Type:        string(source.PollType),
+		MaxChoices:  source.MaxChoices,
+		RatingMax:   source.RatingMax,
+	}
+	if clone.ExpiresAt == "" && source.ExpiresAt.After(source.StartsAt) {
+		clone.ExpiresAt = pollDays(source)
+	}
+	for _, option := range options {
+		clone.Options = append(clone.Options, CreateOption{Name: option.Name})
+	}
+	if req.LinkSource {
+		clone.clonedFrom = uuid.NullUUID{UUID: source.ID, Valid: true}
+	}
+	return clone
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestClonePoll(t *testing.T) {
+	startsAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	source := database.Poll{
+		ID:          uuid.New(),
+		Title:       "Lunch this week",
+		Description: "Where should we go?",
+		Category:    "food",
+		PollType:    database.PollTypeStandard,
+		MaxChoices:  2,
+		Visibility:  database.PollVisibilityPrivate,
+		StartsAt:    startsAt,
+		ExpiresAt:   startsAt.Add(7 * 24 * time.Hour),
+	}
+	options := []database.Option{{Name: "Tacos"}, {Name: "Pho"}}
+
+	t.Run("Source duration", func(t *testing.T) {
+		clone := clonePoll(source, options, ClonePollRequest{})
+		if clone.Title != source.Title || clone.Description != source.Description || clone.Category != source.Category {
+			t.Fatalf("expected the source's details, got %q, %q, %q", clone.Title, clone.Description, clone.Category)
+		}
+		if !reflect.DeepEqual(clone.Options, []CreateOption{{Name: "Tacos"}, {Name: "Pho"}}) {
+			t.Fatalf("expected Tacos and Pho, got %v", clone.Options)
+		}
+		if clone.ExpiresAt != "7" {
+			t.Fatalf("expected expiresAt 7, got %q", clone.ExpiresAt)
+		}
+		if clone.MaxChoices != 2 {
+			t.Fatalf("expected maxChoices 2, got %d", clone.MaxChoices)
+		}
+		if clone.Visibility != "" {
+			t.Fatalf("expected default visibility, got %q", clone.Visibility)
+		}
+		if clone.clonedFrom.Valid {
+			t.Fatalf("expected no link to the source, got %s", clone.clonedFrom.UUID)
+		}
+	})
+
+	t.Run("Fresh expiry and link", func(t *testing.T) {
+		clone := clonePoll(source, options, ClonePollRequest{ExpiresAt: "3", LinkSource: true})
+		if clone.ExpiresAt != "3" {
+			t.Fatalf("expected expiresAt 3, got %q", clone.ExpiresAt)
+		}
+		if !clone.clonedFrom.Valid || clone.clonedFrom.UUID != source.ID {
+			t.Fatalf("expected a link to %s, got %v", source.ID, clone.clonedFrom)
+		}
+	})
+
+	t.Run("Draft without expiry", func(t *testing.T) {
+		draft := source
+		draft.ExpiresAt = draft.StartsAt
+		if clone := clonePoll(draft, nil, ClonePollRequest{}); clone.ExpiresAt != "" {
+			t.Fatalf("expected no expiresAt, got %q", clone.ExpiresAt)
+		}
+	})
+}
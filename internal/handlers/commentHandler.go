@@ -3,6 +3,7 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -36,7 +37,8 @@ func NewCommentHandler(cfg *config.APIConfig, filter *trie.Trie[string]) *Commen
 	}
 }
 
-func (h *CommentHandler) GetAllPollComments(w http.ResponseWriter, r *http.Request) {
+// GetAllPollComments lists a poll's comments to anyone who can see the poll.
+func (h *CommentHandler) GetAllPollComments(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
 	pollID := r.PathValue("pollId")
 	if pollID == "" {
 		respondWithError(w, http.StatusBadRequest, "pollId", "Poll ID is required", nil)
@@ -49,16 +51,47 @@ func (h *CommentHandler) GetAllPollComments(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	comments, err := h.cfg.Queries.GetAllCommentsByPollID(r.Context(), pollUUID)
+	limit, cursor, err := getPageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid limit or cursor", err)
+		return
+	}
+
+	viewer, isAdmin := viewerFromClaims(claims)
+	if !h.authorizePoll(w, r, pollUUID, viewer, isAdmin) {
+		return
+	}
+
+	comments, err := h.cfg.Queries.GetAllCommentsByPollID(r.Context(), database.GetAllCommentsByPollIDParams{
+		PollID:          pollUUID,
+		CursorCreatedAt: cursor.at(),
+		CursorID:        cursor.id(),
+		PageSize:        int32(limit + 1),
+	})
 	if err != nil {
 		if err == sql.ErrNoRows {
-			respondWithJSON(w, http.StatusOK, []CommentResponse{})
+			respondWithJSON(w, http.StatusOK, CommentPage{Comments: []CommentResponse{}})
 			return
 		}
 		respondWithError(w, http.StatusInternalServerError, "database", "Failed to retrieve comments", err)
 		return
 	}
-	respondWithJSON(w, http.StatusOK, comments)
+	comments, next := nextCursor(comments, limit, func(comment database.GetAllCommentsByPollIDRow) pageCursor {
+		return pageCursor{At: comment.CreatedAt, ID: comment.ID}
+	})
+
+	commentsResp := make([]CommentResponse, len(comments))
+	for i, comment := range comments {
+		commentsResp[i] = CommentResponse{
+			ID:        comment.ID.String(),
+			UserID:    comment.UserID.String(),
+			UserName:  comment.Username,
+			AvatarUrl: comment.AvatarUrl,
+			Content:   comment.Content,
+			CreatedAt: comment.CreatedAt.Format(time.RFC3339),
+		}
+	}
+	respondWithJSON(w, http.StatusOK, CommentPage{Comments: commentsResp, NextCursor: next})
 
 }
 
@@ -81,6 +114,10 @@ func (h *CommentHandler) CreatePollComment(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if !h.authorizePoll(w, r, pollUUID, uuid.NullUUID{UUID: userUUID, Valid: true}, claims.Role == "admin") {
+		return
+	}
+
 	var comment struct {
 		Content string `json:"content"`
 	}
@@ -156,6 +193,21 @@ func (h *CommentHandler) DeletePollComment(w http.ResponseWriter, r *http.Reques
 	respondWithJSON(w, http.StatusNoContent, nil)
 }
 
+// authorizePoll checks the caller can see the poll before its comments are
+// read or written, responding with 404 when they cannot.
+func (h *CommentHandler) authorizePoll(w http.ResponseWriter, r *http.Request, pollID uuid.UUID, viewer uuid.NullUUID, isAdmin bool) bool {
+	_, err := authorizePollView(r.Context(), h.cfg, pollID, viewer, isAdmin, r.URL.Query().Get(shareTokenParam))
+	if err != nil {
+		if errors.Is(err, ErrPollNotFound) {
+			respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "Poll not found", err)
+			return false
+		}
+		respondWithError(w, http.StatusInternalServerError, "database", "Failed to retrieve poll", err)
+		return false
+	}
+	return true
+}
+
 // Helper to clean comments using the trie filter
 func cleanComment(content string, filter *trie.Trie[string]) string {
 	contentSplit := strings.Fields(content)
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// draftCategory files drafts saved without a category until one is picked
+const draftCategory = "general"
+
+// Draft is a poll saved for later, as listed to its creator.
+type Draft struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	Type        string    `json:"type"`
+	Options     []string  `json:"options"`
+	Tags        []string  `json:"tags"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// DraftPage is one page of the caller's drafts
+type DraftPage struct {
+	Drafts     []Draft `json:"drafts"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// pollValidationError carries the field a poll failed validation on out of a
+// transaction.
+type pollValidationError struct {
+	field string
+	err   error
+}
+
+func (e *pollValidationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *pollValidationError) Unwrap() error {
+	return e.err
+}
+
+// CreateDraft saves a new draft. Drafts take the same body as CreatePoll but
+// may leave out the title, options and expiry.
+func (h *pollHandler) CreateDraft(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	userUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "accessToken", "Invalid access token", err)
+		return
+	}
+
+	var draft poll
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&draft); err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid request body", err)
+		return
+	}
+	if field, err := h.validateDraft(r.Context(), &draft); err != nil {
+		respondWithPollValidationError(w, field, err)
+		return
+	}
+
+	pollID, err := CreatePollWithOptions(r.Context(), h.cfg, draft, userUUID)
+	if err != nil {
+		respondWithDraftError(w, err)
+		return
+	}
+
+	pollResponse, err := h.loadPollResponse(r.Context(), pollID, userUUID, claims.Role == "admin")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, pollResponse)
+}
+
+// SaveDraft saves over one of the caller's drafts.
+func (h *pollHandler) SaveDraft(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+
+	var draft poll
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&draft); err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid request body", err)
+		return
+	}
+	if field, err := h.validateDraft(r.Context(), &draft); err != nil {
+		respondWithPollValidationError(w, field, err)
+		return
+	}
+
+	_, err := SaveDraft(r.Context(), h.cfg, pollUUID, userUUID, claims.Role == "admin", draft)
+	if err != nil {
+		respondWithDraftError(w, err)
+		return
+	}
+
+	h.respondWithUpdatedPoll(w, r, pollUUID, userUUID, claims.Role == "admin")
+}
+
+// PublishDraft runs one of the caller's drafts through the full validation of
+// CreatePoll and opens it.
+func (h *pollHandler) PublishDraft(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+
+	_, err := PublishDraft(r.Context(), h.cfg, pollUUID, userUUID, claims.Role == "admin", func(draft *poll) (string, error) {
+		return h.validateNewPoll(r.Context(), draft)
+	})
+	var validationErr *pollValidationError
+	if errors.As(err, &validationErr) {
+		respondWithPollValidationError(w, validationErr.field, validationErr.err)
+		return
+	}
+	if err != nil {
+		respondWithLifecycleError(w, err)
+		return
+	}
+
+	h.respondWithUpdatedPoll(w, r, pollUUID, userUUID, claims.Role == "admin")
+}
+
+// GetMyDrafts lists the caller's drafts, most recently edited first.
+func (h *pollHandler) GetMyDrafts(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	userUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "accessToken", "Invalid access token", err)
+		return
+	}
+	limit, cursor, err := getPageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid limit or cursor", err)
+		return
+	}
+
+	rows, err := h.cfg.Queries.GetDraftsByUser(r.Context(), database.GetDraftsByUserParams{
+		UserID:          userUUID,
+		CursorUpdatedAt: cursor.at(),
+		CursorID:        cursor.id(),
+		PageSize:        int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to retrieve drafts", err)
+		return
+	}
+	rows, next := nextCursor(rows, limit, func(row database.GetDraftsByUserRow) pageCursor {
+		return pageCursor{At: row.Updatedat, ID: row.Pollid}
+	})
+
+	drafts := make([]Draft, len(rows))
+	for i, row := range rows {
+		drafts[i] = Draft{
+			ID:          row.Pollid,
+			Title:       row.Title,
+			Description: row.Description,
+			Category:    row.Category,
+			Type:        string(row.Polltype),
+			Options:     row.Options,
+			Tags:        row.Tags,
+			CreatedAt:   row.Createdat,
+			UpdatedAt:   row.Updatedat,
+		}
+	}
+	respondWithJSON(w, http.StatusOK, DraftPage{Drafts: drafts, NextCursor: next})
+}
+
+// validateDraft checks what a draft needs to be stored: the settings every
+// poll shares and, when given, a valid type and expiry. The title, options and
+// the rules that depend on them wait until the draft is published.
+func (h *pollHandler) validateDraft(ctx context.Context, draft *poll) (string, error) {
+	if draft.Category == "" {
+		draft.Category = draftCategory
+	}
+	if field, err := h.validatePollSettings(ctx, draft); err != nil {
+		return field, err
+	}
+	if draft.ExpiresAt != "" {
+		if days, err := strconv.Atoi(draft.ExpiresAt); err != nil || days < 1 {
+			return "expiresAt", errors.New("expiresAt must be a whole number of days, at least 1")
+		}
+	}
+
+	switch database.PollType(draft.Type) {
+	case "":
+		draft.Type = string(database.PollTypeStandard)
+	case database.PollTypeStandard, database.PollTypeRanked, database.PollTypeRating:
+	default:
+		return "type", errors.New("invalid poll type")
+	}
+
+	draft.Status = string(database.PollStatusDraft)
+	return "", nil
+}
+
+// draftToPoll turns a stored draft back into a poll body for validation. The
+// expiry is the draft's duration in days and the start time is kept only
+// while it is still ahead.
+func draftToPoll(record database.Poll, options []database.Option, tags []string, now time.Time) poll {
+	draft := poll{
+		Title:               record.Title,
+		Description:         record.Description,
+		Category:            record.Category,
+		ExpiresAt:           pollDays(record),
+		Type:                string(record.PollType),
+		MaxChoices:          record.MaxChoices,
+		RatingMax:           record.RatingMax,
+		LockVotes:           record.VotesLocked,
+		AllowGuestVotes:     record.AllowGuestVotes,
+		Tags:                tags,
+		Visibility:          string(record.Visibility),
+		ResultsVisibility:   string(record.ResultsVisibility),
+		TieBreak:            string(record.TieBreak),
+		Quorum:              &record.Quorum,
+		QuorumExtensionDays: &record.QuorumExtensionDays,
+	}
+	for _, option := range options {
+		draft.Options = append(draft.Options, CreateOption{Name: option.Name})
+	}
+	if record.StartsAt.After(now) {
+		draft.StartsAt = record.StartsAt.Format(time.RFC3339)
+	}
+	return draft
+}
+
+// pollDays is how many whole days a poll runs for, from its start to its
+// expiry.
+func pollDays(record database.Poll) string {
+	return strconv.Itoa(int(math.Round(record.ExpiresAt.Sub(record.StartsAt).Hours() / 24)))
+}
+
+func respondWithDraftError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownAllowlistUser):
+		respondWithAllowlistError(w, err)
+	case errors.Is(err, ErrUnknownEditor):
+		respondWithEditorsError(w, err)
+	default:
+		respondWithLifecycleError(w, err)
+	}
+}
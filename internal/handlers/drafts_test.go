@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+)
+
+func TestDraftToPoll(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	options := []database.Option{{Name: "Red"}, {Name: "Blue"}}
+
+	t.Run("Started draft", func(t *testing.T) {
+		record := database.Poll{
+			Title:     "Favourite colour",
+			Category:  "general",
+			PollType:  database.PollTypeStandard,
+			StartsAt:  now.Add(-time.Hour),
+			ExpiresAt: now.Add(-time.Hour).Add(7 * 24 * time.Hour),
+		}
+		got := draftToPoll(record, options, []string{"colours"}, now)
+		if got.ExpiresAt != "7" {
+			t.Fatalf("expected expiresAt 7, got %q", got.ExpiresAt)
+		}
+		if got.StartsAt != "" {
+			t.Fatalf("expected no start time, got %q", got.StartsAt)
+		}
+		if !reflect.DeepEqual(got.Options, []CreateOption{{Name: "Red"}, {Name: "Blue"}}) {
+			t.Fatalf("expected Red and Blue, got %v", got.Options)
+		}
+		if !reflect.DeepEqual(got.Tags, []string{"colours"}) {
+			t.Fatalf("expected [colours], got %v", got.Tags)
+		}
+	})
+
+	t.Run("Scheduled draft", func(t *testing.T) {
+		startsAt := now.Add(48 * time.Hour)
+		record := database.Poll{StartsAt: startsAt, ExpiresAt: startsAt.Add(24 * time.Hour)}
+		got := draftToPoll(record, nil, nil, now)
+		if got.StartsAt != startsAt.Format(time.RFC3339) {
+			t.Fatalf("expected start time %s, got %q", startsAt.Format(time.RFC3339), got.StartsAt)
+		}
+		if got.ExpiresAt != "1" {
+			t.Fatalf("expected expiresAt 1, got %q", got.ExpiresAt)
+		}
+	})
+
+	t.Run("Draft without expiry", func(t *testing.T) {
+		record := database.Poll{StartsAt: now, ExpiresAt: now}
+		if got := draftToPoll(record, nil, nil, now); got.ExpiresAt != "0" {
+			t.Fatalf("expected expiresAt 0, got %q", got.ExpiresAt)
+		}
+	})
+}
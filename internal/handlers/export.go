@@ -0,0 +1,402 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// exportPageSize is how many ballots or comments are read and written at a
+// time, so large polls are streamed instead of held in memory
+const exportPageSize = 500
+
+// ExportMetadata describes the exported poll.
+type ExportMetadata struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	Type        string    `json:"type"`
+	Status      string    `json:"status"`
+	Visibility  string    `json:"visibility"`
+	StartsAt    time.Time `json:"startsAt"`
+	EndsAt      time.Time `json:"endsAt"`
+	Quorum      int32     `json:"quorum"`
+	Outcome     string    `json:"outcome"`
+	TotalVotes  int64     `json:"totalVotes"`
+	Turnout     int64     `json:"turnout"`
+	Winner      string    `json:"winner"`
+	ExportedAt  time.Time `json:"exportedAt"`
+}
+
+// ExportOption is one option of the exported poll with its final standing.
+type ExportOption struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Position   int32   `json:"position"`
+	Votes      int64   `json:"votes"`
+	Percentage float64 `json:"percentage"`
+	Rank       int     `json:"rank"`
+}
+
+// ExportBallot is one vote or rating. Voters are replaced by a pseudonym that
+// is stable within the poll.
+type ExportBallot struct {
+	Voter     string    `json:"voter"`
+	OptionID  uuid.UUID `json:"optionId"`
+	Rank      int32     `json:"rank,omitempty"`
+	Score     int32     `json:"score,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ExportComment is one comment, its author replaced by the same pseudonym
+// their ballots get.
+type ExportComment struct {
+	Author    string    `json:"author"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ExportPoll streams a poll's metadata, options with counts and anonymized
+// ballots as CSV or JSON. comments=true adds the poll's comments. Only the
+// creator and admins can export a poll.
+func (h *pollHandler) ExportPoll(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+	format, err := parseFileFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "format", err.Error(), err)
+		return
+	}
+	withComments := r.URL.Query().Get("comments") == "true"
+
+	isAdmin := claims.Role == "admin"
+	err = h.authorizePollAccess(r.Context(), pollUUID, userUUID, isAdmin, permissionManage)
+	if err != nil {
+		respondWithLifecycleError(w, err)
+		return
+	}
+
+	pollResponse, err := h.loadPollResponse(r.Context(), pollUUID, userUUID, isAdmin)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+		return
+	}
+
+	var export exportWriter
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		export = &csvExport{w: csv.NewWriter(w)}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		export = &jsonExport{w: w}
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"poll-%s.%s\"", pollUUID, format))
+	w.WriteHeader(http.StatusOK)
+
+	// The status is already sent, a failure from here on can only cut the
+	// export short
+	if err := h.streamExport(r, http.NewResponseController(w), export, pollResponse, withComments); err != nil {
+		log.Printf("Error exporting poll %s: %v", pollUUID, err)
+	}
+}
+
+// streamExport writes the export a page at a time, flushing each page to the
+// client before the next one is read.
+func (h *pollHandler) streamExport(r *http.Request, rc *http.ResponseController, export exportWriter, pollResponse PollResponse, withComments bool) error {
+	pollID := pollResponse.ID
+	if err := export.header(exportMetadata(pollResponse), exportOptions(pollResponse)); err != nil {
+		return err
+	}
+
+	if err := export.section("ballots", []string{"voter", "option_id", "rank", "score", "created_at"}); err != nil {
+		return err
+	}
+	params := database.GetPollBallotsPageParams{PollID: pollID, PageSize: exportPageSize}
+	for {
+		ballots, err := h.cfg.Queries.GetPollBallotsPage(r.Context(), params)
+		if err != nil {
+			return err
+		}
+		for _, ballot := range ballots {
+			err := export.row(ExportBallot{
+				Voter:     auth.MakePseudonym(ballot.VoterID, pollID, h.cfg.GhostvoxSecretKey),
+				OptionID:  ballot.OptionID,
+				Rank:      ballot.Rank,
+				Score:     ballot.Score,
+				CreatedAt: ballot.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if err := export.flush(); err != nil {
+			return err
+		}
+		_ = rc.Flush()
+		if len(ballots) < exportPageSize {
+			break
+		}
+		last := ballots[len(ballots)-1]
+		params.CursorCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: last.ID, Valid: true}
+	}
+
+	if withComments {
+		if err := export.section("comments", []string{"author", "content", "created_at"}); err != nil {
+			return err
+		}
+		params := database.GetAllCommentsByPollIDParams{PollID: pollID, PageSize: exportPageSize}
+		for {
+			comments, err := h.cfg.Queries.GetAllCommentsByPollID(r.Context(), params)
+			if err != nil {
+				return err
+			}
+			for _, comment := range comments {
+				err := export.row(ExportComment{
+					Author:    auth.MakePseudonym(comment.UserID, pollID, h.cfg.GhostvoxSecretKey),
+					Content:   comment.Content,
+					CreatedAt: comment.CreatedAt,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			if err := export.flush(); err != nil {
+				return err
+			}
+			_ = rc.Flush()
+			if len(comments) < exportPageSize {
+				break
+			}
+			last := comments[len(comments)-1]
+			params.CursorCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+			params.CursorID = uuid.NullUUID{UUID: last.ID, Valid: true}
+		}
+	}
+
+	return export.close()
+}
+
+// parseFileFormat validates the format of poll exports and imports, json
+// when empty.
+func parseFileFormat(format string) (string, error) {
+	switch format {
+	case "":
+		return "json", nil
+	case "csv", "json":
+		return format, nil
+	default:
+		return "", errors.New("format must be csv or json")
+	}
+}
+
+func exportMetadata(pollResponse PollResponse) ExportMetadata {
+	metadata := ExportMetadata{
+		ID:          pollResponse.ID,
+		Title:       pollResponse.Title,
+		Description: pollResponse.Description,
+		Category:    pollResponse.Category,
+		Type:        pollResponse.Type,
+		Status:      pollResponse.Status,
+		Visibility:  pollResponse.Visibility,
+		StartsAt:    pollResponse.StartsAt,
+		EndsAt:      pollResponse.EndedAt,
+		Quorum:      pollResponse.Quorum,
+		Outcome:     pollResponse.Outcome,
+		Winner:      pollResponse.Winner,
+		ExportedAt:  time.Now().UTC(),
+	}
+	if pollResponse.Results != nil {
+		metadata.TotalVotes = pollResponse.Results.TotalVotes
+		metadata.Turnout = pollResponse.Results.Turnout
+	}
+	return metadata
+}
+
+func exportOptions(pollResponse PollResponse) []ExportOption {
+	options := make([]ExportOption, len(pollResponse.Options))
+	for i, option := range pollResponse.Options {
+		options[i] = ExportOption{
+			ID:       option.ID,
+			Name:     option.Name,
+			Position: option.Position,
+			Votes:    int64(option.Count),
+		}
+		if pollResponse.Results == nil {
+			continue
+		}
+		for _, standing := range pollResponse.Results.Options {
+			if standing.OptionID == option.ID {
+				options[i].Percentage = standing.Percentage
+				options[i].Rank = standing.Rank
+			}
+		}
+	}
+	return options
+}
+
+// exportWriter writes an export in one format. The header carries the poll
+// and its options, each section a list of rows that share columns.
+type exportWriter interface {
+	header(metadata ExportMetadata, options []ExportOption) error
+	section(name string, columns []string) error
+	row(value exportRecord) error
+	flush() error
+	close() error
+}
+
+// exportRecord is a row of an export section, record returns its CSV columns.
+type exportRecord interface {
+	record() []string
+}
+
+func (b ExportBallot) record() []string {
+	return []string{b.Voter, b.OptionID.String(), formatOptionalInt(b.Rank), formatOptionalInt(b.Score), b.CreatedAt.Format(time.RFC3339)}
+}
+
+func (c ExportComment) record() []string {
+	return []string{c.Author, c.Content, c.CreatedAt.Format(time.RFC3339)}
+}
+
+func formatOptionalInt(value int32) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(value))
+}
+
+// csvExport writes each part as a block with its own header row, blocks are
+// separated by an empty line.
+type csvExport struct {
+	w *csv.Writer
+}
+
+func (e *csvExport) header(metadata ExportMetadata, options []ExportOption) error {
+	records := [][]string{
+		{"poll_id", "title", "description", "category", "type", "status", "visibility", "starts_at", "ends_at", "quorum", "outcome", "total_votes", "turnout", "winner", "exported_at"},
+		{
+			metadata.ID.String(), metadata.Title, metadata.Description, metadata.Category, metadata.Type, metadata.Status, metadata.Visibility,
+			metadata.StartsAt.Format(time.RFC3339), metadata.EndsAt.Format(time.RFC3339), strconv.Itoa(int(metadata.Quorum)), metadata.Outcome,
+			strconv.FormatInt(metadata.TotalVotes, 10), strconv.FormatInt(metadata.Turnout, 10), metadata.Winner, metadata.ExportedAt.Format(time.RFC3339),
+		},
+		{},
+		{"option_id", "name", "position", "votes", "percentage", "rank"},
+	}
+	for _, option := range options {
+		records = append(records, []string{
+			option.ID, option.Name, strconv.Itoa(int(option.Position)), strconv.FormatInt(option.Votes, 10),
+			strconv.FormatFloat(option.Percentage, 'f', -1, 64), strconv.Itoa(option.Rank),
+		})
+	}
+	return e.w.WriteAll(records)
+}
+
+func (e *csvExport) section(_ string, columns []string) error {
+	if err := e.w.Write([]string{}); err != nil {
+		return err
+	}
+	return e.w.Write(columns)
+}
+
+func (e *csvExport) row(value exportRecord) error {
+	return e.w.Write(value.record())
+}
+
+func (e *csvExport) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExport) close() error {
+	return e.flush()
+}
+
+// jsonExport writes one object with the poll, its options and an array per
+// section, encoding rows one at a time.
+type jsonExport struct {
+	w           io.Writer
+	openSection bool
+	rows        int
+}
+
+func (e *jsonExport) header(metadata ExportMetadata, options []ExportOption) error {
+	if _, err := io.WriteString(e.w, `{"poll":`); err != nil {
+		return err
+	}
+	if err := e.write(metadata); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(e.w, `,"options":`); err != nil {
+		return err
+	}
+	return e.write(options)
+}
+
+func (e *jsonExport) section(name string, _ []string) error {
+	if err := e.endSection(); err != nil {
+		return err
+	}
+	key, err := json.Marshal(name)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(e.w, ",%s:[", key); err != nil {
+		return err
+	}
+	e.openSection, e.rows = true, 0
+	return nil
+}
+
+func (e *jsonExport) row(value exportRecord) error {
+	if e.rows > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.rows++
+	return e.write(value)
+}
+
+func (e *jsonExport) flush() error {
+	return nil
+}
+
+func (e *jsonExport) close() error {
+	if err := e.endSection(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "}")
+	return err
+}
+
+func (e *jsonExport) endSection() error {
+	if !e.openSection {
+		return nil
+	}
+	e.openSection = false
+	_, err := io.WriteString(e.w, "]")
+	return err
+}
+
+func (e *jsonExport) write(value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestExportWriters(t *testing.T) {
+	pollID := uuid.New()
+	optionID := uuid.New()
+	createdAt := time.Date(2025, 10, 6, 9, 0, 0, 0, time.UTC)
+	metadata := ExportMetadata{ID: pollID, Title: "Lunch", Type: "Standard"}
+	options := []ExportOption{{ID: optionID.String(), Name: "Pizza, obviously", Votes: 2, Percentage: 100, Rank: 1}}
+
+	write := func(export exportWriter) {
+		t.Helper()
+		if err := export.header(metadata, options); err != nil {
+			t.Fatalf("header: %v", err)
+		}
+		if err := export.section("ballots", []string{"voter", "option_id", "rank", "score", "created_at"}); err != nil {
+			t.Fatalf("section: %v", err)
+		}
+		for _, voter := range []string{"aaaa", "bbbb"} {
+			if err := export.row(ExportBallot{Voter: voter, OptionID: optionID, CreatedAt: createdAt}); err != nil {
+				t.Fatalf("row: %v", err)
+			}
+		}
+		if err := export.section("comments", []string{"author", "content", "created_at"}); err != nil {
+			t.Fatalf("section: %v", err)
+		}
+		if err := export.close(); err != nil {
+			t.Fatalf("close: %v", err)
+		}
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		write(&jsonExport{w: &out})
+
+		var decoded struct {
+			Poll     ExportMetadata  `json:"poll"`
+			Options  []ExportOption  `json:"options"`
+			Ballots  []ExportBallot  `json:"ballots"`
+			Comments []ExportComment `json:"comments"`
+		}
+		if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+			t.Fatalf("expected valid JSON, got %v: %s", err, out.String())
+		}
+		if decoded.Poll.ID != pollID || len(decoded.Options) != 1 {
+			t.Fatalf("expected the poll and its option, got %+v", decoded)
+		}
+		if len(decoded.Ballots) != 2 || decoded.Ballots[1].Voter != "bbbb" {
+			t.Fatalf("expected 2 ballots, got %+v", decoded.Ballots)
+		}
+		if decoded.Comments == nil || len(decoded.Comments) != 0 {
+			t.Fatalf("expected an empty comments section, got %+v", decoded.Comments)
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		var out bytes.Buffer
+		write(&csvExport{w: csv.NewWriter(&out)})
+
+		blocks := strings.Split(strings.TrimSpace(out.String()), "\n\n")
+		if len(blocks) != 4 {
+			t.Fatalf("expected 4 blocks, got %d: %q", len(blocks), out.String())
+		}
+		if !strings.Contains(blocks[1], `"Pizza, obviously"`) {
+			t.Fatalf("expected the option name to be quoted, got %q", blocks[1])
+		}
+		if rows := strings.Split(blocks[2], "\n"); len(rows) != 3 || !strings.HasPrefix(rows[1], "aaaa,"+optionID.String()+",,,") {
+			t.Fatalf("expected a header and 2 ballots, got %q", blocks[2])
+		}
+		if blocks[3] != "author,content,created_at" {
+			t.Fatalf("expected an empty comments block, got %q", blocks[3])
+		}
+	})
+}
+
+func TestParseExportFormat(t *testing.T) {
+	if format, err := parseFileFormat(""); err != nil || format != "json" {
+		t.Fatalf("expected json, got %q (%v)", format, err)
+	}
+	if format, err := parseFileFormat("csv"); err != nil || format != "csv" {
+		t.Fatalf("expected csv, got %q (%v)", format, err)
+	}
+	if _, err := parseFileFormat("xlsx"); err == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+}
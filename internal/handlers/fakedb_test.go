@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+)
+
+// fakeResult is what a fakeDB answers to one sqlc query. rowsAffected is
+// what a statement registered under its name reports.
+type fakeResult struct {
+	columns      []string
+	rows         [][]driver.Value
+	rowsAffected int64
+}
+
+// newFakeDB opens a database that answers each sqlc query with the result
+// registered under its name, so transactions can be tested without Postgres.
+// Statements without a result affect one row, queries without one fail.
+func newFakeDB(t *testing.T, results map[string]fakeResult) *sql.DB {
+	db, _ := newRecordingFakeDB(t, results)
+	return db
+}
+
+// newRecordingFakeDB is newFakeDB that also records the name of every
+// statement executed, in order.
+func newRecordingFakeDB(t *testing.T, results map[string]fakeResult) (*sql.DB, *[]string) {
+	t.Helper()
+	execs := &[]string{}
+	db := sql.OpenDB(fakeConnector{results: results, execs: execs, args: map[string][]driver.Value{}})
+	t.Cleanup(func() { db.Close() })
+	return db, execs
+}
+
+// newArgsFakeDB is newFakeDB that also records the arguments each query was
+// last run with, by name.
+func newArgsFakeDB(t *testing.T, results map[string]fakeResult) (*sql.DB, map[string][]driver.Value) {
+	t.Helper()
+	args := map[string][]driver.Value{}
+	db := sql.OpenDB(fakeConnector{results: results, execs: &[]string{}, args: args})
+	t.Cleanup(func() { db.Close() })
+	return db, args
+}
+
+type fakeConnector struct {
+	results map[string]fakeResult
+	execs   *[]string
+	args    map[string][]driver.Value
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn(c), nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fake driver is opened through its connector")
+}
+
+type fakeConn struct {
+	results map[string]fakeResult
+	execs   *[]string
+	args    map[string][]driver.Value
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{conn: c, name: queryName(query)}, nil
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	conn fakeConn
+	name string
+}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	*s.conn.execs = append(*s.conn.execs, s.name)
+	if result, ok := s.conn.results[s.name]; ok {
+		return driver.RowsAffected(result.rowsAffected), nil
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.args[s.name] = args
+	result, ok := s.conn.results[s.name]
+	if !ok {
+		return nil, errors.New("fake database has no result for " + s.name)
+	}
+	return &fakeRows{result: result}, nil
+}
+
+type fakeRows struct {
+	result fakeResult
+	next   int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.result.columns
+}
+
+func (*fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
+
+// queryName reads the name sqlc puts on the first line of every query
+func queryName(query string) string {
+	line, _, _ := strings.Cut(query, "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 3 || fields[1] != "name:" {
+		return line
+	}
+	return fields[2]
+}
+
+// fakePollRow answers a query selecting every column of polls with poll
+func fakePollRow(poll database.Poll) fakeResult {
+	return fakeResult{
+		columns: []string{"id", "user_id", "title", "description", "category", "created_at", "updated_at", "expires_at", "status", "max_choices", "poll_type", "rating_max", "votes_locked", "allow_guest_votes", "starts_at", "visibility", "results_visibility", "tie_break", "tie_winner_option_id", "quorum", "quorum_extension_days", "quorum_extended", "outcome", "deleted_at", "cloned_from"},
+		rows: [][]driver.Value{{
+			poll.ID.String(), poll.UserID.String(), poll.Title, poll.Description, poll.Category, poll.CreatedAt, poll.UpdatedAt, poll.ExpiresAt,
+			string(poll.Status), int64(poll.MaxChoices), string(poll.PollType), int64(poll.RatingMax), poll.VotesLocked, poll.AllowGuestVotes, poll.StartsAt,
+			string(poll.Visibility), string(poll.ResultsVisibility), string(poll.TieBreak), nil, int64(poll.Quorum), int64(poll.QuorumExtensionDays), poll.QuorumExtended,
+			nil, nil, nil,
+		}},
+	}
+}
+
+// fakePollDetailRow answers a poll query selecting poll_details with poll and
+// an empty ballot, extra holds the query's own columns that follow
+func fakePollDetailRow(poll database.PollDetail, extra ...driver.Value) []driver.Value {
+	return append([]driver.Value{
+		poll.ID.String(), poll.Title, poll.Category, poll.Description, poll.ExpiresAt, string(poll.Status), int64(poll.MaxChoices),
+		string(poll.PollType), int64(poll.RatingMax), poll.VotesLocked, poll.AllowGuestVotes, poll.StartsAt, string(poll.Visibility),
+		string(poll.ResultsVisibility), poll.CreatorID.String(), string(poll.TieBreak), nil, int64(poll.Quorum), nil, nil,
+		poll.CreatedAt, poll.UpdatedAt, poll.CreatorFirstName, poll.CreatorLastName.String, poll.Votes, poll.Comments, poll.Voters,
+		[]byte(poll.Options), []byte("[]"), nil, []byte("{}"), "{" + strings.Join(poll.Tags, ",") + "}",
+		"{}", []byte("{}"),
+	}, extra...)
+}
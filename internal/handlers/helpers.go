@@ -23,9 +23,9 @@ func NullStringHelper(value interface{}) sql.NullString {
 }
 
 func getLimitAndOffset(r *http.Request) (limit, offset int, err error) {
-	limitParam := r.URL.Query().Get("limit")
-	if limitParam == "" {
-		limitParam = "20"
+	limit, err = getPageLimit(r, defaultPageSize)
+	if err != nil {
+		return 0, 0, err
 	}
 
 	offsetParam := r.URL.Query().Get("offset")
@@ -33,35 +33,41 @@ func getLimitAndOffset(r *http.Request) (limit, offset int, err error) {
 		offsetParam = "0"
 	}
 
-	limit, err = strconv.Atoi(limitParam)
-	if err != nil {
-		err = fmt.Errorf("Invalid limit parameter: %w", err)
-		return 0, 0, err
-	}
-
 	offset, err = strconv.Atoi(offsetParam)
 	if err != nil {
 		err = fmt.Errorf("Invalid offset parameter: %w", err)
 		return 0, 0, err
 	}
+	if offset < 0 {
+		return 0, 0, fmt.Errorf("Invalid offset parameter: must not be negative")
+	}
 
 	return limit, offset, nil
 }
 
-func getWinner(options []Option) string {
-	currentWinner := ""
-	currentCount := int32(0)
-	for _, option := range options {
-		if option.Count > currentCount {
-			currentWinner = option.ID
-			currentCount = option.Count
-			continue
-		}
-		if option.Count == currentCount {
-			currentWinner = ""
-		}
+// parseStatusFilter maps the status query parameter used by the list
+// endpoints to a poll status. An empty value matches every status.
+func parseStatusFilter(status string) (database.NullPollStatus, error) {
+	switch status {
+	case "":
+		return database.NullPollStatus{}, nil
+	case "active":
+		return database.NullPollStatus{PollStatus: database.PollStatusActive, Valid: true}, nil
+	case "finished":
+		return database.NullPollStatus{PollStatus: database.PollStatusArchived, Valid: true}, nil
+	case "upcoming":
+		return database.NullPollStatus{PollStatus: database.PollStatusInactive, Valid: true}, nil
+	default:
+		return database.NullPollStatus{}, fmt.Errorf("status must be active, finished or upcoming")
 	}
-	return currentWinner
+}
+
+// parseCategoryFilter maps the category query parameter used by the list
+// endpoints to a category slug. Names are slugified like resolveCategory does,
+// so "Tech" filters on "tech". An empty value matches every category.
+func parseCategoryFilter(category string) sql.NullString {
+	slug := slugify(category)
+	return sql.NullString{String: slug, Valid: slug != ""}
 }
 
 func SetCookiesHelper(w http.ResponseWriter, code int, refreshToken, accessToken string, cfg *config.APIConfig) {
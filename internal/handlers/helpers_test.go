@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+)
+
+func TestParseStatusFilter(t *testing.T) {
+	t.Run("Empty matches every status", func(t *testing.T) {
+		status, err := parseStatusFilter("")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if status.Valid {
+			t.Fatalf("expected no status filter, got %q", status.PollStatus)
+		}
+	})
+
+	t.Run("Finished polls are archived", func(t *testing.T) {
+		status, err := parseStatusFilter("finished")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if !status.Valid || status.PollStatus != database.PollStatusArchived {
+			t.Fatalf("expected Archived, got %+v", status)
+		}
+	})
+
+	t.Run("Unknown status", func(t *testing.T) {
+		if _, err := parseStatusFilter("deleted"); err == nil {
+			t.Fatalf("expected an error for an unknown status")
+		}
+	})
+}
+
+func TestParseCategoryFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		category string
+		want     string
+		valid    bool
+	}{
+		{name: "Empty matches every category", category: "", valid: false},
+		{name: "Slug", category: "technology", want: "technology", valid: true},
+		{name: "Name is slugified", category: "Tech & Science", want: "tech-science", valid: true},
+		{name: "Wildcards are not patterns", category: "%", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseCategoryFilter(tt.category)
+			if got.Valid != tt.valid || got.String != tt.want {
+				t.Fatalf("expected %q (valid %v), got %q (valid %v)", tt.want, tt.valid, got.String, got.Valid)
+			}
+		})
+	}
+}
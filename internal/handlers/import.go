@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxImportBytes caps the size of an uploaded import file
+	maxImportBytes = 5 << 20
+	// maxImportRows caps how many polls one import can create
+	maxImportRows = 1000
+	// importBatchSize is how many polls are inserted per transaction
+	importBatchSize = 100
+	// importListSeparator splits options, tags, allowlists and editors in CSV cells
+	importListSeparator = "|"
+)
+
+// ErrInvalidImportFile is returned when an import file cannot be read at
+// all, as opposed to rows that fail on their own.
+var ErrInvalidImportFile = errors.New("invalid import file")
+
+// importColumns are the CSV columns an import file may use. title and
+// options are required, every other column falls back to the CreatePoll
+// default when missing or empty.
+var importColumns = []string{
+	"title", "description", "category", "expiresAt", "startsAt", "type", "options",
+	"tags", "maxChoices", "ratingMax", "lockVotes", "allowGuestVotes", "visibility",
+	"allowlist", "resultsVisibility", "tieBreak", "quorum", "quorumExtensionDays",
+	"editors",
+}
+
+// ImportReport is the outcome of a bulk import, one result per row of the file.
+type ImportReport struct {
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// ImportRowResult is the outcome of one row. Rows are numbered from 1, not
+// counting the CSV header.
+type ImportRowResult struct {
+	Row    int    `json:"row"`
+	Title  string `json:"title"`
+	PollID string `json:"pollId,omitempty"`
+	Field  string `json:"field,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// importRow is a poll read from an import file, or the reason it could not
+// be read.
+type importRow struct {
+	poll  poll
+	field string
+	err   error
+}
+
+// ImportPolls creates polls in bulk from a CSV or JSON file, for admins
+// migrating question banks from other tools. Every row goes through the same
+// validation as CreatePoll, and the response reports each row's outcome.
+func (h *pollHandler) ImportPolls(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	userUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "accessToken", "Invalid access token", err)
+		return
+	}
+	format, err := parseFileFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "format", err.Error(), err)
+		return
+	}
+
+	defer r.Body.Close()
+	report, err := h.ImportPollFile(r.Context(), http.MaxBytesReader(w, r.Body, maxImportBytes), format, userUUID)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			respondWithError(w, http.StatusRequestEntityTooLarge, "file", "Import file is too large", err)
+		case errors.Is(err, ErrInvalidImportFile):
+			respondWithError(w, http.StatusBadRequest, "file", err.Error(), err)
+		default:
+			respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+// ImportPollFile reads polls from file, validates them and creates the valid
+// ones owned by userUUID, importBatchSize at a time. It is shared by the admin
+// endpoint and the import command. A batch that fails as a whole marks its
+// rows and every later row as failed, batches before it stay committed.
+func (h *pollHandler) ImportPollFile(ctx context.Context, file io.Reader, format string, userUUID uuid.UUID) (ImportReport, error) {
+	var rows []importRow
+	var err error
+	if format == "csv" {
+		rows, err = readImportCSV(file)
+	} else {
+		rows, err = readImportJSON(file)
+	}
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	now := time.Now()
+	report := ImportReport{Total: len(rows), Rows: make([]ImportRowResult, len(rows))}
+	var pending []int
+	for i := range rows {
+		row := &rows[i]
+		if row.err == nil {
+			row.poll.ExpiresAt, err = importExpiry(row.poll.ExpiresAt, row.poll.StartsAt, now)
+			if err != nil {
+				row.field, row.err = "expiresAt", err
+			}
+		}
+		if row.err == nil {
+			row.field, row.err = h.validateNewPoll(ctx, &row.poll)
+			if row.err != nil && row.field == "" {
+				return ImportReport{}, row.err
+			}
+		}
+
+		report.Rows[i] = ImportRowResult{Row: i + 1, Title: row.poll.Title}
+		if row.err != nil {
+			report.Rows[i].Field = row.field
+			report.Rows[i].Error = row.err.Error()
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	for start := 0; start < len(pending); start += importBatchSize {
+		batch := pending[start:min(start+importBatchSize, len(pending))]
+		polls := make([]poll, len(batch))
+		for i, index := range batch {
+			polls[i] = rows[index].poll
+		}
+
+		ids, errs, err := CreatePollsWithOptions(ctx, h.cfg, polls, userUUID)
+		if err != nil {
+			log.Printf("Import stopped at row %d: %v", batch[0]+1, err)
+			for _, index := range pending[start:] {
+				report.Rows[index].Error = "not imported, the batch holding this row failed"
+			}
+			break
+		}
+		for i, index := range batch {
+			switch {
+			case errs[i] == nil:
+				report.Rows[index].PollID = ids[i].String()
+			case errors.Is(errs[i], ErrUnknownAllowlistUser):
+				report.Rows[index].Field = "allowlist"
+				report.Rows[index].Error = "allowlist names a user that does not exist"
+			case errors.Is(errs[i], ErrUnknownEditor):
+				report.Rows[index].Field = "editors"
+				report.Rows[index].Error = "editors names a user that does not exist"
+			default:
+				log.Printf("Import of row %d failed: %v", index+1, errs[i])
+				report.Rows[index].Error = "failed to create poll"
+			}
+		}
+	}
+
+	for _, row := range report.Rows {
+		if row.Error == "" {
+			report.Created++
+		} else {
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// readImportJSON reads a JSON array of polls shaped like CreatePoll bodies.
+// An element that does not decode fails only its own row.
+func readImportJSON(file io.Reader) ([]importRow, error) {
+	var elements []json.RawMessage
+	if err := json.NewDecoder(file).Decode(&elements); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: expected a JSON array of polls", ErrInvalidImportFile)
+	}
+	if len(elements) > maxImportRows {
+		return nil, fmt.Errorf("%w: more than %d polls", ErrInvalidImportFile, maxImportRows)
+	}
+
+	rows := make([]importRow, len(elements))
+	for i, element := range elements {
+		if err := json.Unmarshal(element, &rows[i].poll); err != nil {
+			rows[i].err = errors.New("row is not a valid poll object")
+		}
+	}
+	return rows, nil
+}
+
+// readImportCSV reads polls from a CSV file with a header row naming
+// importColumns. Lists are separated by importListSeparator.
+func readImportCSV(file io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: missing header row", ErrInvalidImportFile)
+		}
+		return nil, importReadError(err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(importColumns, name) {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidImportFile, name)
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"title", "options"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidImportFile, required)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, importReadError(err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("%w: more than %d polls", ErrInvalidImportFile, maxImportRows)
+		}
+		if len(record) != len(header) {
+			rows = append(rows, importRow{err: fmt.Errorf("row has %d fields, the header has %d", len(record), len(header))})
+			continue
+		}
+		rows = append(rows, csvImportRow(columns, record))
+	}
+}
+
+// csvImportRow turns one CSV record into a poll.
+func csvImportRow(columns map[string]int, record []string) importRow {
+	cell := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	list := func(name string) []string {
+		var values []string
+		for _, value := range strings.Split(cell(name), importListSeparator) {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+
+	row := importRow{poll: poll{
+		Title:             cell("title"),
+		Description:       cell("description"),
+		Category:          cell("category"),
+		ExpiresAt:         cell("expiresAt"),
+		StartsAt:          cell("startsAt"),
+		Type:              cell("type"),
+		Tags:              list("tags"),
+		Visibility:        cell("visibility"),
+		Allowlist:         list("allowlist"),
+		ResultsVisibility: cell("resultsVisibility"),
+		TieBreak:          cell("tieBreak"),
+		Editors:           list("editors"),
+	}}
+	for _, name := range list("options") {
+		row.poll.Options = append(row.poll.Options, CreateOption{Name: name})
+	}
+
+	numbers := map[string]*int32{}
+	for _, name := range []string{"maxChoices", "ratingMax", "quorum", "quorumExtensionDays"} {
+		if cell(name) == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(cell(name), 10, 32)
+		if err != nil {
+			row.field, row.err = name, fmt.Errorf("%s must be a whole number", name)
+			return row
+		}
+		value := int32(parsed)
+		numbers[name] = &value
+	}
+	if numbers["maxChoices"] != nil {
+		row.poll.MaxChoices = *numbers["maxChoices"]
+	}
+	if numbers["ratingMax"] != nil {
+		row.poll.RatingMax = *numbers["ratingMax"]
+	}
+	row.poll.Quorum = numbers["quorum"]
+	row.poll.QuorumExtensionDays = numbers["quorumExtensionDays"]
+
+	for _, name := range []string{"lockVotes", "allowGuestVotes"} {
+		if cell(name) == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(cell(name))
+		if err != nil {
+			row.field, row.err = name, fmt.Errorf("%s must be true or false", name)
+			return row
+		}
+		if name == "lockVotes" {
+			row.poll.LockVotes = parsed
+		} else {
+			row.poll.AllowGuestVotes = parsed
+		}
+	}
+	return row
+}
+
+// importExpiry accepts expiresAt as a number of days, like CreatePoll, or as
+// a date (2006-01-02) or RFC 3339 timestamp that is turned into the days from
+// the poll's start, rounded up. Empty values are left to validation.
+func importExpiry(expiresAt, startsAt string, now time.Time) (string, error) {
+	if expiresAt == "" {
+		return "", nil
+	}
+	if _, err := strconv.Atoi(expiresAt); err == nil {
+		return expiresAt, nil
+	}
+
+	expiry, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		expiry, err = time.ParseInLocation(time.DateOnly, expiresAt, now.Location())
+		if err != nil {
+			return "", errors.New("expiresAt must be a number of days, a date or an RFC 3339 timestamp")
+		}
+	}
+	start := now
+	if scheduled, err := time.Parse(time.RFC3339, startsAt); err == nil && scheduled.After(now) {
+		start = scheduled
+	}
+	if !expiry.After(start) {
+		return "", errors.New("expiresAt must be after the poll starts")
+	}
+	return strconv.Itoa(int(math.Ceil(expiry.Sub(start).Hours() / 24))), nil
+}
+
+// importReadError keeps size limit errors intact and marks the rest as
+// unreadable files.
+func importReadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+}
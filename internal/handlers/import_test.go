@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadImportCSV(t *testing.T) {
+	t.Run("Rows", func(t *testing.T) {
+		file := "title,options,tags,expiresAt,quorum,lockVotes\n" +
+			"Lunch,Pizza | Sushi,food|work,7,3,true\n" +
+			"Short row,A\n" +
+			"Dinner,Pasta|Curry,,5,many,\n"
+		rows, err := readImportCSV(strings.NewReader(file))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(rows) != 3 {
+			t.Fatalf("expected 3 rows, got %d", len(rows))
+		}
+
+		lunch := rows[0]
+		if lunch.err != nil {
+			t.Fatalf("expected the first row to parse, got %v", lunch.err)
+		}
+		if len(lunch.poll.Options) != 2 || lunch.poll.Options[1].Name != "Sushi" {
+			t.Fatalf("expected options Pizza and Sushi, got %+v", lunch.poll.Options)
+		}
+		if len(lunch.poll.Tags) != 2 || lunch.poll.ExpiresAt != "7" || !lunch.poll.LockVotes {
+			t.Fatalf("expected tags, expiry and locked votes, got %+v", lunch.poll)
+		}
+		if lunch.poll.Quorum == nil || *lunch.poll.Quorum != 3 || lunch.poll.QuorumExtensionDays != nil {
+			t.Fatalf("expected a quorum of 3 and no extension, got %v and %v", lunch.poll.Quorum, lunch.poll.QuorumExtensionDays)
+		}
+
+		if rows[1].err == nil {
+			t.Fatalf("expected the short row to fail")
+		}
+		if rows[2].field != "quorum" || rows[2].err == nil {
+			t.Fatalf("expected the quorum of the last row to fail, got %q (%v)", rows[2].field, rows[2].err)
+		}
+	})
+
+	for name, file := range map[string]string{
+		"Empty":          "",
+		"Unknown column": "title,options,color\n",
+		"Missing column": "title,description\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := readImportCSV(strings.NewReader(file)); !errors.Is(err, ErrInvalidImportFile) {
+				t.Fatalf("expected ErrInvalidImportFile, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReadImportJSON(t *testing.T) {
+	rows, err := readImportJSON(strings.NewReader(`[{"title":"Lunch","options":[{"name":"Pizza"}]},{"title":7}]`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(rows) != 2 || rows[0].err != nil || rows[0].poll.Title != "Lunch" {
+		t.Fatalf("expected the first row to parse, got %+v", rows)
+	}
+	if rows[1].err == nil {
+		t.Fatalf("expected the second row to fail")
+	}
+
+	if _, err := readImportJSON(strings.NewReader(`{"title":"Lunch"}`)); !errors.Is(err, ErrInvalidImportFile) {
+		t.Fatalf("expected ErrInvalidImportFile, got %v", err)
+	}
+}
+
+func TestImportExpiry(t *testing.T) {
+	now := time.Date(2025, 10, 6, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		expiresAt string
+		startsAt  string
+		expected  string
+		wantErr   bool
+	}{
+		{name: "Days", expiresAt: "7", expected: "7"},
+		{name: "Empty", expiresAt: "", expected: ""},
+		{name: "Date rounds up", expiresAt: "2025-10-09", expected: "3"},
+		{name: "Timestamp", expiresAt: "2025-10-08T12:00:00Z", expected: "2"},
+		{name: "From a scheduled start", expiresAt: "2025-10-20", startsAt: "2025-10-10T00:00:00Z", expected: "10"},
+		{name: "In the past", expiresAt: "2025-10-01", wantErr: true},
+		{name: "Not a date", expiresAt: "next week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := importExpiry(tt.expiresAt, tt.startsAt, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.expected {
+				t.Fatalf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
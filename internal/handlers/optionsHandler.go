@@ -1,11 +1,16 @@
 package handlers
 
 import (
-	"database/sql"
+	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
 	"github.com/GhostVox/ghostvox.io-backend/internal/config"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	t "github.com/Ghostvox/trie_hard/go"
 	"github.com/google/uuid"
 )
 
@@ -14,6 +19,7 @@ type Option struct {
 	Name      string `json:"name"`
 	PollID    string `json:"poll_id"`
 	Count     int32  `json:"count"`
+	Position  int32  `json:"position"`
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
@@ -24,35 +30,166 @@ type OptionsRequest struct {
 	Options []Option `json:"options"`
 }
 
+// OptionOrder lists every option of a poll in its new display order
+type OptionOrder struct {
+	OptionIds []string `json:"optionIds"`
+}
+
 type optionHandler struct {
-	cfg *config.APIConfig
+	cfg    *config.APIConfig
+	filter *t.Trie[string]
+}
+
+func NewOptionHandler(cfg *config.APIConfig, filter *t.Trie[string]) *optionHandler {
+	return &optionHandler{cfg: cfg, filter: filter}
+}
+
+// AddOption appends an option to a poll the caller owns.
+func (oh *optionHandler) AddOption(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+
+	name, ok := oh.parseOptionName(w, r)
+	if !ok {
+		return
+	}
+
+	option, err := AddOptionToPoll(r.Context(), oh.cfg, pollUUID, userUUID, claims.Role == "admin", name)
+	if err != nil {
+		respondWithOptionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toOption(option))
+}
+
+// RenameOption changes an option's name. Options that already have votes can
+// only be renamed by an admin.
+func (oh *optionHandler) RenameOption(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+	optionUUID, err := uuid.Parse(r.PathValue("optionId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "optionId", "Invalid option ID", err)
+		return
+	}
+
+	name, ok := oh.parseOptionName(w, r)
+	if !ok {
+		return
+	}
+
+	option, err := RenamePollOption(r.Context(), oh.cfg, pollUUID, optionUUID, userUUID, claims.Role == "admin", name)
+	if err != nil {
+		respondWithOptionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toOption(option))
 }
 
-func NewOptionHandler(cfg *config.APIConfig) *optionHandler {
-	return &optionHandler{cfg: cfg}
+// ReorderOptions sets the display order of a poll's options.
+func (oh *optionHandler) ReorderOptions(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+
+	var order OptionOrder
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid request body", err)
+		return
+	}
+	optionUUIDs, err := parseOptionIDs(Vote{OptionIds: order.OptionIds})
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "optionIds", err.Error(), err)
+		return
+	}
+
+	options, err := ReorderPollOptions(r.Context(), oh.cfg, pollUUID, userUUID, claims.Role == "admin", optionUUIDs)
+	if err != nil {
+		respondWithOptionError(w, err)
+		return
+	}
+
+	response := OptionsRequest{Options: make([]Option, len(options))}
+	for i, option := range options {
+		response.Options[i] = toOption(option)
+	}
+	respondWithJSON(w, http.StatusOK, response)
 }
 
-func (oh *optionHandler) DeleteOption(w http.ResponseWriter, r *http.Request) {
-	optionId := r.PathValue("optionId")
-	if optionId == "" {
-		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Option id is required", errors.New("Option id is required"))
+// DeleteOption removes an option from a poll. Options that already have votes
+// can only be removed by an admin, their votes are removed with them.
+func (oh *optionHandler) DeleteOption(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
 		return
 	}
-	optionUUID, err := uuid.Parse(optionId)
-	if optionUUID == uuid.Nil {
-		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid option id", errors.New("Invalid option id"))
+	optionUUID, err := uuid.Parse(r.PathValue("optionId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "optionId", "Invalid option ID", err)
 		return
 	}
 
-	err = oh.cfg.Queries.DeleteOption(r.Context(), optionUUID)
+	err = RemovePollOption(r.Context(), oh.cfg, pollUUID, optionUUID, userUUID, claims.Role == "admin")
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "Option not found", err)
-			return
-		}
-		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+		respondWithOptionError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (oh *optionHandler) parseOptionName(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req CreateOption
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid request body", err)
+		return "", false
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		respondWithError(w, http.StatusBadRequest, "name", "Option name is required", errors.New("Option name is required"))
+		return "", false
+	}
+	if !checkInputClean(name, oh.filter, w) {
+		return "", false
+	}
+	return name, true
+}
+
+func toOption(option database.Option) Option {
+	return Option{
+		ID:        option.ID.String(),
+		Name:      option.Name,
+		PollID:    option.PollID.String(),
+		Count:     option.Count,
+		Position:  option.Position,
+		CreatedAt: option.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: option.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func respondWithOptionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrOptionNotFound):
+		respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "Option not found", err)
+	case errors.Is(err, ErrOptionHasVotes):
+		respondWithError(w, http.StatusConflict, "optionId", "Option already has votes", err)
+	case errors.Is(err, ErrOptionOrder):
+		respondWithError(w, http.StatusBadRequest, "optionIds", "optionIds must list every option of the poll once", err)
+	case errors.Is(err, ErrLastOption):
+		respondWithError(w, http.StatusConflict, "optionId", "A poll needs at least one option", err)
+	case errors.Is(err, ErrPollClosed):
+		respondWithError(w, http.StatusConflict, "status", "Options of a closed poll can't be changed", err)
+	default:
+		respondWithLifecycleError(w, err)
+	}
+}
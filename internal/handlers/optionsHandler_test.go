@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/config"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestGetUnvotedOption(t *testing.T) {
+	pollID := uuid.New()
+	optionID := uuid.New()
+	optionRow := func(count int64) fakeResult {
+		return fakeResult{
+			columns: []string{"id", "name", "poll_id", "count", "created_at", "updated_at", "position"},
+			rows:    [][]driver.Value{{optionID.String(), "Tacos", pollID.String(), count, time.Now(), time.Now(), int64(0)}},
+		}
+	}
+	hasVotes := func(voted bool) fakeResult {
+		return fakeResult{columns: []string{"has_votes"}, rows: [][]driver.Value{{voted}}}
+	}
+
+	tests := []struct {
+		name    string
+		option  fakeResult
+		voted   bool
+		isAdmin bool
+		wantErr error
+	}{
+		{name: "No votes", option: optionRow(0), voted: false},
+		{name: "First preferences", option: optionRow(3), voted: true, wantErr: ErrOptionHasVotes},
+		// A ranked poll only counts first preferences, an option ranked second
+		// on every ballot has a count of 0 but still holds votes
+		{name: "Ranked below first preference", option: optionRow(0), voted: true, wantErr: ErrOptionHasVotes},
+		{name: "Admin edits a voted option", option: optionRow(3), voted: true, isAdmin: true},
+		{name: "Option of another poll", option: fakeResult{columns: optionRow(0).columns}, wantErr: ErrOptionNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t, map[string]fakeResult{
+				"GetOptionForPoll": tt.option,
+				"OptionHasVotes":   hasVotes(tt.voted),
+			})
+			option, err := getUnvotedOption(context.Background(), database.New(db), pollID, optionID, tt.isAdmin)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got: %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && option.ID != optionID {
+				t.Fatalf("expected option %s, got %s", optionID, option.ID)
+			}
+		})
+	}
+}
+
+// fakeOptionRows answers an options query with one option of pollID per id
+func fakeOptionRows(pollID uuid.UUID, ids ...uuid.UUID) fakeResult {
+	result := fakeResult{columns: []string{"id", "name", "poll_id", "count", "created_at", "updated_at", "position"}}
+	for i, id := range ids {
+		result.rows = append(result.rows, []driver.Value{id.String(), "Option", pollID.String(), int64(1), time.Now(), time.Now(), int64(i + 1)})
+	}
+	return result
+}
+
+func TestRemovePollOption(t *testing.T) {
+	pollID := uuid.New()
+	optionID := uuid.New()
+	otherID := uuid.New()
+	owner := uuid.New()
+
+	tests := []struct {
+		name      string
+		pollType  database.PollType
+		isAdmin   bool
+		options   []uuid.UUID
+		wantErr   error
+		wantExecs []string
+	}{
+		{name: "Admin removes a voted option of a ranked poll", pollType: database.PollTypeRanked, isAdmin: true, options: []uuid.UUID{optionID, otherID}, wantExecs: []string{"DeleteOption", "RerankBallots", "RecountOptions", "ClampMaxChoices"}},
+		{name: "Admin removes a voted option of a standard poll", pollType: database.PollTypeStandard, isAdmin: true, options: []uuid.UUID{optionID, otherID}, wantExecs: []string{"DeleteOption", "ClampMaxChoices"}},
+		{name: "Admin can't remove a rated option", pollType: database.PollTypeRating, isAdmin: true, options: []uuid.UUID{optionID, otherID}, wantErr: ErrOptionHasVotes},
+		{name: "Creator can't remove a voted option", pollType: database.PollTypeRanked, options: []uuid.UUID{optionID, otherID}, wantErr: ErrOptionHasVotes},
+		{name: "Last option", pollType: database.PollTypeStandard, isAdmin: true, options: []uuid.UUID{optionID}, wantErr: ErrLastOption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, execs := newRecordingFakeDB(t, map[string]fakeResult{
+				"GetPollForVote":      fakePollRow(database.Poll{ID: pollID, UserID: owner, Status: database.PollStatusActive, PollType: tt.pollType, MaxChoices: 2}),
+				"GetOptionForPoll":    fakeOptionRows(pollID, optionID),
+				"OptionHasVotes":      {columns: []string{"has_votes"}, rows: [][]driver.Value{{true}}},
+				"GetOptionsByPollIDs": fakeOptionRows(pollID, tt.options...),
+			})
+			cfg := &config.APIConfig{DB: db, Queries: database.New(db)}
+
+			err := RemovePollOption(context.Background(), cfg, pollID, optionID, owner, tt.isAdmin)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got: %v", tt.wantErr, err)
+			}
+			if !slices.Equal(*execs, tt.wantExecs) {
+				t.Fatalf("expected statements %v, got %v", tt.wantExecs, *execs)
+			}
+		})
+	}
+}
+
+func TestReorderPollOptions(t *testing.T) {
+	pollID := uuid.New()
+	owner := uuid.New()
+	first, second := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name      string
+		order     []uuid.UUID
+		matched   int64
+		wantErr   error
+		wantExecs []string
+	}{
+		{name: "Every option once", order: []uuid.UUID{second, first}, matched: 2, wantExecs: []string{"SetOptionPosition", "SetOptionPosition"}},
+		{name: "Missing an option", order: []uuid.UUID{second}, matched: 1, wantErr: ErrOptionOrder},
+		{name: "Option of another poll", order: []uuid.UUID{second, uuid.New()}, matched: 1, wantErr: ErrOptionOrder},
+		{name: "Option listed twice", order: []uuid.UUID{second, second}, matched: 1, wantErr: ErrOptionOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, execs := newRecordingFakeDB(t, map[string]fakeResult{
+				"GetPollForVote":      fakePollRow(database.Poll{ID: pollID, UserID: owner, Status: database.PollStatusActive}),
+				"GetOptionsByPollIDs": fakeOptionRows(pollID, first, second),
+				"CountOptionsInPoll":  {columns: []string{"count"}, rows: [][]driver.Value{{tt.matched}}},
+			})
+			cfg := &config.APIConfig{DB: db, Queries: database.New(db)}
+
+			_, err := ReorderPollOptions(context.Background(), cfg, pollID, owner, false, tt.order)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got: %v", tt.wantErr, err)
+			}
+			if !slices.Equal(*execs, tt.wantExecs) {
+				t.Fatalf("expected statements %v, got %v", tt.wantExecs, *execs)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 20
+	// maxPageSize caps limit on every list endpoint, larger values are clamped
+	maxPageSize = 100
+	// recentPollsPageSize is the default size of the home page feed
+	recentPollsPageSize = 10
+	// trendingWindow is how far back votes count toward a poll's trending score
+	trendingWindow = 24 * time.Hour
+)
+
+// Sort modes for the poll feeds. An empty sort keeps the default order of
+// latest expiry first.
+const (
+	sortTrending      = "trending"
+	sortEndingSoon    = "ending_soon"
+	sortMostVoted     = "most_voted"
+	sortMostCommented = "most_commented"
+	sortNewest        = "newest"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor marks the last row of a page. Comments are sorted newest first
+// on (At, ID), polls on (Key, ID) where Key is the sort key of the feed's sort
+// mode. Trending feeds keep the start of their vote window in At. The next
+// page starts at the first row after the cursor. A zero cursor starts from
+// the beginning.
+type pageCursor struct {
+	At   time.Time `json:"at,omitzero"`
+	Sort string    `json:"sort,omitempty"`
+	Key  float64   `json:"key,omitempty"`
+	ID   uuid.UUID `json:"id"`
+}
+
+// feedParams are the paging and sorting options shared by the poll feeds.
+// TrendingSince is fixed on the first page and carried in the cursor, so votes
+// leaving the window don't reshuffle the pages that follow.
+type feedParams struct {
+	Limit         int
+	Sort          string
+	Cursor        pageCursor
+	TrendingSince time.Time
+}
+
+// PollPage is one page of a poll listing
+type PollPage struct {
+	Polls      []PollResponse `json:"polls"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// CommentPage is one page of a poll's comments
+type CommentPage struct {
+	Comments   []CommentResponse `json:"comments"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// encode returns the opaque form of the cursor handed to clients.
+func (c pageCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (c pageCursor) at() sql.NullTime {
+	return sql.NullTime{Time: c.At, Valid: c.ID != uuid.Nil}
+}
+
+func (c pageCursor) key() sql.NullFloat64 {
+	return sql.NullFloat64{Float64: c.Key, Valid: c.ID != uuid.Nil}
+}
+
+func (c pageCursor) id() uuid.NullUUID {
+	return uuid.NullUUID{UUID: c.ID, Valid: c.ID != uuid.Nil}
+}
+
+func decodeCursor(value string) (pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return pageCursor{}, ErrInvalidCursor
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+		return pageCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// getPageParams reads the limit and cursor query parameters. A missing cursor
+// starts from the first page.
+func getPageParams(r *http.Request) (int, pageCursor, error) {
+	limit, err := getPageLimit(r, defaultPageSize)
+	if err != nil {
+		return 0, pageCursor{}, err
+	}
+
+	value := r.URL.Query().Get("cursor")
+	if value == "" {
+		return limit, pageCursor{}, nil
+	}
+	cursor, err := decodeCursor(value)
+	if err != nil {
+		return 0, pageCursor{}, err
+	}
+	return limit, cursor, nil
+}
+
+// getFeedParams reads the sort, limit and cursor query parameters of a poll
+// feed. A cursor is only valid for the sort mode it was issued for.
+func getFeedParams(r *http.Request) (feedParams, error) {
+	sort, err := parseSortMode(r.URL.Query().Get("sort"))
+	if err != nil {
+		return feedParams{}, err
+	}
+
+	limit, cursor, err := getPageParams(r)
+	if err != nil {
+		return feedParams{}, err
+	}
+	if cursor.ID != uuid.Nil && cursor.Sort != sort {
+		return feedParams{}, ErrInvalidCursor
+	}
+
+	feed := feedParams{Limit: limit, Sort: sort, Cursor: cursor, TrendingSince: trendingSince()}
+	if sort == sortTrending && !cursor.At.IsZero() {
+		feed.TrendingSince = cursor.At
+	}
+	return feed, nil
+}
+
+// pageEnd is the cursor of a feed page whose last poll has key and id
+func (f feedParams) pageEnd(key float64, id uuid.UUID) pageCursor {
+	cursor := pageCursor{Sort: f.Sort, Key: key, ID: id}
+	if f.Sort == sortTrending {
+		cursor.At = f.TrendingSince
+	}
+	return cursor
+}
+
+func parseSortMode(sort string) (string, error) {
+	switch sort {
+	case "", sortTrending, sortEndingSoon, sortMostVoted, sortMostCommented, sortNewest:
+		return sort, nil
+	default:
+		return "", errors.New("sort must be trending, ending_soon, most_voted, most_commented or newest")
+	}
+}
+
+// trendingSince is the start of the window whose votes make a poll trend.
+func trendingSince() time.Time {
+	return time.Now().Add(-trendingWindow)
+}
+
+// getPageLimit reads the limit query parameter, clamped to maxPageSize.
+func getPageLimit(r *http.Request, fallback int) (int, error) {
+	limitParam := r.URL.Query().Get("limit")
+	if limitParam == "" {
+		return fallback, nil
+	}
+
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid limit parameter: %w", err)
+	}
+	if limit < 1 {
+		return 0, errors.New("Invalid limit parameter: must be at least 1")
+	}
+	return min(limit, maxPageSize), nil
+}
+
+// nextCursor trims rows fetched with a limit of limit+1 back to limit and
+// returns the cursor for the following page, or "" on the last page.
+func nextCursor[T any](rows []T, limit int, key func(T) pageCursor) ([]T, string) {
+	if len(rows) <= limit {
+		return rows, ""
+	}
+	rows = rows[:limit]
+	return rows, key(rows[limit-1]).encode()
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPageCursor(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		cursor := pageCursor{At: time.Date(2025, 3, 1, 12, 30, 0, 123456000, time.UTC), ID: uuid.New()}
+		decoded, err := decodeCursor(cursor.encode())
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if !decoded.At.Equal(cursor.At) || decoded.ID != cursor.ID {
+			t.Fatalf("expected %+v, got %+v", cursor, decoded)
+		}
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		for _, value := range []string{"not base64!", "bm90IGpzb24", pageCursor{}.encode()} {
+			if _, err := decodeCursor(value); err == nil {
+				t.Fatalf("expected an error for cursor %q", value)
+			}
+		}
+	})
+
+	t.Run("Zero cursor starts from the beginning", func(t *testing.T) {
+		if (pageCursor{}).at().Valid || (pageCursor{}).id().Valid {
+			t.Fatalf("expected a zero cursor to produce NULL query params")
+		}
+	})
+}
+
+func TestGetPageLimit(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    int
+		wantErr bool
+	}{
+		{query: "", want: defaultPageSize},
+		{query: "?limit=5", want: 5},
+		{query: "?limit=5000", want: maxPageSize},
+		{query: "?limit=0", wantErr: true},
+		{query: "?limit=abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		limit, err := getPageLimit(httptest.NewRequest("GET", "/api/v1/polls/active"+tt.query, nil), defaultPageSize)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%q: expected an error", tt.query)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: expected no error, got: %v", tt.query, err)
+		}
+		if limit != tt.want {
+			t.Fatalf("%q: expected limit %d, got %d", tt.query, tt.want, limit)
+		}
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	key := func(id uuid.UUID) pageCursor { return pageCursor{ID: id} }
+
+	t.Run("More rows than the limit", func(t *testing.T) {
+		rows, next := nextCursor(ids, 2, key)
+		if len(rows) != 2 {
+			t.Fatalf("expected 2 rows, got %d", len(rows))
+		}
+		cursor, err := decodeCursor(next)
+		if err != nil || cursor.ID != ids[1] {
+			t.Fatalf("expected cursor at %s, got %+v (%v)", ids[1], cursor, err)
+		}
+	})
+
+	t.Run("Last page", func(t *testing.T) {
+		rows, next := nextCursor(ids, 3, key)
+		if len(rows) != 3 || next != "" {
+			t.Fatalf("expected every row and no cursor, got %d rows and %q", len(rows), next)
+		}
+	})
+}
+
+func TestGetFeedParams(t *testing.T) {
+	t.Run("Sort and cursor", func(t *testing.T) {
+		cursor := pageCursor{Sort: sortTrending, Key: 12, ID: uuid.New()}
+		feed, err := getFeedParams(httptest.NewRequest("GET", "/api/v1/polls/active?sort=trending&cursor="+cursor.encode(), nil))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if feed.Sort != sortTrending || feed.Cursor != cursor || feed.Limit != defaultPageSize {
+			t.Fatalf("unexpected feed params: %+v", feed)
+		}
+	})
+
+	t.Run("Trending window carried in the cursor", func(t *testing.T) {
+		since := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		cursor := pageCursor{At: since, Sort: sortTrending, Key: 12, ID: uuid.New()}
+		feed, err := getFeedParams(httptest.NewRequest("GET", "/api/v1/polls/active?sort=trending&cursor="+cursor.encode(), nil))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if !feed.TrendingSince.Equal(since) {
+			t.Fatalf("expected the window to start at %v, got %v", since, feed.TrendingSince)
+		}
+		if next := feed.pageEnd(8, uuid.New()); !next.At.Equal(since) {
+			t.Fatalf("expected the next cursor to keep the window start %v, got %v", since, next.At)
+		}
+	})
+
+	t.Run("Other sorts leave the window out of the cursor", func(t *testing.T) {
+		feed, err := getFeedParams(httptest.NewRequest("GET", "/api/v1/polls/active?sort=most_voted", nil))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if next := feed.pageEnd(8, uuid.New()); !next.At.IsZero() {
+			t.Fatalf("expected no window start, got %v", next.At)
+		}
+	})
+
+	t.Run("Unknown sort", func(t *testing.T) {
+		if _, err := getFeedParams(httptest.NewRequest("GET", "/api/v1/polls/active?sort=random", nil)); err == nil {
+			t.Fatalf("expected an error for an unknown sort")
+		}
+	})
+
+	t.Run("Cursor from another sort", func(t *testing.T) {
+		cursor := pageCursor{Sort: sortMostVoted, Key: 40, ID: uuid.New()}
+		_, err := getFeedParams(httptest.NewRequest("GET", "/api/v1/polls/active?sort=newest&cursor="+cursor.encode(), nil))
+		if err != ErrInvalidCursor {
+			t.Fatalf("expected ErrInvalidCursor, got: %v", err)
+		}
+	})
+}
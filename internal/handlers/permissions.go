@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// ErrUnknownEditor is returned when an editor list names a user ID that does
+// not exist.
+var ErrUnknownEditor = errors.New("editors names an unknown user")
+
+// maxPollEditors caps how many co-editors a poll can have
+const maxPollEditors = 20
+
+// pollPermission is what a caller wants to do to a poll. Every poll and
+// option mutation checks one through authorizePollChange.
+type pollPermission int
+
+const (
+	// permissionEdit covers a poll's details, options and lifecycle. The
+	// creator, admins and the poll's co-editors have it.
+	permissionEdit pollPermission = iota
+	// permissionManage covers deleting a poll, breaking its ties, exporting it
+	// and deciding who can see or edit it. Only the creator and admins have it.
+	permissionManage
+)
+
+// Editors is the body and response of the poll editor endpoints, a list of
+// user IDs.
+type Editors struct {
+	Editors []string `json:"editors"`
+}
+
+// authorizePollChange checks userID may change a poll owned by ownerID.
+// Co-editors are only looked up when the owner and admin checks fail, so the
+// common case costs no query.
+func authorizePollChange(ctx context.Context, q *database.Queries, pollID, ownerID, userID uuid.UUID, isAdmin bool, permission pollPermission) error {
+	if canChangePoll(ownerID, userID, isAdmin, false, permission) {
+		return nil
+	}
+	if permission == permissionManage {
+		return ErrNotPollOwner
+	}
+
+	isEditor, err := q.IsPollEditor(ctx, database.IsPollEditorParams{
+		PollID: pollID,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if !canChangePoll(ownerID, userID, isAdmin, isEditor, permission) {
+		return ErrNotPollEditor
+	}
+	return nil
+}
+
+func canChangePoll(ownerID, userID uuid.UUID, isAdmin, isEditor bool, permission pollPermission) bool {
+	if isAdmin || userID == ownerID {
+		return true
+	}
+	return permission == permissionEdit && isEditor
+}
+
+// isHiddenDraft reports whether a poll is a draft someone other than userID
+// wrote. Drafts are reported as not found to everyone but their creator.
+func isHiddenDraft(status database.PollStatus, ownerID, userID uuid.UUID) bool {
+	return status == database.PollStatusDraft && ownerID != userID
+}
+
+// authorizePollAccess loads a poll's owner and checks userID may change it,
+// for endpoints that do not lock the poll row.
+func (h *pollHandler) authorizePollAccess(ctx context.Context, pollID, userID uuid.UUID, isAdmin bool, permission pollPermission) error {
+	access, err := h.cfg.Queries.GetPollAccess(ctx, database.GetPollAccessParams{
+		ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+		PollID:   pollID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrPollNotFound
+		}
+		return err
+	}
+	if isHiddenDraft(access.Status, access.Ownerid, userID) {
+		return ErrPollNotFound
+	}
+	return authorizePollChange(ctx, h.cfg.Queries, pollID, access.Ownerid, userID, isAdmin, permission)
+}
+
+// GetEditors lists a poll's co-editors. The creator, admins and the editors
+// themselves can read it.
+func (h *pollHandler) GetEditors(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+
+	err := h.authorizePollAccess(r.Context(), pollUUID, userUUID, claims.Role == "admin", permissionEdit)
+	if err != nil {
+		respondWithLifecycleError(w, err)
+		return
+	}
+
+	editors, err := h.cfg.Queries.GetPollEditors(r.Context(), pollUUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to retrieve editors", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toEditors(editors))
+}
+
+// SetEditors replaces a poll's co-editors. Only the creator and admins can.
+func (h *pollHandler) SetEditors(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+
+	var req Editors
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid request body", err)
+		return
+	}
+	editors, err := normalizeEditors(req.Editors)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "editors", err.Error(), err)
+		return
+	}
+
+	entries, err := SetPollEditors(r.Context(), h.cfg, pollUUID, userUUID, claims.Role == "admin", editors)
+	if err != nil {
+		respondWithEditorsError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toEditors(entries))
+}
+
+// normalizeEditors checks every entry is a user ID and returns them without
+// duplicates.
+func normalizeEditors(raw []string) ([]uuid.UUID, error) {
+	if len(raw) > maxPollEditors {
+		return nil, fmt.Errorf("A poll can have at most %d editors", maxPollEditors)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(raw))
+	editors := make([]uuid.UUID, 0, len(raw))
+	for _, entry := range raw {
+		userUUID, err := uuid.Parse(strings.TrimSpace(entry))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a user ID", entry)
+		}
+		if !seen[userUUID] {
+			seen[userUUID] = true
+			editors = append(editors, userUUID)
+		}
+	}
+	return editors, nil
+}
+
+func toEditors(entries []database.PollEditor) Editors {
+	editors := Editors{Editors: make([]string, len(entries))}
+	for i, entry := range entries {
+		editors.Editors[i] = entry.UserID.String()
+	}
+	return editors
+}
+
+func respondWithEditorsError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrUnknownEditor) {
+		respondWithError(w, http.StatusBadRequest, "editors", "Editors names a user that does not exist", err)
+		return
+	}
+	respondWithLifecycleError(w, err)
+}
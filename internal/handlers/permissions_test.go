@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCanChangePoll(t *testing.T) {
+	owner := uuid.New()
+	other := uuid.New()
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		isAdmin    bool
+		isEditor   bool
+		permission pollPermission
+		want       bool
+	}{
+		{name: "Owner edits", userID: owner, permission: permissionEdit, want: true},
+		{name: "Owner manages", userID: owner, permission: permissionManage, want: true},
+		{name: "Admin manages", userID: other, isAdmin: true, permission: permissionManage, want: true},
+		{name: "Editor edits", userID: other, isEditor: true, permission: permissionEdit, want: true},
+		{name: "Editor manages", userID: other, isEditor: true, permission: permissionManage, want: false},
+		{name: "Stranger edits", userID: other, permission: permissionEdit, want: false},
+		{name: "Stranger manages", userID: other, permission: permissionManage, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canChangePoll(owner, tt.userID, tt.isAdmin, tt.isEditor, tt.permission); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeEditors(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("Duplicates dropped", func(t *testing.T) {
+		editors, err := normalizeEditors([]string{userID.String(), " " + userID.String()})
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if !reflect.DeepEqual(editors, []uuid.UUID{userID}) {
+			t.Fatalf("expected [%s], got %v", userID, editors)
+		}
+	})
+
+	t.Run("Invalid entry", func(t *testing.T) {
+		for _, entry := range []string{"alice", "alice@example.com", ""} {
+			if _, err := normalizeEditors([]string{entry}); err == nil {
+				t.Fatalf("expected an error for %q", entry)
+			}
+		}
+	})
+
+	t.Run("Too many editors", func(t *testing.T) {
+		raw := make([]string, maxPollEditors+1)
+		for i := range raw {
+			raw[i] = uuid.NewString()
+		}
+		if _, err := normalizeEditors(raw); err == nil {
+			t.Fatalf("expected an error for %d editors", len(raw))
+		}
+	})
+}
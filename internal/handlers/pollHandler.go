@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -13,33 +14,78 @@ import (
 	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
 	"github.com/GhostVox/ghostvox.io-backend/internal/config"
 	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/GhostVox/ghostvox.io-backend/internal/tally"
 	t "github.com/Ghostvox/trie_hard/go"
 	"github.com/google/uuid"
 )
 
 type poll struct {
-	Title       string         `json:"title"`
-	Description string         `json:"description"`
-	Category    string         `json:"category"`
-	ExpiresAt   string         `json:"expiresAt"`
-	Status      string         `json:"status"`
-	Options     []CreateOption `json:"options"`
+	Title               string         `json:"title"`
+	Description         string         `json:"description"`
+	Category            string         `json:"category"`
+	ExpiresAt           string         `json:"expiresAt"`
+	StartsAt            string         `json:"startsAt"`
+	Status              string         `json:"status"`
+	Type                string         `json:"type"`
+	MaxChoices          int32          `json:"maxChoices"`
+	RatingMax           int32          `json:"ratingMax"`
+	LockVotes           bool           `json:"lockVotes"`
+	AllowGuestVotes     bool           `json:"allowGuestVotes"`
+	Options             []CreateOption `json:"options"`
+	Tags                []string       `json:"tags"`
+	Visibility          string         `json:"visibility"`
+	Allowlist           []string       `json:"allowlist"`
+	ResultsVisibility   string         `json:"resultsVisibility"`
+	TieBreak            string         `json:"tieBreak"`
+	Quorum              *int32         `json:"quorum"`
+	QuorumExtensionDays *int32         `json:"quorumExtensionDays"`
+	Editors             []string       `json:"editors"`
+	// clonedFrom links a copy made by ClonePoll back to its source
+	clonedFrom uuid.NullUUID
 }
 
 type PollResponse struct {
-	ID          uuid.UUID     `json:"id"`
-	Title       string        `json:"title"`
-	Creator     string        `json:"creator"`
-	Description string        `json:"description"`
-	Status      string        `json:"status"`
-	Category    string        `json:"category"`
-	DaysLeft    int64         `json:"daysLeft"`
-	Options     []Option      `json:"options"`
-	Votes       int64         `json:"votes"`
-	Comments    int64         `json:"comments"`
-	EndedAt     time.Time     `json:"endedAt"`
-	Winner      string        `json:"winner"`
-	UserVote    uuid.NullUUID `json:"userVote,omitempty"`
+	ID                uuid.UUID        `json:"id"`
+	Title             string           `json:"title"`
+	Creator           string           `json:"creator"`
+	Description       string           `json:"description"`
+	Status            string           `json:"status"`
+	Type              string           `json:"type"`
+	Category          string           `json:"category"`
+	DaysLeft          int64            `json:"daysLeft"`
+	Options           []Option         `json:"options"`
+	Votes             int64            `json:"votes"`
+	Comments          int64            `json:"comments"`
+	EndedAt           time.Time        `json:"endedAt"`
+	Winner            string           `json:"winner"`
+	MaxChoices        int32            `json:"maxChoices"`
+	UserVote          []uuid.UUID      `json:"userVote"`
+	Runoff            *tally.Runoff    `json:"runoff,omitempty"`
+	RatingMax         int32            `json:"ratingMax,omitempty"`
+	Ratings           []RatingStats    `json:"ratings,omitempty"`
+	UserRatings       map[string]int32 `json:"userRatings,omitempty"`
+	VotesLocked       bool             `json:"votesLocked"`
+	AllowGuestVotes   bool             `json:"allowGuestVotes"`
+	StartsAt          time.Time        `json:"startsAt"`
+	Tags              []string         `json:"tags"`
+	Visibility        string           `json:"visibility"`
+	ShareToken        string           `json:"shareToken,omitempty"`
+	ResultsVisibility string           `json:"resultsVisibility"`
+	ResultsHidden     bool             `json:"resultsHidden"`
+	TieBreak          string           `json:"tieBreak"`
+	Results           *tally.Results   `json:"results,omitempty"`
+	Quorum            int32            `json:"quorum"`
+	Outcome           string           `json:"outcome,omitempty"`
+	ClonedFrom        *uuid.UUID       `json:"clonedFrom,omitempty"`
+}
+
+// RatingStats summarises the scores given to one option of a rating poll
+type RatingStats struct {
+	OptionID     string           `json:"option_id"`
+	Ratings      int64            `json:"ratings"`
+	Mean         float64          `json:"mean"`
+	Median       float64          `json:"median"`
+	Distribution map[string]int64 `json:"distribution"`
 }
 
 type pollHandler struct {
@@ -82,17 +128,16 @@ func (h *pollHandler) GetPollByID(w http.ResponseWriter, r *http.Request, claims
 		return
 	}
 
-	userUUID, err := uuid.Parse(claims.Subject)
+	// Logged-out visitors can open public polls and unlisted ones they hold
+	// the share token for
+	viewer, isAdmin := viewerFromClaims(claims)
+	access, err := authorizePollView(r.Context(), h.cfg, pollID, viewer, isAdmin, r.URL.Query().Get(shareTokenParam))
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "userUUID", "Invalid user UUID", err)
+		respondWithLifecycleError(w, err)
 		return
 	}
 
-	poll, err := h.cfg.Queries.GetPollByID(r.Context(), database.GetPollByIDParams{
-		ID:     pollID,
-		UserID: userUUID,
-	})
-
+	pollResponse, err := h.loadPollResponse(r.Context(), pollID, viewer.UUID, isAdmin)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "Poll not found", err)
@@ -103,28 +148,50 @@ func (h *pollHandler) GetPollByID(w http.ResponseWriter, r *http.Request, claims
 		}
 	}
 
-	pollResponse, err := h.mapToPollResponse(
-		poll.Pollid,
-		poll.Title,
-		poll.Description,
-		poll.Category,
-		string(poll.Status),
-		poll.Creatorfirstname.String,
-		poll.Creatorlastname.String,
-		poll.Expiresat,
-		poll.Votes,
-		poll.Comments,
-		poll.Options,
-		poll.Uservote,
-	)
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "options", "Invalid options", err)
-		return
+	// Only the creator gets the link that opens an unlisted poll
+	if access.Visibility == database.PollVisibilityUnlisted && ((viewer.Valid && access.Ownerid == viewer.UUID) || isAdmin) {
+		pollResponse.ShareToken = auth.MakeShareToken(pollID, h.cfg.GhostvoxSecretKey)
 	}
 
 	respondWithJSON(w, http.StatusOK, pollResponse)
 }
 
+// loadPollResponse builds the full response for a single poll as seen by
+// userID, including the runoff for ranked polls when results are visible.
+func (h *pollHandler) loadPollResponse(ctx context.Context, pollID, userID uuid.UUID, isAdmin bool) (PollResponse, error) {
+	poll, err := h.cfg.Queries.GetPollByID(ctx, database.GetPollByIDParams{
+		ID:     pollID,
+		UserID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		return PollResponse{}, err
+	}
+
+	pollResponse, err := h.mapToPollResponse(poll.PollDetail, poll.Uservote, poll.Userratings, userID, isAdmin)
+	if err != nil {
+		return PollResponse{}, err
+	}
+	if poll.PollDetail.ClonedFrom.Valid {
+		pollResponse.ClonedFrom = &poll.PollDetail.ClonedFrom.UUID
+	}
+
+	if poll.PollDetail.PollType == database.PollTypeRanked && !pollResponse.ResultsHidden {
+		runoff, err := tally.RankedResult(ctx, h.cfg.Queries, poll.PollDetail.ID)
+		if err != nil {
+			return PollResponse{}, err
+		}
+		pollResponse.Runoff = &runoff
+
+		leaders := runoff.Tied
+		if runoff.Winner != "" {
+			leaders = []string{runoff.Winner}
+		}
+		pollResponse.settle(leaders, tieWinner(poll.PollDetail.TieWinner))
+	}
+
+	return pollResponse, nil
+}
+
 func (h *pollHandler) CreatePoll(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
 
 	userUUID, err := uuid.Parse(claims.Subject)
@@ -140,38 +207,166 @@ func (h *pollHandler) CreatePoll(w http.ResponseWriter, r *http.Request, claims
 		return
 	}
 
+	if field, err := h.validateNewPoll(r.Context(), &newPoll); err != nil {
+		respondWithPollValidationError(w, field, err)
+		return
+	}
+
+	_, err = CreatePollWithOptions(r.Context(), h.cfg, newPoll, userUUID)
+	if errors.Is(err, ErrUnknownAllowlistUser) {
+		respondWithAllowlistError(w, err)
+		return
+	}
+	if errors.Is(err, ErrUnknownEditor) {
+		respondWithEditorsError(w, err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, struct {
+		msg string
+	}{msg: http.StatusText(http.StatusOK)})
+}
+
+// validateNewPoll applies the rules every new poll must pass, whether it is
+// created directly, imported or published from a draft, and fills in the
+// defaults. It returns the offending field with the error, or an empty field
+// when the check itself failed.
+func (h *pollHandler) validateNewPoll(ctx context.Context, newPoll *poll) (string, error) {
 	// Check that title and description are present
 	titlePresent, descriptionPresent := CheckPollTitleAndDescription(newPoll.Title, newPoll.Description)
 	if !titlePresent {
-		respondWithError(w, http.StatusBadRequest, "title", "Title is required", nil)
-		return
+		return "title", errors.New("title is required")
 	}
 	if !descriptionPresent {
 		newPoll.Description = "No description provided."
 	}
 
-	// Check for profanity in title, description, and options
-	if !checkInputClean(newPoll.Title, h.filter, w) {
-		return
+	if field, err := h.validatePollSettings(ctx, newPoll); err != nil {
+		return field, err
 	}
-	if !checkInputClean(newPoll.Description, h.filter, w) {
-		return
+	// The status follows the schedule, drafts are saved through SaveDraft
+	newPoll.Status = ""
+	if days, err := strconv.Atoi(newPoll.ExpiresAt); err != nil || days < 1 {
+		return "expiresAt", errors.New("expiresAt must be a whole number of days, at least 1")
+	}
+
+	switch database.PollType(newPoll.Type) {
+	case "":
+		newPoll.Type = string(database.PollTypeStandard)
+	case database.PollTypeStandard:
+	case database.PollTypeRanked:
+		if len(newPoll.Options) < 2 {
+			return "options", errors.New("ranked polls need at least two options")
+		}
+		// Voters may rank every option unless the creator limits it
+		if newPoll.MaxChoices == 0 {
+			newPoll.MaxChoices = int32(len(newPoll.Options))
+		}
+	case database.PollTypeRating:
+		if newPoll.RatingMax < 2 || newPoll.RatingMax > 10 {
+			return "ratingMax", errors.New("ratingMax must be between 2 and 10")
+		}
+		// Every option gets a score on a rating poll
+		newPoll.MaxChoices = int32(len(newPoll.Options))
+	default:
+		return "type", errors.New("invalid poll type")
+	}
+
+	if newPoll.RatingMax == 0 {
+		newPoll.RatingMax = 5
+	}
+
+	// Single choice polls are the default
+	if newPoll.MaxChoices == 0 {
+		newPoll.MaxChoices = 1
+	}
+	if newPoll.MaxChoices < 1 || int(newPoll.MaxChoices) > len(newPoll.Options) {
+		return "maxChoices", errors.New("maxChoices must be between 1 and the number of options")
+	}
+
+	return "", nil
+}
+
+// validatePollSettings checks the parts of a poll that drafts and new polls
+// share, everything but the required fields, and fills in their defaults.
+func (h *pollHandler) validatePollSettings(ctx context.Context, newPoll *poll) (string, error) {
+	var err error
+	// Check for profanity in title, description, and options
+	if !isInputClean(newPoll.Title, h.filter) || !isInputClean(newPoll.Description, h.filter) {
+		return "profanity", ErrProfanity
 	}
 	for _, option := range newPoll.Options {
-		if !checkInputClean(option.Name, h.filter, w) {
-			return
+		if !isInputClean(option.Name, h.filter) {
+			return "profanity", ErrProfanity
 		}
 	}
 
-	err = CreatePollWithOptions(r.Context(), h.cfg, newPoll, userUUID)
+	newPoll.Tags, err = normalizeTags(newPoll.Tags)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
-		return
+		return "tags", err
+	}
+	if !areTagsClean(newPoll.Tags, h.filter) {
+		return "profanity", ErrProfanity
 	}
 
-	respondWithJSON(w, http.StatusCreated, struct {
-		msg string
-	}{msg: http.StatusText(http.StatusOK)})
+	visibility, err := parseVisibility(newPoll.Visibility)
+	if err != nil {
+		return "visibility", err
+	}
+	newPoll.Visibility = string(visibility)
+	resultsVisibility, err := parseResultsVisibility(newPoll.ResultsVisibility)
+	if err != nil {
+		return "resultsVisibility", err
+	}
+	newPoll.ResultsVisibility = string(resultsVisibility)
+	tieBreak, err := parseTieBreak(newPoll.TieBreak)
+	if err != nil {
+		return "tieBreak", err
+	}
+	newPoll.TieBreak = string(tieBreak)
+	if field, err := validateQuorum(newPoll.Quorum, newPoll.QuorumExtensionDays); err != nil {
+		return field, err
+	}
+	newPoll.Allowlist, err = normalizeAllowlist(newPoll.Allowlist)
+	if err != nil {
+		return "allowlist", err
+	}
+	if _, err := normalizeEditors(newPoll.Editors); err != nil {
+		return "editors", err
+	}
+
+	category, err := resolveCategory(ctx, h.cfg.Queries, newPoll.Category)
+	if errors.Is(err, ErrUnknownCategory) {
+		return "category", err
+	}
+	if err != nil {
+		return "", err
+	}
+	newPoll.Category = category.Slug
+
+	if newPoll.StartsAt != "" {
+		if _, err := time.Parse(time.RFC3339, newPoll.StartsAt); err != nil {
+			return "startsAt", errors.New("startsAt must be an RFC 3339 timestamp")
+		}
+	}
+	return "", nil
+}
+
+func respondWithPollValidationError(w http.ResponseWriter, field string, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownCategory):
+		respondWithCategoryError(w, err)
+	case errors.Is(err, ErrProfanity):
+		respondWithError(w, http.StatusBadRequest, field, "Input contains profanity", err)
+	case field == "":
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+	default:
+		respondWithError(w, http.StatusBadRequest, field, err.Error(), err)
+	}
 }
 
 func (h *pollHandler) UpdatePoll(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
@@ -208,40 +403,205 @@ func (h *pollHandler) UpdatePoll(w http.ResponseWriter, r *http.Request, claims
 		return
 	}
 
+	// Tags are only replaced when the body includes them
+	if newPoll.Tags != nil {
+		newPoll.Tags, err = normalizeTags(newPoll.Tags)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "tags", err.Error(), err)
+			return
+		}
+		if !checkTagsClean(newPoll.Tags, h.filter, w) {
+			return
+		}
+	}
+
+	// An empty visibility keeps the poll's current one
+	if newPoll.Visibility != "" {
+		if _, err := parseVisibility(newPoll.Visibility); err != nil {
+			respondWithError(w, http.StatusBadRequest, "visibility", err.Error(), err)
+			return
+		}
+	}
+	if newPoll.ResultsVisibility != "" {
+		if _, err := parseResultsVisibility(newPoll.ResultsVisibility); err != nil {
+			respondWithError(w, http.StatusBadRequest, "resultsVisibility", err.Error(), err)
+			return
+		}
+	}
+	if newPoll.TieBreak != "" {
+		if _, err := parseTieBreak(newPoll.TieBreak); err != nil {
+			respondWithError(w, http.StatusBadRequest, "tieBreak", err.Error(), err)
+			return
+		}
+	}
+	if field, err := validateQuorum(newPoll.Quorum, newPoll.QuorumExtensionDays); err != nil {
+		respondWithError(w, http.StatusBadRequest, field, err.Error(), err)
+		return
+	}
+
+	// An empty category keeps the poll's current one
+	if newPoll.Category != "" {
+		category, err := resolveCategory(r.Context(), h.cfg.Queries, newPoll.Category)
+		if err != nil {
+			respondWithCategoryError(w, err)
+			return
+		}
+		newPoll.Category = category.Slug
+	}
+
 	pollUUID, err := uuid.Parse(pollId)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid poll id in pathvalue", err)
 		return
 	}
 
-	exp, err := strconv.Atoi(newPoll.ExpiresAt)
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid expiresAt", "Invalid expiresAt", err)
-		return
+	// expiresAt is optional on edits, left out the deadline is kept
+	days := 0
+	if newPoll.ExpiresAt != "" {
+		days, err = strconv.Atoi(newPoll.ExpiresAt)
+		if err != nil || days < 1 || days > maxLifecycleDays {
+			respondWithError(w, http.StatusBadRequest, "expiresAt", "expiresAt must be between 1 and 30 days", err)
+			return
+		}
 	}
-	expiresAt := time.Now().Add(time.Duration(exp))
-	pollRecord, err := h.cfg.Queries.UpdatePoll(r.Context(), database.UpdatePollParams{
+	pollRecord, err := UpdatePollDetails(r.Context(), h.cfg, userUUID, claims.Role == "admin", database.UpdatePollParams{
 		ID:          pollUUID,
-		UserID:      userUUID,
 		Description: newPoll.Description,
 		Title:       newPoll.Title,
-		ExpiresAt:   expiresAt,
-		Status:      database.PollStatus(newPoll.Status),
-	})
-
+		Category:    newPoll.Category,
+		Column6:     newPoll.Visibility,
+		Column7:     newPoll.ResultsVisibility,
+		Column8:     newPoll.TieBreak,
+		Column9:     quorumUpdate(newPoll.Quorum),
+		Column10:    quorumUpdate(newPoll.QuorumExtensionDays),
+	}, days, newPoll.Tags)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "Poll not found", err)
-			return
-		} else {
-			respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
-			return
-		}
+		respondWithLifecycleError(w, err)
+		return
 	}
 
 	respondWithJSON(w, http.StatusOK, pollRecord)
 }
 
+// lifecycleRequest is the body of the reopen and extend actions
+type lifecycleRequest struct {
+	Days int `json:"days"`
+}
+
+// maxLifecycleDays caps how far a reopen or extend can push the deadline
+const maxLifecycleDays = 30
+
+func (h *pollHandler) ClosePoll(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+
+	_, err := ClosePoll(r.Context(), h.cfg, pollUUID, userUUID, claims.Role == "admin")
+	if err != nil {
+		respondWithLifecycleError(w, err)
+		return
+	}
+
+	h.respondWithUpdatedPoll(w, r, pollUUID, userUUID, claims.Role == "admin")
+}
+
+func (h *pollHandler) ReopenPoll(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+	days, ok := parseLifecycleDays(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := ReopenPoll(r.Context(), h.cfg, pollUUID, userUUID, claims.Role == "admin", days)
+	if err != nil {
+		respondWithLifecycleError(w, err)
+		return
+	}
+
+	h.respondWithUpdatedPoll(w, r, pollUUID, userUUID, claims.Role == "admin")
+}
+
+func (h *pollHandler) ExtendPoll(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+	days, ok := parseLifecycleDays(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := ExtendPoll(r.Context(), h.cfg, pollUUID, userUUID, claims.Role == "admin", days)
+	if err != nil {
+		respondWithLifecycleError(w, err)
+		return
+	}
+
+	h.respondWithUpdatedPoll(w, r, pollUUID, userUUID, claims.Role == "admin")
+}
+
+func (h *pollHandler) respondWithUpdatedPoll(w http.ResponseWriter, r *http.Request, pollID, userID uuid.UUID, isAdmin bool) {
+	pollResponse, err := h.loadPollResponse(r.Context(), pollID, userID, isAdmin)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, pollResponse)
+}
+
+func parseLifecycleIDs(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) (uuid.UUID, uuid.UUID, bool) {
+	pollUUID, err := uuid.Parse(r.PathValue("pollId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "pollId", "Invalid poll ID", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+	userUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "accessToken", "Invalid access token", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return pollUUID, userUUID, true
+}
+
+func parseLifecycleDays(w http.ResponseWriter, r *http.Request) (int, bool) {
+	var req lifecycleRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid request body", err)
+		return 0, false
+	}
+	if req.Days < 1 || req.Days > maxLifecycleDays {
+		respondWithError(w, http.StatusBadRequest, "days", "days must be between 1 and 30", nil)
+		return 0, false
+	}
+	return req.Days, true
+}
+
+func respondWithLifecycleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrPollNotFound):
+		respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "Poll not found", err)
+	case errors.Is(err, ErrNotPollOwner):
+		respondWithError(w, http.StatusForbidden, http.StatusText(http.StatusForbidden), "Only the poll's creator can do this", err)
+	case errors.Is(err, ErrNotPollEditor):
+		respondWithError(w, http.StatusForbidden, http.StatusText(http.StatusForbidden), "Only the poll's creator or its editors can do this", err)
+	case errors.Is(err, ErrInvalidTransition):
+		respondWithError(w, http.StatusConflict, "status", err.Error(), err)
+	case errors.Is(err, ErrReopenWindowPassed):
+		respondWithError(w, http.StatusConflict, "status", "Poll closed too long ago to reopen", err)
+	case errors.Is(err, ErrPollIsDraft):
+		respondWithError(w, http.StatusConflict, "status", "Drafts are saved with PUT /polls/{pollId}/draft until they are published", err)
+	case errors.Is(err, ErrPollNotDraft):
+		respondWithError(w, http.StatusConflict, "status", "Poll is not a draft", err)
+	default:
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+	}
+}
+
 func (h *pollHandler) DeletePoll(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
 	pollId := r.PathValue("pollId")
 	if pollId == "" {
@@ -255,34 +615,43 @@ func (h *pollHandler) DeletePoll(w http.ResponseWriter, r *http.Request, claims
 		return
 	}
 
-	if claims.Role != "admin" {
-		respondWithError(w, http.StatusForbidden, http.StatusText(http.StatusForbidden), "Forbidden", nil)
+	userUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "accessToken", "Invalid access token", err)
+		return
+	}
+	err = h.authorizePollAccess(r.Context(), pollUUID, userUUID, claims.Role == "admin", permissionManage)
+	if err != nil {
+		respondWithLifecycleError(w, err)
 		return
 	}
 
-	err = h.cfg.Queries.DeletePoll(r.Context(), pollUUID)
+	// The poll goes to the trash, cron purges it after the retention period
+	deleted, err := h.cfg.Queries.DeletePoll(r.Context(), pollUUID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "Poll not found", err)
-			return
-		} else {
-			respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
-			return
-		}
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "Poll not found", nil)
+		return
 	}
 	respondWithJSON(w, http.StatusNoContent, nil)
 }
 
 func (h *pollHandler) GetAllActivePolls(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
-	limit, offset, err := getLimitAndOffset(r)
+	feed, err := getFeedParams(r)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid limit or offset", err)
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid sort, limit or cursor", err)
 		return
 	}
 
-	category := r.URL.Query().Get("category")
-	if category == "" {
-		category = "%%"
+	category := parseCategoryFilter(r.URL.Query().Get("category"))
+
+	tags, matchAllTags, err := parseTagFilter(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "tags", err.Error(), err)
+		return
 	}
 
 	userUUID, err := uuid.Parse(claims.Subject)
@@ -292,38 +661,34 @@ func (h *pollHandler) GetAllActivePolls(w http.ResponseWriter, r *http.Request,
 	}
 
 	polls, err := h.cfg.Queries.GetAllPollsByStatusList(r.Context(), database.GetAllPollsByStatusListParams{
-		Limit:    int32(limit),
-		Offset:   int32(offset),
-		Status:   database.PollStatus(database.PollStatusActive),
-		Category: category,
-		UserID:   userUUID,
+		Sort:          feed.Sort,
+		TrendingSince: feed.TrendingSince,
+		Status:        database.PollStatus(database.PollStatusActive),
+		Category:      category,
+		Tags:          tags,
+		MatchAllTags:  matchAllTags,
+		UserID:        uuid.NullUUID{UUID: userUUID, Valid: true},
+		CursorKey:     feed.Cursor.key(),
+		CursorID:      feed.Cursor.id(),
+		PageSize:      int32(feed.Limit + 1),
 	})
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			respondWithJSON(w, http.StatusOK, []PollResponse{})
+			respondWithJSON(w, http.StatusOK, PollPage{Polls: []PollResponse{}})
 			return
 		}
 		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
 		return
 	}
+	polls, next := nextCursor(polls, feed.Limit, func(poll database.GetAllPollsByStatusListRow) pageCursor {
+		return feed.pageEnd(poll.Sortkey, poll.PollDetail.ID)
+	})
+
 	pollsResp := make([]PollResponse, len(polls))
 	for i, poll := range polls {
 
-		p, err := h.mapToPollResponse(
-			poll.Pollid,
-			poll.Title,
-			poll.Description,
-			poll.Category,
-			string(poll.Status),
-			poll.Creatorfirstname,
-			poll.Creatorlastname.String,
-			poll.Expiresat,
-			poll.Votes,
-			poll.Comments,
-			poll.Options,
-			poll.Uservote,
-		)
+		p, err := h.mapToPollResponse(poll.PollDetail, poll.Uservote, poll.Userratings, userUUID, claims.Role == "admin")
 		if err != nil {
 			respondWithError(w, http.StatusBadRequest, "options", "Invalid options", err)
 			return
@@ -331,21 +696,24 @@ func (h *pollHandler) GetAllActivePolls(w http.ResponseWriter, r *http.Request,
 		pollsResp[i] = p
 	}
 
-	respondWithJSON(w, http.StatusOK, pollsResp)
+	respondWithJSON(w, http.StatusOK, PollPage{Polls: pollsResp, NextCursor: next})
 
 }
 
 func (h *pollHandler) GetAllFinishedPolls(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
 
-	limit, offset, err := getLimitAndOffset(r)
+	feed, err := getFeedParams(r)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid limit or offset", err)
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid sort, limit or cursor", err)
 		return
 	}
 
-	category := r.URL.Query().Get("category")
-	if category == "" {
-		category = "%%"
+	category := parseCategoryFilter(r.URL.Query().Get("category"))
+
+	tags, matchAllTags, err := parseTagFilter(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "tags", err.Error(), err)
+		return
 	}
 
 	userUUID, err := uuid.Parse(claims.Subject)
@@ -355,37 +723,32 @@ func (h *pollHandler) GetAllFinishedPolls(w http.ResponseWriter, r *http.Request
 	}
 
 	polls, err := h.cfg.Queries.GetAllPollsByStatusList(r.Context(), database.GetAllPollsByStatusListParams{
-		Limit:    int32(limit),
-		Offset:   int32(offset),
-		Status:   database.PollStatus(database.PollStatusArchived),
-		Category: category,
-		UserID:   userUUID,
+		Sort:          feed.Sort,
+		TrendingSince: feed.TrendingSince,
+		Status:        database.PollStatus(database.PollStatusArchived),
+		Category:      category,
+		Tags:          tags,
+		MatchAllTags:  matchAllTags,
+		UserID:        uuid.NullUUID{UUID: userUUID, Valid: true},
+		CursorKey:     feed.Cursor.key(),
+		CursorID:      feed.Cursor.id(),
+		PageSize:      int32(feed.Limit + 1),
 	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			respondWithJSON(w, http.StatusOK, []PollResponse{})
+			respondWithJSON(w, http.StatusOK, PollPage{Polls: []PollResponse{}})
 			return
 		}
 		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
 		return
 	}
+	polls, next := nextCursor(polls, feed.Limit, func(poll database.GetAllPollsByStatusListRow) pageCursor {
+		return feed.pageEnd(poll.Sortkey, poll.PollDetail.ID)
+	})
 
 	pollsResp := make([]PollResponse, len(polls))
 	for i, poll := range polls {
-		p, err := h.mapToPollResponse(
-			poll.Pollid,
-			poll.Title,
-			poll.Description,
-			poll.Category,
-			string(poll.Status),
-			poll.Creatorfirstname,
-			poll.Creatorlastname.String,
-			poll.Expiresat,
-			poll.Votes,
-			poll.Comments,
-			poll.Options,
-			poll.Uservote,
-		)
+		p, err := h.mapToPollResponse(poll.PollDetail, poll.Uservote, poll.Userratings, userUUID, claims.Role == "admin")
 		if err != nil {
 			respondWithError(w, http.StatusBadRequest, "options", "Invalid options", err)
 			return
@@ -393,11 +756,90 @@ func (h *pollHandler) GetAllFinishedPolls(w http.ResponseWriter, r *http.Request
 		pollsResp[i] = p
 	}
 
-	respondWithJSON(w, http.StatusOK, pollsResp)
+	respondWithJSON(w, http.StatusOK, PollPage{Polls: pollsResp, NextCursor: next})
 
 }
 
-func (h *pollHandler) GetUsersPolls(w http.ResponseWriter, r *http.Request) {
+// SearchResult is a poll matching a search, with the matching text highlighted
+type SearchResult struct {
+	PollResponse
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// maxSearchQueryLength keeps search queries to a sensible size
+const maxSearchQueryLength = 200
+
+// SearchPolls finds polls whose title, description or option names match q.
+// Results can be narrowed by category and status like the feed endpoints.
+func (h *pollHandler) SearchPolls(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		respondWithError(w, http.StatusBadRequest, "q", "Search query is required", nil)
+		return
+	}
+	if len(query) > maxSearchQueryLength {
+		respondWithError(w, http.StatusBadRequest, "q", "Search query is too long", nil)
+		return
+	}
+
+	limit, offset, err := getLimitAndOffset(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid limit or offset", err)
+		return
+	}
+
+	category := parseCategoryFilter(r.URL.Query().Get("category"))
+
+	tags, matchAllTags, err := parseTagFilter(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "tags", err.Error(), err)
+		return
+	}
+
+	status, err := parseStatusFilter(r.URL.Query().Get("status"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "status", err.Error(), err)
+		return
+	}
+
+	userUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid user UUID", err)
+		return
+	}
+
+	polls, err := h.cfg.Queries.SearchPolls(r.Context(), database.SearchPollsParams{
+		Query:        query,
+		UserID:       uuid.NullUUID{UUID: userUUID, Valid: true},
+		Category:     category,
+		Tags:         tags,
+		MatchAllTags: matchAllTags,
+		Status:       status,
+		PageSize:     int32(limit),
+		PageOffset:   int32(offset),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+		return
+	}
+
+	results := make([]SearchResult, len(polls))
+	for i, poll := range polls {
+		p, err := h.mapToPollResponse(poll.PollDetail, poll.Uservote, poll.Userratings, userUUID, claims.Role == "admin")
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "options", "Invalid options", err)
+			return
+		}
+		results[i] = SearchResult{PollResponse: p, Rank: poll.Rank, Snippet: poll.Snippet}
+	}
+
+	respondWithJSON(w, http.StatusOK, results)
+}
+
+// GetUsersPolls lists the polls a user created. Logged-out visitors and other
+// users only see the public polls and private polls they are allowed on.
+func (h *pollHandler) GetUsersPolls(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
 	userIDString := r.PathValue("userId")
 	userId, err := uuid.Parse(userIDString)
 	if err != nil {
@@ -405,58 +847,61 @@ func (h *pollHandler) GetUsersPolls(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	limit, offset, err := getLimitAndOffset(r)
+	feed, err := getFeedParams(r)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid limit or offset", err)
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid sort, limit or cursor", err)
 		return
 	}
 
-	category := r.URL.Query().Get("category")
-	if category == "" {
-		category = "%%"
+	category := parseCategoryFilter(r.URL.Query().Get("category"))
+
+	tags, matchAllTags, err := parseTagFilter(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "tags", err.Error(), err)
+		return
 	}
 
+	viewer, isAdmin := viewerFromClaims(claims)
+
 	userPolls, err := h.cfg.Queries.GetPollsByUser(r.Context(), database.GetPollsByUserParams{
-		UserID:   userId,
-		Limit:    int32(limit),
-		Offset:   int32(offset),
-		Category: category,
+		Sort:          feed.Sort,
+		TrendingSince: feed.TrendingSince,
+		UserID:        userId,
+		Category:      category,
+		ViewerID:      viewer,
+		Tags:          tags,
+		MatchAllTags:  matchAllTags,
+		CursorKey:     feed.Cursor.key(),
+		CursorID:      feed.Cursor.id(),
+		PageSize:      int32(feed.Limit + 1),
 	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			respondWithJSON(w, http.StatusOK, []PollResponse{})
+			respondWithJSON(w, http.StatusOK, PollPage{Polls: []PollResponse{}})
 			return
 		}
 		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to retrieve user polls", err)
 		return
 	}
+	userPolls, next := nextCursor(userPolls, feed.Limit, func(poll database.GetPollsByUserRow) pageCursor {
+		return feed.pageEnd(poll.Sortkey, poll.PollDetail.ID)
+	})
 
 	pollsResp := make([]PollResponse, len(userPolls))
 	for i, poll := range userPolls {
-		p, err := h.mapToPollResponse(
-			poll.Pollid,
-			poll.Title,
-			poll.Description,
-			poll.Category,
-			string(poll.Status),
-			poll.Creatorfirstname,
-			poll.Creatorlastname.String,
-			poll.Expiresat,
-			poll.Votes,
-			poll.Comments,
-			poll.Options,
-			poll.Uservote,
-		)
+		p, err := h.mapToPollResponse(poll.PollDetail, poll.Uservote, poll.Userratings, viewer.UUID, isAdmin)
 		if err != nil {
 			respondWithError(w, http.StatusBadRequest, "options", "Invalid options", err)
 			return
 		}
 		pollsResp[i] = p
 	}
-	respondWithJSON(w, http.StatusOK, pollsResp)
+	respondWithJSON(w, http.StatusOK, PollPage{Polls: pollsResp, NextCursor: next})
 
 }
 
+// GetRecentPolls is the home page feed. It takes the same sort modes as the
+// other poll feeds and returns up to limit polls, 10 by default.
 func (h *pollHandler) GetRecentPolls(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
 
 	userID, err := uuid.Parse(claims.Subject)
@@ -465,7 +910,30 @@ func (h *pollHandler) GetRecentPolls(w http.ResponseWriter, r *http.Request, cla
 		return
 	}
 
-	polls, err := h.cfg.Queries.GetRecentPolls(r.Context(), userID)
+	sort, err := parseSortMode(r.URL.Query().Get("sort"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "sort", err.Error(), err)
+		return
+	}
+	limit, err := getPageLimit(r, recentPollsPageSize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "limit", "Invalid limit", err)
+		return
+	}
+	tags, matchAllTags, err := parseTagFilter(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "tags", err.Error(), err)
+		return
+	}
+
+	polls, err := h.cfg.Queries.GetRecentPolls(r.Context(), database.GetRecentPollsParams{
+		Sort:          sort,
+		TrendingSince: trendingSince(),
+		Tags:          tags,
+		MatchAllTags:  matchAllTags,
+		UserID:        uuid.NullUUID{UUID: userID, Valid: true},
+		PageSize:      int32(limit),
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			respondWithJSON(w, http.StatusOK, []PollResponse{})
@@ -477,20 +945,7 @@ func (h *pollHandler) GetRecentPolls(w http.ResponseWriter, r *http.Request, cla
 
 	pollsResp := make([]PollResponse, len(polls))
 	for i, poll := range polls {
-		p, err := h.mapToPollResponse(
-			poll.Pollid,
-			poll.Title,
-			poll.Description,
-			poll.Category,
-			string(poll.Status),
-			poll.Creatorfirstname,
-			poll.Creatorlastname.String,
-			poll.Expiresat,
-			poll.Votes,
-			poll.Comments,
-			poll.Options,
-			poll.Uservote,
-		)
+		p, err := h.mapToPollResponse(poll.PollDetail, poll.Uservote, poll.Userratings, userID, claims.Role == "admin")
 		if err != nil {
 			respondWithError(w, http.StatusBadRequest, "options", "Invalid options", err)
 			return
@@ -502,40 +957,136 @@ func (h *pollHandler) GetRecentPolls(w http.ResponseWriter, r *http.Request, cla
 	respondWithJSON(w, http.StatusOK, pollsResp)
 }
 
-// Create a helper to centralize the conversion logic
-func (h *pollHandler) mapToPollResponse(
-	pollID uuid.UUID,
-	title, description, category, status string,
-	creatorFirst, creatorLast string,
-	expiresAt time.Time,
-	votes, comments int64,
-	optionsJSON []byte,
-	userVote uuid.NullUUID,
-) (PollResponse, error) {
+// pollRow is a poll as every poll query returns it, the details from the
+// poll_details view plus the viewer's own ballot.
+type pollRow struct {
+	database.PollDetail
+	UserVote    []uuid.UUID
+	UserRatings map[string]int32
+}
+
+// Create a helper to centralize the conversion logic. Counts and the winner
+// are left out when viewer may not see the results yet.
+func (h *pollHandler) mapToPollResponse(detail database.PollDetail, userVote []uuid.UUID, userRatings json.RawMessage, viewer uuid.UUID, isAdmin bool) (PollResponse, error) {
+	row := pollRow{PollDetail: detail, UserVote: userVote}
+	if err := json.Unmarshal(userRatings, &row.UserRatings); err != nil {
+		return PollResponse{}, err
+	}
+
 	var options []Option
-	if err := json.Unmarshal(optionsJSON, &options); err != nil {
+	if err := json.Unmarshal(row.Options, &options); err != nil {
 		return PollResponse{}, err
 	}
 
-	return PollResponse{
-		ID:          pollID,
-		Title:       title,
-		Creator:     creatorFirst + " " + creatorLast,
-		Description: description,
-		Status:      status,
-		Category:    category,
-		Options:     options,
-		DaysLeft:    int64(time.Until(expiresAt).Hours() / 24),
-		Votes:       votes,
-		Comments:    comments,
-		EndedAt:     expiresAt,
-		Winner:      getWinner(options),
-		UserVote:    userVote,
-	}, nil
+	response := PollResponse{
+		ID:                row.ID,
+		Title:             row.Title,
+		Creator:           row.CreatorFirstName + " " + row.CreatorLastName.String,
+		Description:       row.Description,
+		Status:            string(row.Status),
+		Type:              string(row.PollType),
+		Category:          row.Category,
+		Options:           options,
+		DaysLeft:          int64(time.Until(row.ExpiresAt).Hours() / 24),
+		Votes:             row.Votes,
+		Comments:          row.Comments,
+		EndedAt:           row.ExpiresAt,
+		MaxChoices:        row.MaxChoices,
+		UserVote:          row.UserVote,
+		UserRatings:       row.UserRatings,
+		VotesLocked:       row.VotesLocked,
+		AllowGuestVotes:   row.AllowGuestVotes,
+		StartsAt:          row.StartsAt,
+		Tags:              row.Tags,
+		Visibility:        string(row.Visibility),
+		ResultsVisibility: string(row.ResultsVisibility),
+		TieBreak:          string(row.TieBreak),
+		Quorum:            row.Quorum,
+	}
+	if row.Outcome.Valid {
+		response.Outcome = string(row.Outcome.PollOutcome)
+	}
+
+	if !resultsVisible(row, viewer, isAdmin) {
+		for i := range response.Options {
+			response.Options[i].Count = 0
+		}
+		response.Votes = 0
+		response.ResultsHidden = true
+		response.RatingMax = row.RatingMax
+		return response, nil
+	}
+
+	var reached map[string]float64
+	if err := json.Unmarshal(row.ReachedAt, &reached); err != nil {
+		return PollResponse{}, err
+	}
+	counts := make([]tally.Count, len(options))
+	for i, option := range options {
+		counts[i] = tally.Count{OptionID: option.ID, Votes: int64(option.Count), ReachedAt: reached[option.ID]}
+	}
+
+	var results tally.Results
+	switch row.PollType {
+	case database.PollTypeRanked:
+		results = tally.Summarize(counts, row.Voters, false, row.TieBreak, "")
+	case database.PollTypeRating:
+		if err := json.Unmarshal(row.RatingStats, &response.Ratings); err != nil {
+			return PollResponse{}, err
+		}
+		response.RatingMax = row.RatingMax
+		// Every voter rates every option, so any option's count is the number of voters
+		for _, option := range options {
+			response.Votes = max(response.Votes, int64(option.Count))
+		}
+		means := make(map[string]float64, len(response.Ratings))
+		for _, stat := range response.Ratings {
+			means[stat.OptionID] = stat.Mean
+		}
+		for i := range counts {
+			counts[i].Score = means[counts[i].OptionID]
+		}
+		results = tally.Summarize(counts, response.Votes, true, row.TieBreak, tieWinner(row.TieWinner))
+	default:
+		results = tally.Summarize(counts, row.Voters, false, row.TieBreak, tieWinner(row.TieWinner))
+	}
+	response.Results = &results
+
+	// Ranked polls are decided by the runoff, not by first preference counts.
+	// Feeds only know the frozen winner, loadPollResponse settles the rest.
+	leaders := results.Leaders
+	if row.PollType == database.PollTypeRanked {
+		leaders = nil
+		if row.FinalWinner.Valid {
+			leaders = []string{row.FinalWinner.UUID.String()}
+		}
+	}
+	response.settle(leaders, tieWinner(row.TieWinner))
+
+	return response, nil
+}
+
+// settle records the leaders and winner of a poll whose results are visible.
+// A poll that closed short of its quorum has no winner.
+func (p *PollResponse) settle(leaders []string, pick string) {
+	p.Results.Settle(leaders, pick)
+	if p.Outcome == string(database.PollOutcomeNoQuorum) {
+		p.Results.Winner = ""
+	}
+	p.Winner = p.Results.Winner
 }
 
 // Helper function to check for profanity in input
 func checkInputClean(input string, filter *t.Trie[string], w http.ResponseWriter) bool {
+	if !isInputClean(input, filter) {
+		respondWithError(w, http.StatusBadRequest, "profanity", "Input contains profanity", nil)
+		return false
+	}
+	return true
+}
+
+// isInputClean reports whether no word of input is in the profanity filter.
+func isInputClean(input string, filter *t.Trie[string]) bool {
 	words := strings.Fields(input)
 	for _, word := range words {
 		cleanedWord := strings.TrimFunc(strings.ToLower(word), func(r rune) bool {
@@ -543,7 +1094,6 @@ func checkInputClean(input string, filter *t.Trie[string], w http.ResponseWriter
 		})
 
 		if _, ok := filter.Get(&cleanedWord); ok {
-			respondWithError(w, http.StatusBadRequest, "profanity", "Input contains profanity", nil)
 			return false
 		}
 	}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/GhostVox/ghostvox.io-backend/internal/config"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestSearchPollsValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Missing query", query: ""},
+		{name: "Blank query", query: "q=%20%20"},
+		{name: "Query too long", query: "q=" + strings.Repeat("a", maxSearchQueryLength+1)},
+		{name: "Unknown status", query: "q=tacos&status=closed"},
+		{name: "Unknown tag match", query: "q=tacos&tagMatch=some"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No query is registered, reaching the database would answer 500
+			db := newFakeDB(t, nil)
+			h := &pollHandler{cfg: &config.APIConfig{DB: db, Queries: database.New(db)}}
+
+			claims := &auth.CustomClaims{}
+			claims.Subject = uuid.NewString()
+			rr := httptest.NewRecorder()
+			h.SearchPolls(rr, httptest.NewRequest("GET", "/api/v1/polls/search?"+tt.query, nil), claims)
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusBadRequest, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestSearchPolls(t *testing.T) {
+	viewer := uuid.New()
+	poll := database.PollDetail{
+		ID:                uuid.New(),
+		Title:             "Best tacos",
+		Category:          "food",
+		ExpiresAt:         time.Now().Add(24 * time.Hour),
+		Status:            database.PollStatusActive,
+		MaxChoices:        1,
+		PollType:          database.PollTypeStandard,
+		Visibility:        database.PollVisibilityPublic,
+		ResultsVisibility: database.ResultsVisibilityAlways,
+		CreatorID:         uuid.New(),
+		TieBreak:          database.TieBreakNone,
+		CreatorFirstName:  "Ada",
+		Options:           json.RawMessage(`[{"id":"a","name":"Al pastor","count":2}]`),
+		Tags:              []string{"go", "tacos"},
+	}
+	row := fakePollDetailRow(poll, float64(0.5), "Best <mark>tacos</mark>")
+	db, args := newArgsFakeDB(t, map[string]fakeResult{
+		"SearchPolls": {columns: make([]string, len(row)), rows: [][]driver.Value{row}},
+	})
+	h := &pollHandler{cfg: &config.APIConfig{DB: db, Queries: database.New(db)}}
+
+	claims := &auth.CustomClaims{}
+	claims.Subject = viewer.String()
+	rr := httptest.NewRecorder()
+	h.SearchPolls(rr, httptest.NewRequest("GET", "/api/v1/polls/search?q=+tacos+&category=Food&status=active&tags=go,Tacos&tagMatch=all&limit=5&offset=10", nil), claims)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	// Query, viewer, category, status, tags, match all, page size and offset
+	want := []driver.Value{"tacos", viewer.String(), "food", "Active", `{"go","tacos"}`, true, int64(5), int64(10)}
+	if got := args["SearchPolls"]; !slices.Equal(got, want) {
+		t.Fatalf("expected the search to run with %v, got %v", want, got)
+	}
+
+	var results []SearchResult
+	if err := json.NewDecoder(rr.Body).Decode(&results); err != nil {
+		t.Fatalf("expected search results, got: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != poll.ID {
+		t.Fatalf("expected only poll %s, got %+v", poll.ID, results)
+	}
+	if results[0].Rank != 0.5 || results[0].Snippet != "Best <mark>tacos</mark>" {
+		t.Fatalf("expected rank 0.5 and the highlighted snippet, got %v and %q", results[0].Rank, results[0].Snippet)
+	}
+	if results[0].Title != poll.Title || !slices.Equal(results[0].Tags, poll.Tags) {
+		t.Fatalf("expected the poll's title and tags, got %q and %v", results[0].Title, results[0].Tags)
+	}
+}
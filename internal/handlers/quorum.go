@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxQuorum caps how many distinct voters a poll can require
+const maxQuorum = 1000000
+
+// validateQuorum checks a poll's quorum and its optional one-time extension.
+// Either may be nil, which keeps the current value on update. It returns the
+// offending field with the error.
+func validateQuorum(quorum, extensionDays *int32) (string, error) {
+	if quorum != nil && (*quorum < 0 || *quorum > maxQuorum) {
+		return "quorum", fmt.Errorf("quorum must be between 0 and %d", maxQuorum)
+	}
+	if extensionDays != nil && (*extensionDays < 0 || *extensionDays > maxLifecycleDays) {
+		return "quorumExtensionDays", fmt.Errorf("quorumExtensionDays must be between 0 and %d", maxLifecycleDays)
+	}
+	if quorum != nil && *quorum == 0 && extensionDays != nil && *extensionDays > 0 {
+		return "quorumExtensionDays", errors.New("quorumExtensionDays needs a quorum")
+	}
+	return "", nil
+}
+
+// quorumValue returns a create request's quorum setting, 0 when left out.
+func quorumValue(value *int32) int32 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+// quorumUpdate returns an update request's quorum setting, -1 when left out
+// so UpdatePoll keeps the current value.
+func quorumUpdate(value *int32) int32 {
+	if value == nil {
+		return -1
+	}
+	return *value
+}
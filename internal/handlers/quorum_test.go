@@ -0,0 +1,33 @@
+package handlers
+
+import "testing"
+
+func TestValidateQuorum(t *testing.T) {
+	value := func(v int32) *int32 { return &v }
+
+	tests := []struct {
+		name          string
+		quorum        *int32
+		extensionDays *int32
+		field         string
+	}{
+		{"Left out", nil, nil, ""},
+		{"Quorum with extension", value(25), value(3), ""},
+		{"Negative quorum", value(-1), nil, "quorum"},
+		{"Quorum too large", value(maxQuorum + 1), nil, "quorum"},
+		{"Extension too long", value(10), value(maxLifecycleDays + 1), "quorumExtensionDays"},
+		{"Extension without quorum", value(0), value(3), "quorumExtensionDays"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, err := validateQuorum(tt.quorum, tt.extensionDays)
+			if field != tt.field {
+				t.Fatalf("expected field %q, got %q", tt.field, field)
+			}
+			if (err != nil) != (tt.field != "") {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
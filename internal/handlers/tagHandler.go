@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/config"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	t "github.com/Ghostvox/trie_hard/go"
+)
+
+const (
+	maxTagsPerPoll = 10
+	maxTagLength   = 32
+	// tagSuggestionsPageSize is the default number of autocomplete suggestions
+	tagSuggestionsPageSize = 10
+)
+
+// TagSuggestion is an autocomplete match with the number of polls using it
+type TagSuggestion struct {
+	Name  string `json:"name"`
+	Polls int64  `json:"polls"`
+}
+
+type tagHandler struct {
+	cfg *config.APIConfig
+}
+
+func NewTagHandler(cfg *config.APIConfig) *tagHandler {
+	return &tagHandler{cfg: cfg}
+}
+
+// AutocompleteTags suggests existing tags starting with prefix, most used
+// first. An empty prefix returns the most used tags.
+func (h *tagHandler) AutocompleteTags(w http.ResponseWriter, r *http.Request) {
+	limit, err := getPageLimit(r, tagSuggestionsPageSize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "limit", "Invalid limit", err)
+		return
+	}
+
+	rows, err := h.cfg.Queries.SearchTagsByPrefix(r.Context(), database.SearchTagsByPrefixParams{
+		Prefix:   slugify(r.URL.Query().Get("prefix")),
+		PageSize: int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to retrieve tags", err)
+		return
+	}
+
+	suggestions := make([]TagSuggestion, len(rows))
+	for i, row := range rows {
+		suggestions[i] = TagSuggestion{Name: row.Name, Polls: row.Polls}
+	}
+	respondWithJSON(w, http.StatusOK, suggestions)
+}
+
+// normalizeTags slugifies tags and drops duplicates, keeping the order given.
+func normalizeTags(raw []string) ([]string, error) {
+	seen := make(map[string]bool, len(raw))
+	tags := make([]string, 0, len(raw))
+	for _, tag := range raw {
+		tag = slugify(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > maxTagLength {
+			return nil, fmt.Errorf("Tags can be at most %d characters", maxTagLength)
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTagsPerPoll {
+		return nil, fmt.Errorf("A poll can have at most %d tags", maxTagsPerPoll)
+	}
+	return tags, nil
+}
+
+// checkTagsClean runs every word of every tag through the profanity filter.
+func checkTagsClean(tags []string, filter *t.Trie[string], w http.ResponseWriter) bool {
+	if !areTagsClean(tags, filter) {
+		respondWithError(w, http.StatusBadRequest, "profanity", "Input contains profanity", nil)
+		return false
+	}
+	return true
+}
+
+// areTagsClean reports whether no word of any tag is in the profanity filter.
+func areTagsClean(tags []string, filter *t.Trie[string]) bool {
+	for _, tag := range tags {
+		if !isInputClean(strings.ReplaceAll(tag, "-", " "), filter) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTagFilter reads the tags and tagMatch query parameters of the poll
+// lists. tags is a comma separated list, tagMatch is any (the default) or all.
+func parseTagFilter(r *http.Request) ([]string, bool, error) {
+	tags := []string{}
+	if value := r.URL.Query().Get("tags"); value != "" {
+		var err error
+		tags, err = normalizeTags(strings.Split(value, ","))
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	switch r.URL.Query().Get("tagMatch") {
+	case "", "any":
+		return tags, false, nil
+	case "all":
+		return tags, true, nil
+	default:
+		return nil, false, errors.New("tagMatch must be any or all")
+	}
+}
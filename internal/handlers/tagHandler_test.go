@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	t.Run("Slugifies and drops duplicates", func(t *testing.T) {
+		tags, err := normalizeTags([]string{"Web Dev", "golang", "web-dev", " ", "GoLang"})
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if !reflect.DeepEqual(tags, []string{"web-dev", "golang"}) {
+			t.Fatalf("expected [web-dev golang], got %v", tags)
+		}
+	})
+
+	t.Run("Too many tags", func(t *testing.T) {
+		raw := make([]string, maxTagsPerPoll+1)
+		for i := range raw {
+			raw[i] = strings.Repeat("a", i+1)
+		}
+		if _, err := normalizeTags(raw); err == nil {
+			t.Fatalf("expected an error for %d tags", len(raw))
+		}
+	})
+
+	t.Run("Tag too long", func(t *testing.T) {
+		if _, err := normalizeTags([]string{strings.Repeat("a", maxTagLength+1)}); err == nil {
+			t.Fatalf("expected an error for a long tag")
+		}
+	})
+}
+
+func TestParseTagFilter(t *testing.T) {
+	tests := []struct {
+		query    string
+		tags     []string
+		matchAll bool
+		wantErr  bool
+	}{
+		{query: "", tags: []string{}},
+		{query: "?tags=Go,web%20dev", tags: []string{"go", "web-dev"}},
+		{query: "?tags=go,web&tagMatch=all", tags: []string{"go", "web"}, matchAll: true},
+		{query: "?tags=go&tagMatch=any", tags: []string{"go"}},
+		{query: "?tags=go&tagMatch=some", wantErr: true},
+	}
+	for _, tt := range tests {
+		tags, matchAll, err := parseTagFilter(httptest.NewRequest("GET", "/api/v1/polls/active"+tt.query, nil))
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%q: expected an error", tt.query)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: expected no error, got: %v", tt.query, err)
+		}
+		if !reflect.DeepEqual(tags, tt.tags) || matchAll != tt.matchAll {
+			t.Fatalf("%q: expected %v (all=%v), got %v (all=%v)", tt.query, tt.tags, tt.matchAll, tags, matchAll)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// maxTemplateNameLength caps how long a template's name can be
+const maxTemplateNameLength = 100
+
+// TemplateRequest is the body of SaveTemplate. TitlePattern may contain
+// {date}, {week} and {month}, filled in from the day a poll is created.
+type TemplateRequest struct {
+	Name         string   `json:"name"`
+	TitlePattern string   `json:"titlePattern"`
+	Description  string   `json:"description"`
+	Category     string   `json:"category"`
+	Options      []string `json:"options"`
+	DurationDays int32    `json:"durationDays"`
+	Type         string   `json:"type"`
+	MaxChoices   int32    `json:"maxChoices"`
+	RatingMax    int32    `json:"ratingMax"`
+	Global       bool     `json:"global"`
+}
+
+// Template is a saved poll template as returned to callers
+type Template struct {
+	ID           uuid.UUID `json:"id"`
+	CreatorID    uuid.UUID `json:"creatorId"`
+	Name         string    `json:"name"`
+	TitlePattern string    `json:"titlePattern"`
+	Description  string    `json:"description"`
+	Category     string    `json:"category"`
+	Options      []string  `json:"options"`
+	DurationDays int32     `json:"durationDays"`
+	Type         string    `json:"type"`
+	MaxChoices   int32     `json:"maxChoices"`
+	RatingMax    int32     `json:"ratingMax"`
+	Global       bool      `json:"global"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// TemplatePage is one page of the templates a caller can use
+type TemplatePage struct {
+	Templates  []Template `json:"templates"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// SaveTemplate saves a poll template for the caller. The template is checked
+// like a new poll would be, so every poll created from it starts out valid.
+// Only admins can publish global templates.
+func (h *pollHandler) SaveTemplate(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	userUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "accessToken", "Invalid access token", err)
+		return
+	}
+
+	var req TemplateRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid request body", err)
+		return
+	}
+	if req.Global && claims.Role != "admin" {
+		respondWithError(w, http.StatusForbidden, http.StatusText(http.StatusForbidden), "Only admins can publish global templates", nil)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > maxTemplateNameLength {
+		respondWithError(w, http.StatusBadRequest, "name", fmt.Sprintf("name is required and at most %d characters", maxTemplateNameLength), nil)
+		return
+	}
+
+	template := database.PollTemplate{
+		TitlePattern: strings.TrimSpace(req.TitlePattern),
+		Description:  req.Description,
+		Category:     req.Category,
+		Options:      req.Options,
+		DurationDays: req.DurationDays,
+		PollType:     database.PollType(req.Type),
+		MaxChoices:   req.MaxChoices,
+		RatingMax:    req.RatingMax,
+	}
+	preview := templateToPoll(template, time.Now())
+	if field, err := h.validateNewPoll(r.Context(), &preview); err != nil {
+		if field == "title" {
+			field = "titlePattern"
+		}
+		respondWithPollValidationError(w, field, err)
+		return
+	}
+
+	record, err := h.cfg.Queries.CreatePollTemplate(r.Context(), database.CreatePollTemplateParams{
+		UserID:       userUUID,
+		Name:         req.Name,
+		TitlePattern: template.TitlePattern,
+		Description:  template.Description,
+		Category:     preview.Category,
+		Options:      template.Options,
+		DurationDays: template.DurationDays,
+		PollType:     database.PollType(preview.Type),
+		MaxChoices:   preview.MaxChoices,
+		RatingMax:    preview.RatingMax,
+		IsGlobal:     req.Global,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to save template", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, toTemplate(record))
+}
+
+// GetTemplates lists the global templates and the caller's own, newest first.
+func (h *pollHandler) GetTemplates(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	userUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "accessToken", "Invalid access token", err)
+		return
+	}
+	limit, cursor, err := getPageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid limit or cursor", err)
+		return
+	}
+
+	records, err := h.cfg.Queries.GetPollTemplates(r.Context(), database.GetPollTemplatesParams{
+		UserID:          userUUID,
+		CursorCreatedAt: cursor.at(),
+		CursorID:        cursor.id(),
+		PageSize:        int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to retrieve templates", err)
+		return
+	}
+	records, next := nextCursor(records, limit, func(record database.PollTemplate) pageCursor {
+		return pageCursor{At: record.CreatedAt, ID: record.ID}
+	})
+
+	templates := make([]Template, len(records))
+	for i, record := range records {
+		templates[i] = toTemplate(record)
+	}
+	respondWithJSON(w, http.StatusOK, TemplatePage{Templates: templates, NextCursor: next})
+}
+
+// CreatePollFromTemplate creates a poll owned by the caller from a global
+// template or one of their own. The poll opens now and runs for the
+// template's duration.
+func (h *pollHandler) CreatePollFromTemplate(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	templateUUID, err := uuid.Parse(r.PathValue("templateId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "templateId", "Invalid template ID", err)
+		return
+	}
+	userUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "accessToken", "Invalid access token", err)
+		return
+	}
+
+	template, err := h.cfg.Queries.GetPollTemplate(r.Context(), database.GetPollTemplateParams{
+		ID:     templateUUID,
+		UserID: userUUID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "Template not found", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to retrieve template", err)
+		return
+	}
+
+	// The filter and categories may have changed since the template was saved
+	newPoll := templateToPoll(template, time.Now())
+	if field, err := h.validateNewPoll(r.Context(), &newPoll); err != nil {
+		respondWithPollValidationError(w, field, err)
+		return
+	}
+
+	pollID, err := CreatePollWithOptions(r.Context(), h.cfg, newPoll, userUUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to create poll", err)
+		return
+	}
+
+	pollResponse, err := h.loadPollResponse(r.Context(), pollID, userUUID, claims.Role == "admin")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, pollResponse)
+}
+
+// templateToPoll builds the body of a new poll from a template, filling in
+// the title pattern's placeholders from now.
+func templateToPoll(template database.PollTemplate, now time.Time) poll {
+	newPoll := poll{
+		Title:       expandTitlePattern(template.TitlePattern, now),
+		Description: template.Description,
+		Category:    template.Category,
+		ExpiresAt:   strconv.Itoa(int(template.DurationDays)),
+		Type:        string(template.PollType),
+		MaxChoices:  template.MaxChoices,
+		RatingMax:   template.RatingMax,
+	}
+	for _, option := range template.Options {
+		newPoll.Options = append(newPoll.Options, CreateOption{Name: option})
+	}
+	return newPoll
+}
+
+// expandTitlePattern replaces {date} with the day as 2006-01-02, {week} with
+// the ISO week as 2006-W01 and {month} with the month as January 2006.
+func expandTitlePattern(pattern string, now time.Time) string {
+	year, week := now.ISOWeek()
+	return strings.NewReplacer(
+		"{date}", now.Format("2006-01-02"),
+		"{week}", fmt.Sprintf("%d-W%02d", year, week),
+		"{month}", now.Format("January 2006"),
+	).Replace(pattern)
+}
+
+func toTemplate(record database.PollTemplate) Template {
+	return Template{
+		ID:           record.ID,
+		CreatorID:    record.UserID,
+		Name:         record.Name,
+		TitlePattern: record.TitlePattern,
+		Description:  record.Description,
+		Category:     record.Category,
+		Options:      record.Options,
+		DurationDays: record.DurationDays,
+		Type:         string(record.PollType),
+		MaxChoices:   record.MaxChoices,
+		RatingMax:    record.RatingMax,
+		Global:       record.IsGlobal,
+		CreatedAt:    record.CreatedAt,
+	}
+}
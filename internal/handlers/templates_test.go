@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+)
+
+func TestExpandTitlePattern(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{pattern: "Standup {date}", want: "Standup 2025-01-01"},
+		{pattern: "Lunch for {week}", want: "Lunch for 2025-W01"},
+		{pattern: "Book club, {month}", want: "Book club, January 2025"},
+		{pattern: "No placeholders", want: "No placeholders"},
+		{pattern: "{unknown}", want: "{unknown}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := expandTitlePattern(tt.pattern, now); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTemplateToPoll(t *testing.T) {
+	now := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	template := database.PollTemplate{
+		TitlePattern: "Retro {week}",
+		Description:  "What went well?",
+		Category:     "work",
+		Options:      []string{"Shipping", "Pairing"},
+		DurationDays: 5,
+		PollType:     database.PollTypeRanked,
+		MaxChoices:   2,
+	}
+
+	got := templateToPoll(template, now)
+	if got.Title != "Retro 2025-W23" {
+		t.Fatalf("expected title Retro 2025-W23, got %q", got.Title)
+	}
+	if got.ExpiresAt != "5" {
+		t.Fatalf("expected expiresAt 5, got %q", got.ExpiresAt)
+	}
+	if got.Type != string(database.PollTypeRanked) || got.MaxChoices != 2 || got.Category != "work" {
+		t.Fatalf("expected a ranked work poll with 2 choices, got %q %q %d", got.Type, got.Category, got.MaxChoices)
+	}
+	if !reflect.DeepEqual(got.Options, []CreateOption{{Name: "Shipping"}, {Name: "Pairing"}}) {
+		t.Fatalf("expected Shipping and Pairing, got %v", got.Options)
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// tieBreakRequest is the body of the tie-break endpoint
+type tieBreakRequest struct {
+	OptionID string `json:"optionId"`
+}
+
+// BreakTie lets the creator of a closed poll pick the winner among its tied
+// leaders, when the poll was created with the creator tie-break policy.
+func (h *pollHandler) BreakTie(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+
+	var req tieBreakRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid request body", err)
+		return
+	}
+	optionUUID, err := uuid.Parse(req.OptionID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "optionId", "Invalid option ID", err)
+		return
+	}
+
+	isAdmin := claims.Role == "admin"
+	pollResponse, err := h.loadPollResponse(r.Context(), pollUUID, userUUID, isAdmin)
+	if err != nil {
+		respondWithTieBreakError(w, err)
+		return
+	}
+	var leaders []string
+	if pollResponse.Results != nil {
+		leaders = pollResponse.Results.Leaders
+	}
+
+	_, err = BreakTie(r.Context(), h.cfg, pollUUID, userUUID, isAdmin, optionUUID, leaders)
+	if err != nil {
+		respondWithTieBreakError(w, err)
+		return
+	}
+
+	h.respondWithUpdatedPoll(w, r, pollUUID, userUUID, isAdmin)
+}
+
+// parseTieBreak validates how a poll settles a tie for first, none when empty.
+func parseTieBreak(tieBreak string) (database.TieBreak, error) {
+	switch database.TieBreak(tieBreak) {
+	case "":
+		return database.TieBreakNone, nil
+	case database.TieBreakNone, database.TieBreakEarliestVote, database.TieBreakCreator:
+		return database.TieBreak(tieBreak), nil
+	default:
+		return "", errors.New("tieBreak must be none, earliest_vote or creator")
+	}
+}
+
+// tieWinner returns the creator's stored pick, or an empty string.
+func tieWinner(pick uuid.NullUUID) string {
+	if !pick.Valid {
+		return ""
+	}
+	return pick.UUID.String()
+}
+
+func respondWithTieBreakError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		respondWithLifecycleError(w, ErrPollNotFound)
+	case errors.Is(err, ErrNoCreatorTieBreak):
+		respondWithError(w, http.StatusConflict, "tieBreak", "Ties on this poll are not decided by its creator", err)
+	case errors.Is(err, ErrPollNotClosed):
+		respondWithError(w, http.StatusConflict, "status", "Poll has not closed yet", err)
+	case errors.Is(err, ErrNoQuorum):
+		respondWithError(w, http.StatusConflict, "outcome", "Poll closed short of its quorum", err)
+	case errors.Is(err, ErrNotTiedLeader):
+		respondWithError(w, http.StatusBadRequest, "optionId", "Option is not tied for the lead", err)
+	default:
+		respondWithLifecycleError(w, err)
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+)
+
+func TestParseTieBreak(t *testing.T) {
+	t.Run("Empty defaults to none", func(t *testing.T) {
+		tieBreak, err := parseTieBreak("")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if tieBreak != database.TieBreakNone {
+			t.Fatalf("expected none, got %q", tieBreak)
+		}
+	})
+
+	t.Run("Known policy", func(t *testing.T) {
+		tieBreak, err := parseTieBreak("creator")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if tieBreak != database.TieBreakCreator {
+			t.Fatalf("expected creator, got %q", tieBreak)
+		}
+	})
+
+	t.Run("Unknown policy", func(t *testing.T) {
+		if _, err := parseTieBreak("coin_flip"); err == nil {
+			t.Fatalf("expected an error for an unknown policy")
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// Timeline is a poll's vote history grouped into hour or day buckets.
+// Buckets without votes are left out.
+type Timeline struct {
+	PollID  uuid.UUID        `json:"pollId"`
+	Bucket  string           `json:"bucket"`
+	Buckets []TimelineBucket `json:"buckets"`
+}
+
+// TimelineBucket holds the votes cast per option during one bucket, the
+// running totals at its end and the options leading at that point.
+type TimelineBucket struct {
+	Start      time.Time        `json:"start"`
+	Votes      map[string]int64 `json:"votes"`
+	Cumulative map[string]int64 `json:"cumulative"`
+	Leaders    []string         `json:"leaders"`
+}
+
+// GetPollTimeline returns how a poll's votes came in over time, so clients
+// can chart it and creators can see whether the lead changed late.
+func (h *pollHandler) GetPollTimeline(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+	bucket, err := parseTimelineBucket(r.URL.Query().Get("bucket"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "bucket", err.Error(), err)
+		return
+	}
+
+	isAdmin := claims.Role == "admin"
+	viewer := uuid.NullUUID{UUID: userUUID, Valid: true}
+	if _, err := authorizePollView(r.Context(), h.cfg, pollUUID, viewer, isAdmin, r.URL.Query().Get(shareTokenParam)); err != nil {
+		respondWithLifecycleError(w, err)
+		return
+	}
+
+	pollResponse, err := h.loadPollResponse(r.Context(), pollUUID, userUUID, isAdmin)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+		return
+	}
+	// The history gives away the counts, so it follows the poll's results visibility
+	if pollResponse.ResultsHidden {
+		respondWithError(w, http.StatusForbidden, "resultsVisibility", "Results of this poll are not visible yet", nil)
+		return
+	}
+
+	rows, err := h.cfg.Queries.GetPollTimeline(r.Context(), database.GetPollTimelineParams{
+		Bucket: bucket,
+		PollID: pollUUID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to retrieve timeline", err)
+		return
+	}
+
+	optionIDs := make([]string, len(pollResponse.Options))
+	for i, option := range pollResponse.Options {
+		optionIDs[i] = option.ID
+	}
+	respondWithJSON(w, http.StatusOK, Timeline{
+		PollID:  pollUUID,
+		Bucket:  bucket,
+		Buckets: buildTimeline(optionIDs, rows),
+	})
+}
+
+// parseTimelineBucket validates the timeline bucket size, day when empty.
+func parseTimelineBucket(bucket string) (string, error) {
+	switch bucket {
+	case "":
+		return "day", nil
+	case "hour", "day":
+		return bucket, nil
+	default:
+		return "", errors.New("bucket must be hour or day")
+	}
+}
+
+// buildTimeline folds per bucket counts, ordered by bucket, into running
+// totals. Every option appears in each bucket's totals, even before its
+// first vote.
+func buildTimeline(optionIDs []string, rows []database.GetPollTimelineRow) []TimelineBucket {
+	buckets := []TimelineBucket{}
+	totals := make(map[string]int64, len(optionIDs))
+	for _, optionID := range optionIDs {
+		totals[optionID] = 0
+	}
+
+	for _, row := range rows {
+		if len(buckets) == 0 || !buckets[len(buckets)-1].Start.Equal(row.Bucket) {
+			buckets = append(buckets, TimelineBucket{Start: row.Bucket, Votes: map[string]int64{}})
+		}
+		buckets[len(buckets)-1].Votes[row.OptionID.String()] += row.Votes
+	}
+
+	for i := range buckets {
+		for optionID, votes := range buckets[i].Votes {
+			totals[optionID] += votes
+		}
+		buckets[i].Cumulative = maps.Clone(totals)
+		buckets[i].Leaders = timelineLeaders(optionIDs, totals)
+	}
+	return buckets
+}
+
+// timelineLeaders returns the options with the most votes so far, in option
+// order.
+func timelineLeaders(optionIDs []string, totals map[string]int64) []string {
+	most := int64(0)
+	for _, votes := range totals {
+		most = max(most, votes)
+	}
+
+	leaders := []string{}
+	if most == 0 {
+		return leaders
+	}
+	for _, optionID := range optionIDs {
+		if totals[optionID] == most {
+			leaders = append(leaders, optionID)
+		}
+	}
+	return leaders
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestParseTimelineBucket(t *testing.T) {
+	if bucket, err := parseTimelineBucket(""); err != nil || bucket != "day" {
+		t.Fatalf("expected day, got %q (%v)", bucket, err)
+	}
+	if bucket, err := parseTimelineBucket("hour"); err != nil || bucket != "hour" {
+		t.Fatalf("expected hour, got %q (%v)", bucket, err)
+	}
+	if _, err := parseTimelineBucket("week"); err == nil {
+		t.Fatalf("expected an error for an unknown bucket")
+	}
+}
+
+func TestBuildTimeline(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+	optionIDs := []string{first.String(), second.String()}
+	start := time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Running totals and a late flip", func(t *testing.T) {
+		buckets := buildTimeline(optionIDs, []database.GetPollTimelineRow{
+			{Bucket: start, OptionID: first, Votes: 3},
+			{Bucket: start, OptionID: second, Votes: 1},
+			{Bucket: start.Add(time.Hour), OptionID: second, Votes: 4},
+		})
+		if len(buckets) != 2 {
+			t.Fatalf("expected 2 buckets, got %d", len(buckets))
+		}
+
+		expected := map[string]int64{first.String(): 3, second.String(): 1}
+		if !reflect.DeepEqual(buckets[0].Cumulative, expected) {
+			t.Fatalf("expected %v after the first bucket, got %v", expected, buckets[0].Cumulative)
+		}
+		if !reflect.DeepEqual(buckets[0].Leaders, []string{first.String()}) {
+			t.Fatalf("expected the first option to lead, got %v", buckets[0].Leaders)
+		}
+
+		expected = map[string]int64{first.String(): 3, second.String(): 5}
+		if !reflect.DeepEqual(buckets[1].Cumulative, expected) {
+			t.Fatalf("expected %v after the second bucket, got %v", expected, buckets[1].Cumulative)
+		}
+		if !reflect.DeepEqual(buckets[1].Votes, map[string]int64{second.String(): 4}) {
+			t.Fatalf("expected 4 votes for the second option in the second bucket, got %v", buckets[1].Votes)
+		}
+		if !reflect.DeepEqual(buckets[1].Leaders, []string{second.String()}) {
+			t.Fatalf("expected the second option to lead, got %v", buckets[1].Leaders)
+		}
+	})
+
+	t.Run("Tied leaders", func(t *testing.T) {
+		buckets := buildTimeline(optionIDs, []database.GetPollTimelineRow{
+			{Bucket: start, OptionID: first, Votes: 2},
+			{Bucket: start, OptionID: second, Votes: 2},
+		})
+		if !reflect.DeepEqual(buckets[0].Leaders, optionIDs) {
+			t.Fatalf("expected both options to lead, got %v", buckets[0].Leaders)
+		}
+	})
+
+	t.Run("No votes", func(t *testing.T) {
+		if buckets := buildTimeline(optionIDs, nil); len(buckets) != 0 {
+			t.Fatalf("expected no buckets, got %v", buckets)
+		}
+	})
+}
@@ -5,15 +5,83 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
 	"github.com/GhostVox/ghostvox.io-backend/internal/config"
 	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/GhostVox/ghostvox.io-backend/internal/tally"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+var (
+	ErrPollNotFound        = errors.New("poll not found")
+	ErrAlreadyVoted        = errors.New("user has already voted on this poll")
+	ErrTooManyChoices      = errors.New("too many options selected")
+	ErrInvalidOption       = errors.New("option does not belong to this poll")
+	ErrWrongBallot         = errors.New("ballot does not match the poll type")
+	ErrInvalidScore        = errors.New("rating is outside the poll's scale")
+	ErrUnratedOption       = errors.New("every option must be rated")
+	ErrPollClosed          = errors.New("poll is not accepting votes")
+	ErrPollNotOpen         = errors.New("poll has not opened yet")
+	ErrVotesLocked         = errors.New("votes on this poll are locked")
+	ErrNoVote              = errors.New("user has not voted on this poll")
+	ErrGuestVotingDisabled = errors.New("poll does not accept guest votes")
+	ErrNoGuestSession      = errors.New("guest has no voter session")
+	ErrInvalidSession      = errors.New("invalid voter session")
+	ErrNotPollOwner        = errors.New("user does not own this poll")
+	ErrNotPollEditor       = errors.New("user cannot edit this poll")
+	ErrInvalidTransition   = errors.New("poll status change is not allowed")
+	ErrReopenWindowPassed  = errors.New("poll closed too long ago to reopen")
+	ErrOptionNotFound      = errors.New("option not found")
+	ErrOptionHasVotes      = errors.New("option already has votes")
+	ErrOptionOrder         = errors.New("order must list every option of the poll once")
+	ErrLastOption          = errors.New("poll needs at least one option")
+	ErrNoCreatorTieBreak   = errors.New("poll's ties are not decided by its creator")
+	ErrPollNotClosed       = errors.New("poll has not closed yet")
+	ErrNotTiedLeader       = errors.New("option is not tied for the lead")
+	ErrNoQuorum            = errors.New("poll closed short of its quorum")
+	ErrPollIsDraft         = errors.New("poll is a draft")
+	ErrPollNotDraft        = errors.New("poll is not a draft")
+	ErrProfanity           = errors.New("input contains profanity")
+)
+
+// reopenGraceWindow is how long after closing an archived poll can be reopened.
+const reopenGraceWindow = 24 * time.Hour
+
+// pollTransitions lists the poll_status changes allowed by the lifecycle
+// actions. Inactive polls are only opened by the scheduler and archived polls
+// can only be reopened inside the grace window.
+var pollTransitions = map[database.PollStatus][]database.PollStatus{
+	database.PollStatusActive:   {database.PollStatusArchived},
+	database.PollStatusArchived: {database.PollStatusActive},
+}
+
+func validateTransition(from, to database.PollStatus) error {
+	if slices.Contains(pollTransitions[from], to) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+}
+
+// Voter identifies who cast a ballot, a signed in user or a guest holding a
+// signed anonymous voter cookie.
+type Voter struct {
+	ID    uuid.UUID
+	Guest bool
+}
+
+func (v Voter) userID() uuid.NullUUID {
+	return uuid.NullUUID{UUID: v.ID, Valid: !v.Guest}
+}
+
+func (v Voter) guestID() uuid.NullUUID {
+	return uuid.NullUUID{UUID: v.ID, Valid: v.Guest}
+}
+
 func addUserAndRefreshToken(ctx context.Context, db *sql.DB, queries *database.Queries, user *User) (string, database.User, error) {
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
@@ -133,77 +201,1209 @@ func deleteAndReplaceRefreshToken(ctx context.Context, cfg *config.APIConfig, us
 	return refreshRecord.Token, nil
 }
 
-func CreatePollWithOptions(ctx context.Context, cfg *config.APIConfig, poll poll, userUUID uuid.UUID) (err error) {
+func CreatePollWithOptions(ctx context.Context, cfg *config.APIConfig, poll poll, userUUID uuid.UUID) (uuid.UUID, error) {
 	tx, err := cfg.DB.Begin()
 	if err != nil {
-		return err
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	pollID, err := createPollWithOptions(ctx, qtx, poll, userUUID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return pollID, tx.Commit()
+}
+
+// CreatePollsWithOptions creates a batch of polls in one transaction. Each
+// poll is inserted under its own savepoint, so a poll that fails is rolled
+// back alone and reported at its index while the rest of the batch is kept.
+// The returned error is set when the batch as a whole failed.
+func CreatePollsWithOptions(ctx context.Context, cfg *config.APIConfig, polls []poll, userUUID uuid.UUID) ([]uuid.UUID, []error, error) {
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
 	}
 	defer tx.Rollback()
 	qtx := cfg.Queries.WithTx(tx)
-	exp, err := strconv.Atoi(poll.ExpiresAt)
+
+	ids := make([]uuid.UUID, len(polls))
+	errs := make([]error, len(polls))
+	for i, poll := range polls {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT create_poll"); err != nil {
+			return nil, nil, err
+		}
+		ids[i], errs[i] = createPollWithOptions(ctx, qtx, poll, userUUID)
+		release := "RELEASE SAVEPOINT create_poll"
+		if errs[i] != nil {
+			release = "ROLLBACK TO SAVEPOINT create_poll"
+		}
+		if _, err := tx.ExecContext(ctx, release); err != nil {
+			return nil, nil, err
+		}
+	}
+	return ids, errs, tx.Commit()
+}
+
+// createPollWithOptions inserts a poll with its options, tags, allowlist and
+// co-editors. A poll whose Status is Draft is saved as a draft.
+func createPollWithOptions(ctx context.Context, qtx *database.Queries, poll poll, userUUID uuid.UUID) (uuid.UUID, error) {
+	startsAt, expiresAt, status, err := pollSchedule(poll, time.Now())
 	if err != nil {
-		return err
+		return uuid.Nil, err
 	}
-	expiresAt := time.Now().Add(time.Duration(exp) * 24 * time.Hour) // write a reusable helper for this and test.
 
 	pollRecord, err := qtx.CreatePoll(ctx, database.CreatePollParams{
-		UserID:      userUUID,
-		Title:       poll.Title,
-		Description: poll.Description,
-		Category:    poll.Category,
-		ExpiresAt:   expiresAt,
-		Status:      database.PollStatus("Active"),
+		UserID:              userUUID,
+		Title:               poll.Title,
+		Description:         poll.Description,
+		Category:            poll.Category,
+		ExpiresAt:           expiresAt,
+		Status:              status,
+		MaxChoices:          poll.MaxChoices,
+		PollType:            database.PollType(poll.Type),
+		RatingMax:           poll.RatingMax,
+		VotesLocked:         poll.LockVotes,
+		AllowGuestVotes:     poll.AllowGuestVotes,
+		StartsAt:            startsAt,
+		Visibility:          database.PollVisibility(poll.Visibility),
+		ResultsVisibility:   database.ResultsVisibility(poll.ResultsVisibility),
+		TieBreak:            database.TieBreak(poll.TieBreak),
+		Quorum:              quorumValue(poll.Quorum),
+		QuorumExtensionDays: quorumValue(poll.QuorumExtensionDays),
+		ClonedFrom:          poll.clonedFrom,
 	})
 	if err != nil {
-		return err
+		return uuid.Nil, err
 	}
 
+	err = addPollContents(ctx, qtx, pollRecord.ID, poll)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return pollRecord.ID, nil
+}
+
+// pollSchedule works out when a poll opens and closes and the status it starts
+// in. Polls with a future start time are created Inactive and opened by cron.
+// Drafts keep their start time and duration until they are published, and may
+// leave expiresAt empty.
+func pollSchedule(poll poll, now time.Time) (time.Time, time.Time, database.PollStatus, error) {
+	isDraft := poll.Status == string(database.PollStatusDraft)
+	days := 0
+	if poll.ExpiresAt != "" || !isDraft {
+		exp, err := strconv.Atoi(poll.ExpiresAt)
+		if err != nil {
+			return time.Time{}, time.Time{}, "", err
+		}
+		days = exp
+	}
+
+	startsAt := now
+	status := database.PollStatusActive
+	if poll.StartsAt != "" {
+		scheduled, err := time.Parse(time.RFC3339, poll.StartsAt)
+		if err != nil {
+			return time.Time{}, time.Time{}, "", err
+		}
+		if scheduled.After(now) {
+			startsAt = scheduled.Local()
+			status = database.PollStatusInactive
+		}
+	}
+	if isDraft {
+		status = database.PollStatusDraft
+	}
+	return startsAt, startsAt.Add(time.Duration(days) * 24 * time.Hour), status, nil
+}
+
+// addPollContents inserts a poll's options, tags, allowlist and co-editors.
+func addPollContents(ctx context.Context, qtx *database.Queries, pollID uuid.UUID, poll poll) error {
 	names := make([]string, len(poll.Options))
 	for i, option := range poll.Options {
 		names[i] = option.Name
 	}
-	_, err = qtx.CreateOptions(ctx, database.CreateOptionsParams{
-		PollID:  pollRecord.ID,
+	_, err := qtx.CreateOptions(ctx, database.CreateOptionsParams{
+		PollID:  pollID,
 		Column2: names,
 	})
 	if err != nil {
 		return err
 	}
 
+	if len(poll.Tags) > 0 {
+		err = tagPoll(ctx, qtx, pollID, poll.Tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(poll.Allowlist) > 0 {
+		err = allowPollViewers(ctx, qtx, pollID, poll.Allowlist)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(poll.Editors) > 0 {
+		editors, err := normalizeEditors(poll.Editors)
+		if err != nil {
+			return err
+		}
+		err = addPollEditors(ctx, qtx, pollID, editors)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdatePollDetails applies an edit to a poll's details and, when tags is not
+// nil, replaces its tags. The creator, admins and co-editors may edit. When
+// days is not 0 the poll closes that many days from now, which only open polls
+// accept, otherwise the deadline is kept.
+func UpdatePollDetails(ctx context.Context, cfg *config.APIConfig, userID uuid.UUID, isAdmin bool, params database.UpdatePollParams, days int, tags []string) (database.Poll, error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return database.Poll{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	pollRecord, err := lockPollForChange(ctx, qtx, params.ID, userID, isAdmin, permissionEdit)
+	if err != nil {
+		return database.Poll{}, err
+	}
+	if pollRecord.Status == database.PollStatusDraft {
+		return database.Poll{}, ErrPollIsDraft
+	}
+
+	params.ExpiresAt = pollRecord.ExpiresAt
+	if days != 0 {
+		if pollRecord.Status != database.PollStatusActive {
+			return database.Poll{}, fmt.Errorf("%w: only open polls can change their deadline, closed polls must be reopened", ErrInvalidTransition)
+		}
+		params.ExpiresAt = time.Now().Add(time.Duration(days) * 24 * time.Hour)
+	}
+
+	pollRecord, err = qtx.UpdatePoll(ctx, params)
+	if err != nil {
+		return database.Poll{}, err
+	}
+
+	// Tags are only replaced when the edit includes them
+	if tags != nil {
+		err = qtx.DeletePollTags(ctx, params.ID)
+		if err != nil {
+			return database.Poll{}, err
+		}
+		if len(tags) > 0 {
+			err = tagPoll(ctx, qtx, params.ID, tags)
+			if err != nil {
+				return database.Poll{}, err
+			}
+		}
+	}
+
+	return pollRecord, tx.Commit()
+}
+
+// SaveDraft saves over a draft. The options are replaced as a whole, tags,
+// allowlist and co-editors only when draft includes them. Only the creator
+// may save a draft, co-editors can't see it until it is published.
+func SaveDraft(ctx context.Context, cfg *config.APIConfig, pollID, userID uuid.UUID, isAdmin bool, draft poll) (database.Poll, error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return database.Poll{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	pollRecord, err := lockPollForChange(ctx, qtx, pollID, userID, isAdmin, permissionManage)
+	if err != nil {
+		return database.Poll{}, err
+	}
+	if pollRecord.Status != database.PollStatusDraft {
+		return database.Poll{}, ErrPollNotDraft
+	}
+
+	draft.Status = string(database.PollStatusDraft)
+	pollRecord, err = updateDraft(ctx, qtx, pollID, draft, time.Now())
+	if err != nil {
+		return database.Poll{}, err
+	}
+
+	err = qtx.DeletePollOptions(ctx, pollID)
+	if err != nil {
+		return database.Poll{}, err
+	}
+	if draft.Tags != nil {
+		err = qtx.DeletePollTags(ctx, pollID)
+		if err != nil {
+			return database.Poll{}, err
+		}
+	}
+	if draft.Allowlist != nil {
+		err = qtx.DeletePollAllowlist(ctx, pollID)
+		if err != nil {
+			return database.Poll{}, err
+		}
+	}
+	if draft.Editors != nil {
+		err = qtx.DeletePollEditors(ctx, pollID)
+		if err != nil {
+			return database.Poll{}, err
+		}
+	}
+	err = addPollContents(ctx, qtx, pollID, draft)
+	if err != nil {
+		return database.Poll{}, err
+	}
+
+	return pollRecord, tx.Commit()
+}
+
+// PublishDraft runs a draft through validate, the full validation of a new
+// poll, and opens it, or schedules it when its start time is still ahead.
+// A validation failure is returned as a *pollValidationError. Only the
+// creator may publish a draft.
+func PublishDraft(ctx context.Context, cfg *config.APIConfig, pollID, userID uuid.UUID, isAdmin bool, validate func(*poll) (string, error)) (database.Poll, error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return database.Poll{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	pollRecord, err := lockPollForChange(ctx, qtx, pollID, userID, isAdmin, permissionManage)
+	if err != nil {
+		return database.Poll{}, err
+	}
+	if pollRecord.Status != database.PollStatusDraft {
+		return database.Poll{}, ErrPollNotDraft
+	}
+
+	options, err := qtx.GetOptionsByPollIDs(ctx, []uuid.UUID{pollID})
+	if err != nil {
+		return database.Poll{}, err
+	}
+	tags, err := qtx.GetPollTagNames(ctx, pollID)
+	if err != nil {
+		return database.Poll{}, err
+	}
+
+	now := time.Now()
+	draft := draftToPoll(pollRecord, options, tags, now)
+	if field, err := validate(&draft); err != nil {
+		if field == "" {
+			return database.Poll{}, err
+		}
+		return database.Poll{}, &pollValidationError{field: field, err: err}
+	}
+
+	pollRecord, err = updateDraft(ctx, qtx, pollID, draft, now)
+	if err != nil {
+		return database.Poll{}, err
+	}
+
+	return pollRecord, tx.Commit()
+}
+
+// updateDraft writes a draft's settings and schedule. The status follows
+// draft.Status, so a validated poll with no status is published.
+func updateDraft(ctx context.Context, qtx *database.Queries, pollID uuid.UUID, draft poll, now time.Time) (database.Poll, error) {
+	startsAt, expiresAt, status, err := pollSchedule(draft, now)
+	if err != nil {
+		return database.Poll{}, err
+	}
+	return qtx.UpdateDraft(ctx, database.UpdateDraftParams{
+		ID:                  pollID,
+		Title:               draft.Title,
+		Description:         draft.Description,
+		Category:            draft.Category,
+		Status:              status,
+		StartsAt:            startsAt,
+		ExpiresAt:           expiresAt,
+		MaxChoices:          draft.MaxChoices,
+		PollType:            database.PollType(draft.Type),
+		RatingMax:           draft.RatingMax,
+		VotesLocked:         draft.LockVotes,
+		AllowGuestVotes:     draft.AllowGuestVotes,
+		Visibility:          database.PollVisibility(draft.Visibility),
+		ResultsVisibility:   database.ResultsVisibility(draft.ResultsVisibility),
+		TieBreak:            database.TieBreak(draft.TieBreak),
+		Quorum:              quorumValue(draft.Quorum),
+		QuorumExtensionDays: quorumValue(draft.QuorumExtensionDays),
+	})
+}
+
+// SetPollAllowlist replaces the users and emails allowed to see a private
+// poll. Only the creator and admins may change it.
+func SetPollAllowlist(ctx context.Context, cfg *config.APIConfig, pollID, userID uuid.UUID, isAdmin bool, allowlist []string) ([]database.PollAllowlist, error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	_, err = lockPollForChange(ctx, qtx, pollID, userID, isAdmin, permissionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	err = qtx.DeletePollAllowlist(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+	err = allowPollViewers(ctx, qtx, pollID, allowlist)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := qtx.GetPollAllowlist(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// SetPollEditors replaces a poll's co-editors. Only the creator and admins
+// may change them.
+func SetPollEditors(ctx context.Context, cfg *config.APIConfig, pollID, userID uuid.UUID, isAdmin bool, editors []uuid.UUID) ([]database.PollEditor, error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	_, err = lockPollForChange(ctx, qtx, pollID, userID, isAdmin, permissionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	err = qtx.DeletePollEditors(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+	err = addPollEditors(ctx, qtx, pollID, editors)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := qtx.GetPollEditors(ctx, pollID)
+	if err != nil {
+		return nil, err
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// addPollEditors adds normalized co-editors to a poll.
+func addPollEditors(ctx context.Context, qtx *database.Queries, pollID uuid.UUID, editors []uuid.UUID) error {
+	err := qtx.AddPollEditors(ctx, database.AddPollEditorsParams{
+		PollID:  pollID,
+		UserIds: editors,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return ErrUnknownEditor
+		}
+		return err
+	}
 	return nil
 }
 
-func CreateVoteAndUpdateOptionCount(ctx context.Context, cfg *config.APIConfig, userID, optionID, pollID uuid.UUID) (vote database.Vote, err error) {
+// allowPollViewers adds a normalized allowlist to a poll.
+func allowPollViewers(ctx context.Context, qtx *database.Queries, pollID uuid.UUID, allowlist []string) error {
+	userIDs, emails := splitAllowlist(allowlist)
+	err := qtx.AddPollAllowlistUsers(ctx, database.AddPollAllowlistUsersParams{
+		PollID:  pollID,
+		UserIds: userIDs,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return ErrUnknownAllowlistUser
+		}
+		return err
+	}
+	return qtx.AddPollAllowlistEmails(ctx, database.AddPollAllowlistEmailsParams{
+		PollID: pollID,
+		Emails: emails,
+	})
+}
+
+// tagPoll links a poll to tags that are already normalized.
+func tagPoll(ctx context.Context, qtx *database.Queries, pollID uuid.UUID, tags []string) error {
+	err := qtx.UpsertTags(ctx, tags)
+	if err != nil {
+		return err
+	}
+	return qtx.AddPollTags(ctx, database.AddPollTagsParams{
+		PollID: pollID,
+		Names:  tags,
+	})
+}
+
+// CreateVotesAndUpdateOptionCounts records a user's ballot for every selected
+// option and bumps each option's count in a single transaction. The ballot is
+// rejected if the user has already voted or selected more options than the
+// poll's max_choices allows. Ranked ballots are stored in the order given and
+// only the first preference is added to the option counts.
+func CreateVotesAndUpdateOptionCounts(ctx context.Context, cfg *config.APIConfig, voter Voter, pollID uuid.UUID, optionIDs []uuid.UUID) (votes []database.Vote, err error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	pollRecord, err := lockOpenPoll(ctx, qtx, pollID, voter)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := qtx.CountVoterVotesByPollID(ctx, database.CountVoterVotesByPollIDParams{
+		PollID:  pollID,
+		VoterID: voter.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, ErrAlreadyVoted
+	}
+
+	votes, err = castBallot(ctx, qtx, pollRecord, voter, optionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return votes, nil
+}
+
+// ChangeVotesAndUpdateOptionCounts replaces a user's ballot. The old votes are
+// removed and their option counts decremented in the same transaction that
+// stores the new ballot, so the counts never drift from the votes table.
+func ChangeVotesAndUpdateOptionCounts(ctx context.Context, cfg *config.APIConfig, voter Voter, pollID uuid.UUID, optionIDs []uuid.UUID) (votes []database.Vote, err error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	pollRecord, err := lockOpenPoll(ctx, qtx, pollID, voter)
+	if err != nil {
+		return nil, err
+	}
+	if pollRecord.VotesLocked {
+		return nil, ErrVotesLocked
+	}
+
+	removed, err := retractBallot(ctx, qtx, pollRecord, voter)
+	if err != nil {
+		return nil, err
+	}
+	if removed == 0 {
+		return nil, ErrNoVote
+	}
+
+	votes, err = castBallot(ctx, qtx, pollRecord, voter, optionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return votes, nil
+}
+
+// DeleteVotesAndUpdateOptionCounts retracts a user's ballot, votes or ratings,
+// and decrements the affected option counts in a single transaction.
+func DeleteVotesAndUpdateOptionCounts(ctx context.Context, cfg *config.APIConfig, voter Voter, pollID uuid.UUID) (err error) {
 	tx, err := cfg.DB.Begin()
 	if err != nil {
-		return database.Vote{}, err
+		return err
 	}
 	defer tx.Rollback()
 	qtx := cfg.Queries.WithTx(tx)
 
-	vote, err = qtx.CreateVote(ctx, database.CreateVoteParams{
-		UserID:   userID,
-		PollID:   pollID,
-		OptionID: optionID,
+	pollRecord, err := lockOpenPoll(ctx, qtx, pollID, voter)
+	if err != nil {
+		return err
+	}
+	if pollRecord.VotesLocked {
+		return ErrVotesLocked
+	}
+
+	removed, err := retractBallot(ctx, qtx, pollRecord, voter)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrNoVote
+	}
+
+	return tx.Commit()
+}
+
+// CreateRatingsAndUpdateOptionCounts stores a user's score for every option of
+// a rating poll in a single transaction. Each option's count tracks how many
+// ratings it has received.
+func CreateRatingsAndUpdateOptionCounts(ctx context.Context, cfg *config.APIConfig, voter Voter, pollID uuid.UUID, scores map[uuid.UUID]int32) (ratings []database.Rating, err error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	pollRecord, err := lockOpenPoll(ctx, qtx, pollID, voter)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := qtx.CountVoterRatingsByPollID(ctx, database.CountVoterRatingsByPollIDParams{
+		PollID:  pollID,
+		VoterID: voter.ID,
 	})
 	if err != nil {
-		return database.Vote{}, err
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, ErrAlreadyVoted
+	}
+
+	ratings, err = castRatings(ctx, qtx, pollRecord, voter, scores)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+// ChangeRatingsAndUpdateOptionCounts replaces a user's scores on a rating poll
+// in a single transaction.
+func ChangeRatingsAndUpdateOptionCounts(ctx context.Context, cfg *config.APIConfig, voter Voter, pollID uuid.UUID, scores map[uuid.UUID]int32) (ratings []database.Rating, err error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	pollRecord, err := lockOpenPoll(ctx, qtx, pollID, voter)
+	if err != nil {
+		return nil, err
+	}
+	if pollRecord.VotesLocked {
+		return nil, ErrVotesLocked
+	}
+
+	removed, err := retractBallot(ctx, qtx, pollRecord, voter)
+	if err != nil {
+		return nil, err
+	}
+	if removed == 0 {
+		return nil, ErrNoVote
 	}
 
-	_, err = qtx.UpdateOptionCount(ctx, optionID)
+	ratings, err = castRatings(ctx, qtx, pollRecord, voter, scores)
 	if err != nil {
-		return database.Vote{}, err
+		return nil, err
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return database.Vote{}, err
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+// lockOpenPoll locks the poll row for the rest of the transaction so
+// concurrent ballots are serialized, and checks the poll is still open and
+// accepts ballots from this voter.
+func lockOpenPoll(ctx context.Context, qtx *database.Queries, pollID uuid.UUID, voter Voter) (database.Poll, error) {
+	pollRecord, err := qtx.GetPollForVote(ctx, pollID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.Poll{}, ErrPollNotFound
+		}
+		return database.Poll{}, err
+	}
+	switch pollRecord.Status {
+	case database.PollStatusActive:
+	case database.PollStatusInactive, database.PollStatusDraft:
+		return database.Poll{}, ErrPollNotOpen
+	default:
+		return database.Poll{}, ErrPollClosed
+	}
+	if voter.Guest && !pollRecord.AllowGuestVotes {
+		return database.Poll{}, ErrGuestVotingDisabled
+	}
+	return pollRecord, nil
+}
+
+// castBallot validates and stores a standard or ranked ballot inside an open
+// transaction.
+func castBallot(ctx context.Context, qtx *database.Queries, pollRecord database.Poll, voter Voter, optionIDs []uuid.UUID) ([]database.Vote, error) {
+	if pollRecord.PollType == database.PollTypeRating {
+		return nil, ErrWrongBallot
 	}
 
-	return vote, nil
+	if len(optionIDs) > int(pollRecord.MaxChoices) {
+		return nil, ErrTooManyChoices
+	}
+
+	matched, err := qtx.CountOptionsInPoll(ctx, database.CountOptionsInPollParams{
+		PollID:  pollRecord.ID,
+		Column2: optionIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if matched != int64(len(optionIDs)) {
+		return nil, ErrInvalidOption
+	}
+
+	ranked := pollRecord.PollType == database.PollTypeRanked
+	votes := make([]database.Vote, 0, len(optionIDs))
+	for i, optionID := range optionIDs {
+		rank := sql.NullInt32{}
+		if ranked {
+			rank = sql.NullInt32{Int32: int32(i + 1), Valid: true}
+		}
+
+		if !ranked || i == 0 {
+			_, err = qtx.UpdateOptionCount(ctx, database.UpdateOptionCountParams{
+				ID:     optionID,
+				PollID: pollRecord.ID,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		vote, err := qtx.CreateVote(ctx, database.CreateVoteParams{
+			UserID:   voter.userID(),
+			GuestID:  voter.guestID(),
+			PollID:   pollRecord.ID,
+			OptionID: optionID,
+			Rank:     rank,
+		})
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, vote)
+	}
+
+	return votes, nil
+}
+
+// castRatings validates and stores a rating poll ballot inside an open
+// transaction.
+func castRatings(ctx context.Context, qtx *database.Queries, pollRecord database.Poll, voter Voter, scores map[uuid.UUID]int32) ([]database.Rating, error) {
+	if pollRecord.PollType != database.PollTypeRating {
+		return nil, ErrWrongBallot
+	}
+
+	options, err := qtx.GetOptionsByPollIDs(ctx, []uuid.UUID{pollRecord.ID})
+	if err != nil {
+		return nil, err
+	}
+	if len(options) != len(scores) {
+		return nil, ErrUnratedOption
+	}
+	for _, option := range options {
+		if _, ok := scores[option.ID]; !ok {
+			return nil, ErrInvalidOption
+		}
+	}
+
+	ratings := make([]database.Rating, 0, len(options))
+	for _, option := range options {
+		score := scores[option.ID]
+		if score < 1 || score > pollRecord.RatingMax {
+			return nil, ErrInvalidScore
+		}
+
+		rating, err := qtx.CreateRating(ctx, database.CreateRatingParams{
+			PollID:   pollRecord.ID,
+			OptionID: option.ID,
+			UserID:   voter.userID(),
+			GuestID:  voter.guestID(),
+			Score:    score,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = qtx.UpdateOptionCount(ctx, database.UpdateOptionCountParams{
+			ID:     option.ID,
+			PollID: pollRecord.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, rating)
+	}
+
+	return ratings, nil
+}
+
+// retractBallot removes a user's votes or ratings inside an open transaction
+// and decrements every option count the ballot contributed to. It returns the
+// number of rows removed.
+func retractBallot(ctx context.Context, qtx *database.Queries, pollRecord database.Poll, voter Voter) (int, error) {
+	if pollRecord.PollType == database.PollTypeRating {
+		ratings, err := qtx.DeleteVoterRatingsByPollID(ctx, database.DeleteVoterRatingsByPollIDParams{
+			PollID:  pollRecord.ID,
+			VoterID: voter.ID,
+		})
+		if err != nil {
+			return 0, err
+		}
+		for _, rating := range ratings {
+			if err := qtx.DecrementOptionCount(ctx, rating.OptionID); err != nil {
+				return 0, err
+			}
+		}
+		return len(ratings), nil
+	}
+
+	votes, err := qtx.DeleteVoterVotesByPollID(ctx, database.DeleteVoterVotesByPollIDParams{
+		PollID:  pollRecord.ID,
+		VoterID: voter.ID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, vote := range votes {
+		// Ranked polls only count first preferences
+		if vote.Rank.Valid && vote.Rank.Int32 != 1 {
+			continue
+		}
+		if err := qtx.DecrementOptionCount(ctx, vote.OptionID); err != nil {
+			return 0, err
+		}
+	}
+	return len(votes), nil
+}
+
+// ClosePoll ends an active poll early. Ranked polls have their runoff frozen
+// and the outcome is recorded against the quorum the same way the expiry job
+// does, but a poll closed by hand is never extended.
+func ClosePoll(ctx context.Context, cfg *config.APIConfig, pollID, userID uuid.UUID, isAdmin bool) (database.Poll, error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return database.Poll{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	pollRecord, err := lockPollForChange(ctx, qtx, pollID, userID, isAdmin, permissionEdit)
+	if err != nil {
+		return database.Poll{}, err
+	}
+	if err := validateTransition(pollRecord.Status, database.PollStatusArchived); err != nil {
+		return database.Poll{}, err
+	}
+
+	if pollRecord.PollType == database.PollTypeRanked {
+		if err := tally.FreezeRanked(ctx, qtx, pollID); err != nil {
+			return database.Poll{}, err
+		}
+	}
+
+	outcome, err := tally.PollOutcome(ctx, qtx, pollRecord)
+	if err != nil {
+		return database.Poll{}, err
+	}
+
+	pollRecord, err = qtx.UpdatePollLifecycle(ctx, database.UpdatePollLifecycleParams{
+		ID:        pollID,
+		Status:    database.PollStatusArchived,
+		ExpiresAt: time.Now(),
+		Outcome:   database.NullPollOutcome{PollOutcome: outcome, Valid: true},
+	})
+	if err != nil {
+		return database.Poll{}, err
+	}
+
+	return pollRecord, tx.Commit()
+}
+
+// ReopenPoll makes an archived poll active again for the given number of
+// days, as long as it closed within the reopen grace window.
+func ReopenPoll(ctx context.Context, cfg *config.APIConfig, pollID, userID uuid.UUID, isAdmin bool, days int) (database.Poll, error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return database.Poll{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	pollRecord, err := lockPollForChange(ctx, qtx, pollID, userID, isAdmin, permissionEdit)
+	if err != nil {
+		return database.Poll{}, err
+	}
+	if err := validateTransition(pollRecord.Status, database.PollStatusActive); err != nil {
+		return database.Poll{}, err
+	}
+	if time.Since(pollRecord.ExpiresAt) > reopenGraceWindow {
+		return database.Poll{}, ErrReopenWindowPassed
+	}
+
+	// Ranked polls are counted live while they are open
+	if err := qtx.DeletePollResult(ctx, pollID); err != nil {
+		return database.Poll{}, err
+	}
+
+	pollRecord, err = qtx.UpdatePollLifecycle(ctx, database.UpdatePollLifecycleParams{
+		ID:        pollID,
+		Status:    database.PollStatusActive,
+		ExpiresAt: time.Now().Add(time.Duration(days) * 24 * time.Hour),
+	})
+	if err != nil {
+		return database.Poll{}, err
+	}
+
+	return pollRecord, tx.Commit()
+}
+
+// ExtendPoll pushes back the deadline of a poll that has not closed yet, see
+// extendedDeadline.
+func ExtendPoll(ctx context.Context, cfg *config.APIConfig, pollID, userID uuid.UUID, isAdmin bool, days int) (database.Poll, error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return database.Poll{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	pollRecord, err := lockPollForChange(ctx, qtx, pollID, userID, isAdmin, permissionEdit)
+	if err != nil {
+		return database.Poll{}, err
+	}
+	expiresAt, err := extendedDeadline(pollRecord, days, time.Now())
+	if err != nil {
+		return database.Poll{}, err
+	}
+
+	pollRecord, err = qtx.UpdatePollLifecycle(ctx, database.UpdatePollLifecycleParams{
+		ID:        pollID,
+		Status:    pollRecord.Status,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return database.Poll{}, err
+	}
+
+	return pollRecord, tx.Commit()
+}
+
+// extendedDeadline pushes a poll's deadline back by days. Drafts and closed
+// polls can't be extended, and the new deadline may be at most
+// maxLifecycleDays after the poll opens, or after now once it is open.
+func extendedDeadline(pollRecord database.Poll, days int, now time.Time) (time.Time, error) {
+	switch pollRecord.Status {
+	case database.PollStatusDraft:
+		return time.Time{}, ErrPollIsDraft
+	case database.PollStatusArchived:
+		return time.Time{}, fmt.Errorf("%w: closed polls must be reopened", ErrInvalidTransition)
+	}
+
+	from := pollRecord.StartsAt
+	if now.After(from) {
+		from = now
+	}
+	expiresAt := pollRecord.ExpiresAt.Add(time.Duration(days) * 24 * time.Hour)
+	if expiresAt.After(from.Add(maxLifecycleDays * 24 * time.Hour)) {
+		return time.Time{}, fmt.Errorf("%w: polls can't run more than %d days ahead", ErrInvalidTransition, maxLifecycleDays)
+	}
+	return expiresAt, nil
+}
+
+// lockPollForChange locks the poll row and checks the caller has permission
+// to change it, see authorizePollChange.
+func lockPollForChange(ctx context.Context, qtx *database.Queries, pollID, userID uuid.UUID, isAdmin bool, permission pollPermission) (database.Poll, error) {
+	pollRecord, err := qtx.GetPollForVote(ctx, pollID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.Poll{}, ErrPollNotFound
+		}
+		return database.Poll{}, err
+	}
+	if isHiddenDraft(pollRecord.Status, pollRecord.UserID, userID) {
+		return database.Poll{}, ErrPollNotFound
+	}
+	err = authorizePollChange(ctx, qtx, pollID, pollRecord.UserID, userID, isAdmin, permission)
+	if err != nil {
+		return database.Poll{}, err
+	}
+	return pollRecord, nil
+}
+
+// BreakTie records the creator's pick between a closed poll's tied leaders.
+// Only polls created with the creator tie-break policy accept a pick.
+func BreakTie(ctx context.Context, cfg *config.APIConfig, pollID, userID uuid.UUID, isAdmin bool, optionID uuid.UUID, leaders []string) (database.Poll, error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return database.Poll{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	pollRecord, err := lockPollForChange(ctx, qtx, pollID, userID, isAdmin, permissionManage)
+	if err != nil {
+		return database.Poll{}, err
+	}
+	if pollRecord.TieBreak != database.TieBreakCreator {
+		return database.Poll{}, ErrNoCreatorTieBreak
+	}
+	if pollRecord.Status != database.PollStatusArchived {
+		return database.Poll{}, ErrPollNotClosed
+	}
+	if pollRecord.Outcome.PollOutcome == database.PollOutcomeNoQuorum {
+		return database.Poll{}, ErrNoQuorum
+	}
+	if len(leaders) < 2 || !slices.Contains(leaders, optionID.String()) {
+		return database.Poll{}, ErrNotTiedLeader
+	}
+
+	pollRecord, err = qtx.SetPollTieWinner(ctx, database.SetPollTieWinnerParams{
+		ID:                pollID,
+		TieWinnerOptionID: uuid.NullUUID{UUID: optionID, Valid: true},
+	})
+	if err != nil {
+		return database.Poll{}, err
+	}
+
+	return pollRecord, tx.Commit()
+}
+
+// AddOptionToPoll appends an option to a poll that has not closed. Ranked
+// polls that let voters rank every option also raise max_choices to match.
+func AddOptionToPoll(ctx context.Context, cfg *config.APIConfig, pollID, userID uuid.UUID, isAdmin bool, name string) (database.Option, error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return database.Option{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	if _, err := lockEditablePoll(ctx, qtx, pollID, userID, isAdmin); err != nil {
+		return database.Option{}, err
+	}
+
+	option, err := qtx.CreateOption(ctx, database.CreateOptionParams{
+		PollID: pollID,
+		Name:   name,
+	})
+	if err != nil {
+		return database.Option{}, err
+	}
+	err = qtx.RaiseRankedMaxChoices(ctx, pollID)
+	if err != nil {
+		return database.Option{}, err
+	}
+
+	return option, tx.Commit()
+}
+
+// RenamePollOption renames an option. Once an option has votes only admins
+// can rename it, so ballots keep meaning what the voter saw.
+func RenamePollOption(ctx context.Context, cfg *config.APIConfig, pollID, optionID, userID uuid.UUID, isAdmin bool, name string) (database.Option, error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return database.Option{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	if _, err := lockEditablePoll(ctx, qtx, pollID, userID, isAdmin); err != nil {
+		return database.Option{}, err
+	}
+	if _, err := getUnvotedOption(ctx, qtx, pollID, optionID, isAdmin); err != nil {
+		return database.Option{}, err
+	}
+
+	option, err := qtx.RenameOption(ctx, database.RenameOptionParams{
+		ID:   optionID,
+		Name: name,
+	})
+	if err != nil {
+		return database.Option{}, err
+	}
+
+	return option, tx.Commit()
+}
+
+// ReorderPollOptions sets the display order of a poll's options. optionIDs
+// must list every option of the poll exactly once.
+func ReorderPollOptions(ctx context.Context, cfg *config.APIConfig, pollID, userID uuid.UUID, isAdmin bool, optionIDs []uuid.UUID) ([]database.Option, error) {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	if _, err := lockEditablePoll(ctx, qtx, pollID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	options, err := qtx.GetOptionsByPollIDs(ctx, []uuid.UUID{pollID})
+	if err != nil {
+		return nil, err
+	}
+	if len(options) != len(optionIDs) {
+		return nil, ErrOptionOrder
+	}
+	matched, err := qtx.CountOptionsInPoll(ctx, database.CountOptionsInPollParams{
+		PollID:  pollID,
+		Column2: optionIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if matched != int64(len(optionIDs)) {
+		return nil, ErrOptionOrder
+	}
+
+	for i, optionID := range optionIDs {
+		err := qtx.SetOptionPosition(ctx, database.SetOptionPositionParams{
+			ID:       optionID,
+			Position: int32(i + 1),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	options, err = qtx.GetOptionsByPollIDs(ctx, []uuid.UUID{pollID})
+	if err != nil {
+		return nil, err
+	}
+
+	return options, tx.Commit()
+}
+
+// RemovePollOption deletes an option. Once an option has votes only admins
+// can remove it, its votes are deleted with it and ranked ballots move their
+// next choice up. Voted options of rating polls can't be removed, their
+// raters scored every option.
+func RemovePollOption(ctx context.Context, cfg *config.APIConfig, pollID, optionID, userID uuid.UUID, isAdmin bool) error {
+	tx, err := cfg.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.Queries.WithTx(tx)
+
+	pollRecord, err := lockEditablePoll(ctx, qtx, pollID, userID, isAdmin)
+	if err != nil {
+		return err
+	}
+	mayRemoveVoted := isAdmin && pollRecord.PollType != database.PollTypeRating
+	if _, err := getUnvotedOption(ctx, qtx, pollID, optionID, mayRemoveVoted); err != nil {
+		return err
+	}
+
+	options, err := qtx.GetOptionsByPollIDs(ctx, []uuid.UUID{pollID})
+	if err != nil {
+		return err
+	}
+	if len(options) <= 1 {
+		return ErrLastOption
+	}
+
+	if err := qtx.DeleteOption(ctx, optionID); err != nil {
+		return err
+	}
+	if pollRecord.PollType == database.PollTypeRanked {
+		if err := qtx.RerankBallots(ctx, pollID); err != nil {
+			return err
+		}
+		// options.count holds first preferences, which the re-rank may have moved
+		if err := qtx.RecountOptions(ctx, pollID); err != nil {
+			return err
+		}
+	}
+	if err := qtx.ClampMaxChoices(ctx, pollID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockEditablePoll locks a poll the caller may edit, co-editors included.
+// Options of archived polls are frozen.
+func lockEditablePoll(ctx context.Context, qtx *database.Queries, pollID, userID uuid.UUID, isAdmin bool) (database.Poll, error) {
+	pollRecord, err := lockPollForChange(ctx, qtx, pollID, userID, isAdmin, permissionEdit)
+	if err != nil {
+		return database.Poll{}, err
+	}
+	if pollRecord.Status == database.PollStatusArchived {
+		return database.Poll{}, ErrPollClosed
+	}
+	return pollRecord, nil
+}
+
+// getUnvotedOption loads an option of the poll and checks nobody has voted
+// for it yet. Admins may edit options that already have votes. The poll row
+// must already be locked so no ballot can land between the check and the edit.
+func getUnvotedOption(ctx context.Context, qtx *database.Queries, pollID, optionID uuid.UUID, isAdmin bool) (database.Option, error) {
+	option, err := qtx.GetOptionForPoll(ctx, database.GetOptionForPollParams{
+		ID:     optionID,
+		PollID: pollID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.Option{}, ErrOptionNotFound
+		}
+		return database.Option{}, err
+	}
+	if isAdmin {
+		return option, nil
+	}
+	// Count misses the lower preferences of ranked ballots
+	hasVotes, err := qtx.OptionHasVotes(ctx, option.ID)
+	if err != nil {
+		return database.Option{}, err
+	}
+	if hasVotes {
+		return database.Option{}, ErrOptionHasVotes
+	}
+	return option, nil
 }
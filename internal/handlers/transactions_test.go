@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		from, to database.PollStatus
+		allowed  bool
+	}{
+		{database.PollStatusActive, database.PollStatusArchived, true},
+		{database.PollStatusArchived, database.PollStatusActive, true},
+		{database.PollStatusInactive, database.PollStatusActive, false},
+		{database.PollStatusInactive, database.PollStatusArchived, false},
+		{database.PollStatusActive, database.PollStatusActive, false},
+		{database.PollStatusArchived, database.PollStatusArchived, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			err := validateTransition(tt.from, tt.to)
+			if tt.allowed && err != nil {
+				t.Fatalf("expected transition to be allowed, got: %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrInvalidTransition) {
+				t.Fatalf("expected ErrInvalidTransition, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestPollSchedule(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	later := now.Add(48 * time.Hour)
+
+	tests := []struct {
+		name       string
+		poll       poll
+		wantStarts time.Time
+		wantEnds   time.Time
+		wantStatus database.PollStatus
+	}{
+		{name: "Opens now", poll: poll{ExpiresAt: "3"}, wantStarts: now, wantEnds: now.Add(72 * time.Hour), wantStatus: database.PollStatusActive},
+		{name: "Scheduled", poll: poll{ExpiresAt: "1", StartsAt: later.Format(time.RFC3339)}, wantStarts: later, wantEnds: later.Add(24 * time.Hour), wantStatus: database.PollStatusInactive},
+		{name: "Draft without expiry", poll: poll{Status: string(database.PollStatusDraft)}, wantStarts: now, wantEnds: now, wantStatus: database.PollStatusDraft},
+		{name: "Scheduled draft", poll: poll{Status: string(database.PollStatusDraft), ExpiresAt: "2", StartsAt: later.Format(time.RFC3339)}, wantStarts: later, wantEnds: later.Add(48 * time.Hour), wantStatus: database.PollStatusDraft},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startsAt, expiresAt, status, err := pollSchedule(tt.poll, now)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !startsAt.Equal(tt.wantStarts) || !expiresAt.Equal(tt.wantEnds) {
+				t.Fatalf("expected %v to %v, got %v to %v", tt.wantStarts, tt.wantEnds, startsAt, expiresAt)
+			}
+			if status != tt.wantStatus {
+				t.Fatalf("expected status %s, got %s", tt.wantStatus, status)
+			}
+		})
+	}
+
+	t.Run("Missing expiry", func(t *testing.T) {
+		if _, _, _, err := pollSchedule(poll{}, now); err == nil {
+			t.Fatalf("expected an error for a poll without expiresAt")
+		}
+	})
+}
+
+func TestExtendedDeadline(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name    string
+		poll    database.Poll
+		days    int
+		want    time.Time
+		wantErr error
+	}{
+		{
+			name: "Open poll",
+			poll: database.Poll{Status: database.PollStatusActive, StartsAt: now.Add(-day), ExpiresAt: now.Add(2 * day)},
+			days: 3,
+			want: now.Add(5 * day),
+		},
+		{
+			name:    "Open poll past the cap",
+			poll:    database.Poll{Status: database.PollStatusActive, StartsAt: now.Add(-day), ExpiresAt: now.Add(25 * day)},
+			days:    10,
+			wantErr: ErrInvalidTransition,
+		},
+		{
+			name: "Scheduled poll counts from its start",
+			poll: database.Poll{Status: database.PollStatusInactive, StartsAt: now.Add(10 * day), ExpiresAt: now.Add(30 * day)},
+			days: 10,
+			want: now.Add(40 * day),
+		},
+		{
+			name:    "Scheduled poll past the cap",
+			poll:    database.Poll{Status: database.PollStatusInactive, StartsAt: now.Add(10 * day), ExpiresAt: now.Add(30 * day)},
+			days:    11,
+			wantErr: ErrInvalidTransition,
+		},
+		{
+			name:    "Draft",
+			poll:    database.Poll{Status: database.PollStatusDraft, StartsAt: now, ExpiresAt: now.Add(day)},
+			days:    1,
+			wantErr: ErrPollIsDraft,
+		},
+		{
+			name:    "Closed poll",
+			poll:    database.Poll{Status: database.PollStatusArchived, StartsAt: now.Add(-2 * day), ExpiresAt: now.Add(-day)},
+			days:    1,
+			wantErr: ErrInvalidTransition,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extendedDeadline(tt.poll, tt.days, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
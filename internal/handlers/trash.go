@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// DeletedPoll is a poll in the trash. PurgeAt is when cron removes it for
+// good, until then an admin can restore it.
+type DeletedPoll struct {
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title"`
+	Category  string    `json:"category"`
+	Status    string    `json:"status"`
+	CreatorID uuid.UUID `json:"creatorId"`
+	Creator   string    `json:"creator"`
+	CreatedAt time.Time `json:"createdAt"`
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"`
+}
+
+// DeletedPollPage is one page of the trash
+type DeletedPollPage struct {
+	Polls      []DeletedPoll `json:"polls"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// ListDeletedPolls lists the trash to admins, most recently deleted first.
+func (h *pollHandler) ListDeletedPolls(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	limit, cursor, err := getPageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid limit or cursor", err)
+		return
+	}
+
+	rows, err := h.cfg.Queries.GetDeletedPolls(r.Context(), database.GetDeletedPollsParams{
+		CursorDeletedAt: cursor.at(),
+		CursorID:        cursor.id(),
+		PageSize:        int32(limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to retrieve deleted polls", err)
+		return
+	}
+	rows, next := nextCursor(rows, limit, func(row database.GetDeletedPollsRow) pageCursor {
+		return pageCursor{At: row.Deletedat, ID: row.Pollid}
+	})
+
+	polls := make([]DeletedPoll, len(rows))
+	for i, row := range rows {
+		polls[i] = DeletedPoll{
+			ID:        row.Pollid,
+			Title:     row.Title,
+			Category:  row.Category,
+			Status:    string(row.Status),
+			CreatorID: row.Creatorid,
+			Creator:   row.Creatorfirstname + " " + row.Creatorlastname.String,
+			CreatedAt: row.Createdat,
+			DeletedAt: row.Deletedat,
+			PurgeAt:   row.Deletedat.Add(h.cfg.DeletedPollRetention),
+		}
+	}
+	respondWithJSON(w, http.StatusOK, DeletedPollPage{Polls: polls, NextCursor: next})
+}
+
+// RestorePoll takes a poll out of the trash with its options, votes and
+// comments, and returns it.
+func (h *pollHandler) RestorePoll(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+
+	restored, err := h.cfg.Queries.RestorePoll(r.Context(), pollUUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Internal server error", err)
+		return
+	}
+	if restored == 0 {
+		respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "Poll is not in the trash", nil)
+		return
+	}
+
+	h.respondWithUpdatedPoll(w, r, pollUUID, userUUID, true)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/GhostVox/ghostvox.io-backend/internal/config"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestListDeletedPolls(t *testing.T) {
+	deletedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+	first, second := uuid.New(), uuid.New()
+	row := func(id uuid.UUID, deletedAt time.Time) []driver.Value {
+		return []driver.Value{id.String(), "Best tacos", "food", "Active", uuid.NewString(), "Ada", "Lovelace", deletedAt.Add(-time.Hour), deletedAt}
+	}
+
+	// The query fetches one row past the limit to tell whether a next page exists
+	db := newFakeDB(t, map[string]fakeResult{
+		"GetDeletedPolls": {
+			columns: []string{"pollid", "title", "category", "status", "creatorid", "creatorfirstname", "creatorlastname", "createdat", "deletedat"},
+			rows:    [][]driver.Value{row(first, deletedAt), row(second, deletedAt.Add(-time.Hour))},
+		},
+	})
+	h := &pollHandler{cfg: &config.APIConfig{DB: db, Queries: database.New(db), DeletedPollRetention: retention}}
+
+	rr := httptest.NewRecorder()
+	h.ListDeletedPolls(rr, httptest.NewRequest("GET", "/api/v1/admin/polls/trash?limit=1", nil), &auth.CustomClaims{Role: "admin"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var page DeletedPollPage
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+		t.Fatalf("expected a page of deleted polls, got: %v", err)
+	}
+	if len(page.Polls) != 1 || page.Polls[0].ID != first {
+		t.Fatalf("expected only poll %s, got %+v", first, page.Polls)
+	}
+	if !page.Polls[0].PurgeAt.Equal(deletedAt.Add(retention)) {
+		t.Fatalf("expected the poll to be purged at %v, got %v", deletedAt.Add(retention), page.Polls[0].PurgeAt)
+	}
+	cursor, err := decodeCursor(page.NextCursor)
+	if err != nil {
+		t.Fatalf("expected a next cursor, got %q: %v", page.NextCursor, err)
+	}
+	if cursor.ID != first || !cursor.At.Equal(deletedAt) {
+		t.Fatalf("expected the cursor to point after poll %s deleted at %v, got %+v", first, deletedAt, cursor)
+	}
+}
+
+func TestRestorePoll(t *testing.T) {
+	tests := []struct {
+		name   string
+		pollID string
+		want   int
+	}{
+		{name: "Invalid poll ID", pollID: "not-a-uuid", want: http.StatusBadRequest},
+		{name: "Poll not in the trash", pollID: uuid.NewString(), want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB(t, map[string]fakeResult{
+				"RestorePoll": {rowsAffected: 0},
+			})
+			h := &pollHandler{cfg: &config.APIConfig{DB: db, Queries: database.New(db)}}
+
+			req := httptest.NewRequest("POST", "/api/v1/admin/polls/"+tt.pollID+"/restore", nil)
+			req.SetPathValue("pollId", tt.pollID)
+			claims := &auth.CustomClaims{Role: "admin"}
+			claims.Subject = uuid.NewString()
+			rr := httptest.NewRecorder()
+			h.RestorePoll(rr, req, claims)
+			if rr.Code != tt.want {
+				t.Fatalf("expected status %d, got %d: %s", tt.want, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
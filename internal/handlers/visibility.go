@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/GhostVox/ghostvox.io-backend/internal/config"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// ErrUnknownAllowlistUser is returned when an allowlist names a user ID that
+// does not exist.
+var ErrUnknownAllowlistUser = errors.New("allowlist names an unknown user")
+
+const (
+	// shareTokenParam is the query parameter carrying an unlisted poll's share token
+	shareTokenParam = "share"
+	// maxAllowlistEntries caps how many users and emails a private poll can list
+	maxAllowlistEntries = 200
+)
+
+// Allowlist is the body and response of the poll allowlist endpoints. Each
+// entry is a user ID or an email address.
+type Allowlist struct {
+	Allowlist []string `json:"allowlist"`
+}
+
+// GetAllowlist lists who may see a private poll. Only the creator and admins
+// can read it.
+func (h *pollHandler) GetAllowlist(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+
+	err := h.authorizePollAccess(r.Context(), pollUUID, userUUID, claims.Role == "admin", permissionManage)
+	if err != nil {
+		respondWithLifecycleError(w, err)
+		return
+	}
+
+	entries, err := h.cfg.Queries.GetPollAllowlist(r.Context(), pollUUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to retrieve allowlist", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toAllowlist(entries))
+}
+
+// SetAllowlist replaces the users and emails allowed to see a private poll.
+func (h *pollHandler) SetAllowlist(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, userUUID, ok := parseLifecycleIDs(w, r, claims)
+	if !ok {
+		return
+	}
+
+	var req Allowlist
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid request body", err)
+		return
+	}
+	allowlist, err := normalizeAllowlist(req.Allowlist)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "allowlist", err.Error(), err)
+		return
+	}
+
+	entries, err := SetPollAllowlist(r.Context(), h.cfg, pollUUID, userUUID, claims.Role == "admin", allowlist)
+	if err != nil {
+		respondWithAllowlistError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toAllowlist(entries))
+}
+
+// authorizePollView checks viewer may see a poll. Public polls are open to
+// everyone, unlisted polls need the share token and private polls an
+// allowlist entry. Creators and admins always pass, except that drafts are
+// only shown to their creator. Hidden polls are reported
+// as ErrPollNotFound so their existence does not leak.
+func authorizePollView(ctx context.Context, cfg *config.APIConfig, pollID uuid.UUID, viewer uuid.NullUUID, isAdmin bool, shareToken string) (database.GetPollAccessRow, error) {
+	access, err := cfg.Queries.GetPollAccess(ctx, database.GetPollAccessParams{
+		ViewerID: viewer,
+		PollID:   pollID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.GetPollAccessRow{}, ErrPollNotFound
+		}
+		return database.GetPollAccessRow{}, err
+	}
+
+	shared := auth.ValidateShareToken(shareToken, pollID, cfg.GhostvoxSecretKey)
+	if !canViewPoll(access, viewer, isAdmin, shared) {
+		return database.GetPollAccessRow{}, ErrPollNotFound
+	}
+	return access, nil
+}
+
+func canViewPoll(access database.GetPollAccessRow, viewer uuid.NullUUID, isAdmin, shared bool) bool {
+	if access.Status == database.PollStatusDraft {
+		return viewer.Valid && !isHiddenDraft(access.Status, access.Ownerid, viewer.UUID)
+	}
+	if isAdmin || (viewer.Valid && viewer.UUID == access.Ownerid) {
+		return true
+	}
+	switch access.Visibility {
+	case database.PollVisibilityPublic:
+		return true
+	case database.PollVisibilityUnlisted:
+		return shared
+	case database.PollVisibilityPrivate:
+		return access.Allowed
+	default:
+		return false
+	}
+}
+
+// resultsVisible reports whether viewer may see a poll's counts and winner.
+// Creators and admins always can, other viewers once the poll's results
+// visibility allows it.
+func resultsVisible(row pollRow, viewer uuid.UUID, isAdmin bool) bool {
+	if isAdmin || viewer == row.CreatorID {
+		return true
+	}
+	closed := row.Status == database.PollStatusArchived
+	switch row.ResultsVisibility {
+	case database.ResultsVisibilityAfterVote:
+		voted := len(row.UserVote) > 0 || len(row.UserRatings) > 0
+		return voted || closed
+	case database.ResultsVisibilityAfterClose:
+		return closed
+	default:
+		return true
+	}
+}
+
+// viewerFromClaims returns the signed in caller, or no viewer for guests.
+func viewerFromClaims(claims *auth.CustomClaims) (uuid.NullUUID, bool) {
+	if claims == nil {
+		return uuid.NullUUID{}, false
+	}
+	userUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.NullUUID{}, false
+	}
+	return uuid.NullUUID{UUID: userUUID, Valid: true}, claims.Role == "admin"
+}
+
+// parseVisibility validates a poll's visibility, public when empty.
+func parseVisibility(visibility string) (database.PollVisibility, error) {
+	switch database.PollVisibility(visibility) {
+	case "":
+		return database.PollVisibilityPublic, nil
+	case database.PollVisibilityPublic, database.PollVisibilityUnlisted, database.PollVisibilityPrivate:
+		return database.PollVisibility(visibility), nil
+	default:
+		return "", errors.New("visibility must be public, unlisted or private")
+	}
+}
+
+// parseResultsVisibility validates when a poll's results are shown, always
+// when empty.
+func parseResultsVisibility(visibility string) (database.ResultsVisibility, error) {
+	switch database.ResultsVisibility(visibility) {
+	case "":
+		return database.ResultsVisibilityAlways, nil
+	case database.ResultsVisibilityAlways, database.ResultsVisibilityAfterVote, database.ResultsVisibilityAfterClose:
+		return database.ResultsVisibility(visibility), nil
+	default:
+		return "", errors.New("resultsVisibility must be always, after_vote or after_close")
+	}
+}
+
+// normalizeAllowlist checks every entry is a user ID or an email address and
+// returns them in canonical form, emails lowercased, without duplicates.
+func normalizeAllowlist(raw []string) ([]string, error) {
+	if len(raw) > maxAllowlistEntries {
+		return nil, fmt.Errorf("An allowlist can have at most %d entries", maxAllowlistEntries)
+	}
+
+	seen := make(map[string]bool, len(raw))
+	allowlist := make([]string, 0, len(raw))
+	for _, entry := range raw {
+		entry = strings.TrimSpace(entry)
+		if userUUID, err := uuid.Parse(entry); err == nil {
+			entry = userUUID.String()
+		} else if address, err := mail.ParseAddress(entry); err == nil && address.Address == entry {
+			entry = strings.ToLower(entry)
+		} else {
+			return nil, fmt.Errorf("%q is not a user ID or an email address", entry)
+		}
+		if !seen[entry] {
+			seen[entry] = true
+			allowlist = append(allowlist, entry)
+		}
+	}
+	return allowlist, nil
+}
+
+// splitAllowlist separates a normalized allowlist into user IDs and emails.
+func splitAllowlist(allowlist []string) ([]uuid.UUID, []string) {
+	userIDs := []uuid.UUID{}
+	emails := []string{}
+	for _, entry := range allowlist {
+		if userUUID, err := uuid.Parse(entry); err == nil {
+			userIDs = append(userIDs, userUUID)
+		} else {
+			emails = append(emails, entry)
+		}
+	}
+	return userIDs, emails
+}
+
+func toAllowlist(entries []database.PollAllowlist) Allowlist {
+	allowlist := Allowlist{Allowlist: make([]string, len(entries))}
+	for i, entry := range entries {
+		if entry.UserID.Valid {
+			allowlist.Allowlist[i] = entry.UserID.UUID.String()
+		} else {
+			allowlist.Allowlist[i] = entry.Email.String
+		}
+	}
+	return allowlist
+}
+
+func respondWithAllowlistError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrUnknownAllowlistUser) {
+		respondWithError(w, http.StatusBadRequest, "allowlist", "Allowlist names a user that does not exist", err)
+		return
+	}
+	respondWithLifecycleError(w, err)
+}
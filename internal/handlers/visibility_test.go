@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestCanViewPoll(t *testing.T) {
+	owner := uuid.New()
+	stranger := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	guest := uuid.NullUUID{}
+
+	tests := []struct {
+		name       string
+		status     database.PollStatus
+		visibility database.PollVisibility
+		viewer     uuid.NullUUID
+		isAdmin    bool
+		shared     bool
+		allowed    bool
+		want       bool
+	}{
+		{name: "Public to guests", visibility: database.PollVisibilityPublic, viewer: guest, want: true},
+		{name: "Unlisted without token", visibility: database.PollVisibilityUnlisted, viewer: stranger, want: false},
+		{name: "Unlisted with token", visibility: database.PollVisibilityUnlisted, viewer: guest, shared: true, want: true},
+		{name: "Unlisted to creator", visibility: database.PollVisibilityUnlisted, viewer: uuid.NullUUID{UUID: owner, Valid: true}, want: true},
+		{name: "Private to stranger", visibility: database.PollVisibilityPrivate, viewer: stranger, want: false},
+		{name: "Private with token", visibility: database.PollVisibilityPrivate, viewer: stranger, shared: true, want: false},
+		{name: "Private to allowlisted user", visibility: database.PollVisibilityPrivate, viewer: stranger, allowed: true, want: true},
+		{name: "Private to admin", visibility: database.PollVisibilityPrivate, viewer: stranger, isAdmin: true, want: true},
+		{name: "Draft to creator", status: database.PollStatusDraft, visibility: database.PollVisibilityPublic, viewer: uuid.NullUUID{UUID: owner, Valid: true}, want: true},
+		{name: "Draft to admin", status: database.PollStatusDraft, visibility: database.PollVisibilityPublic, viewer: stranger, isAdmin: true, want: false},
+		{name: "Draft to guest", status: database.PollStatusDraft, visibility: database.PollVisibilityPublic, viewer: guest, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			access := database.GetPollAccessRow{Ownerid: owner, Visibility: tt.visibility, Status: tt.status, Allowed: tt.allowed}
+			if got := canViewPoll(access, tt.viewer, tt.isAdmin, tt.shared); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseVisibility(t *testing.T) {
+	if visibility, err := parseVisibility(""); err != nil || visibility != database.PollVisibilityPublic {
+		t.Fatalf("expected public by default, got %q (%v)", visibility, err)
+	}
+	if visibility, err := parseVisibility("unlisted"); err != nil || visibility != database.PollVisibilityUnlisted {
+		t.Fatalf("expected unlisted, got %q (%v)", visibility, err)
+	}
+	if _, err := parseVisibility("hidden"); err == nil {
+		t.Fatalf("expected an error for an unknown visibility")
+	}
+}
+
+func TestNormalizeAllowlist(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("User IDs and emails", func(t *testing.T) {
+		allowlist, err := normalizeAllowlist([]string{userID.String(), " Alice@Example.com", "alice@example.com"})
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if !reflect.DeepEqual(allowlist, []string{userID.String(), "alice@example.com"}) {
+			t.Fatalf("expected [%s alice@example.com], got %v", userID, allowlist)
+		}
+
+		userIDs, emails := splitAllowlist(allowlist)
+		if len(userIDs) != 1 || userIDs[0] != userID || !reflect.DeepEqual(emails, []string{"alice@example.com"}) {
+			t.Fatalf("expected one user ID and one email, got %v and %v", userIDs, emails)
+		}
+	})
+
+	t.Run("Invalid entry", func(t *testing.T) {
+		for _, entry := range []string{"alice", "Alice <alice@example.com>", ""} {
+			if _, err := normalizeAllowlist([]string{entry}); err == nil {
+				t.Fatalf("expected an error for %q", entry)
+			}
+		}
+	})
+}
+
+func TestResultsVisible(t *testing.T) {
+	creator := uuid.New()
+	voter := uuid.New()
+	active := database.PollStatusActive
+	archived := database.PollStatusArchived
+
+	tests := []struct {
+		name    string
+		mode    database.ResultsVisibility
+		status  database.PollStatus
+		viewer  uuid.UUID
+		isAdmin bool
+		voted   bool
+		want    bool
+	}{
+		{name: "Always", mode: database.ResultsVisibilityAlways, status: active, viewer: voter, want: true},
+		{name: "After vote before voting", mode: database.ResultsVisibilityAfterVote, status: active, viewer: voter, want: false},
+		{name: "After vote once voted", mode: database.ResultsVisibilityAfterVote, status: active, viewer: voter, voted: true, want: true},
+		{name: "After vote once closed", mode: database.ResultsVisibilityAfterVote, status: archived, viewer: voter, want: true},
+		{name: "After close while open", mode: database.ResultsVisibilityAfterClose, status: active, viewer: voter, voted: true, want: false},
+		{name: "After close once closed", mode: database.ResultsVisibilityAfterClose, status: archived, viewer: voter, want: true},
+		{name: "Creator while open", mode: database.ResultsVisibilityAfterClose, status: active, viewer: creator, want: true},
+		{name: "Admin while open", mode: database.ResultsVisibilityAfterClose, status: active, viewer: voter, isAdmin: true, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := pollRow{PollDetail: database.PollDetail{Status: tt.status, ResultsVisibility: tt.mode, CreatorID: creator}}
+			if tt.voted {
+				row.UserVote = []uuid.UUID{uuid.New()}
+			}
+			if got := resultsVisible(row, tt.viewer, tt.isAdmin); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
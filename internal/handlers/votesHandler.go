@@ -1,18 +1,31 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
 	"github.com/GhostVox/ghostvox.io-backend/internal/config"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
 	"github.com/google/uuid"
 )
 
 type Vote struct {
-	PollId   string       `json:"pollId"`
-	OptionId string       `json:"optionId"`
-	UserId   string       `json:"userId"`
-	Poll     PollResponse `json:"poll"`
+	PollId    string        `json:"pollId"`
+	OptionId  string        `json:"optionId"`
+	OptionIds []string      `json:"optionIds"`
+	Ratings   []OptionScore `json:"ratings"`
+	Poll      PollResponse  `json:"poll"`
+}
+
+// OptionScore is a voter's score for one option of a rating poll
+type OptionScore struct {
+	OptionId string `json:"optionId"`
+	Score    int32  `json:"score"`
 }
 
 type voteHandler struct {
@@ -25,7 +38,9 @@ func NewVoteHandler(db *config.APIConfig) *voteHandler {
 	}
 }
 
-func (vh *voteHandler) VoteOnPoll(w http.ResponseWriter, r *http.Request) {
+// VoteOnPoll casts the caller's ballot. Signed in users vote as themselves,
+// logged-out visitors may vote as a guest on polls that allow it.
+func (vh *voteHandler) VoteOnPoll(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
 
 	// get the poll ID
 	pollId := r.PathValue("pollId")
@@ -43,26 +58,301 @@ func (vh *voteHandler) VoteOnPoll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	optionUUID, err := uuid.Parse(vote.OptionId)
+	voter, err := vh.resolveVoter(w, r, pollUUID, claims)
+	if err != nil {
+		respondWithVoteError(w, err)
+		return
+	}
+
+	err = vh.authorizeVoter(r, pollUUID, voter, claims)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid option ID format", err)
+		respondWithVoteError(w, err)
 		return
 	}
 
-	userUUID, err := uuid.Parse(vote.UserId)
+	// Rating polls take a score per option instead of a list of choices
+	if len(vote.Ratings) > 0 {
+		scores, err := parseRatings(vote)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "ratings", err.Error(), err)
+			return
+		}
+
+		_, err = CreateRatingsAndUpdateOptionCounts(r.Context(), vh.cfg, voter, pollUUID, scores)
+		if err != nil {
+			respondWithVoteError(w, err)
+			return
+		}
+		vote.Poll.Votes++
+		vote.Poll.UserRatings = make(map[string]int32, len(scores))
+		for optionUUID, score := range scores {
+			vote.Poll.UserRatings[optionUUID.String()] = score
+		}
+
+		respondWithJSON(w, http.StatusCreated, vote.Poll)
+		return
+	}
+
+	optionUUIDs, err := parseOptionIDs(vote)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid user ID format", err)
+		respondWithError(w, http.StatusBadRequest, "optionIds", err.Error(), err)
 		return
 	}
 
-	voteRecord, err := CreateVoteAndUpdateOptionCount(r.Context(), vh.cfg, userUUID, optionUUID, pollUUID)
+	votes, err := CreateVotesAndUpdateOptionCounts(r.Context(), vh.cfg, voter, pollUUID, optionUUIDs)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to create vote", err)
+		respondWithVoteError(w, err)
 		return
 	}
-	vote.Poll.Votes++
-	vote.Poll.UserVote = uuid.NullUUID{UUID: voteRecord.ID, Valid: true}
+	vote.Poll.Votes += ballotVotes(votes)
+	vote.Poll.UserVote = optionUUIDs
 
 	respondWithJSON(w, http.StatusCreated, vote.Poll)
 
 }
+
+// ChangeVote replaces the caller's ballot on an active poll. The body takes the
+// same shape as VoteOnPoll.
+func (vh *voteHandler) ChangeVote(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, err := uuid.Parse(r.PathValue("pollId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid poll ID format", err)
+		return
+	}
+
+	voter, err := vh.resolveVoter(w, r, pollUUID, claims)
+	if err != nil {
+		respondWithVoteError(w, err)
+		return
+	}
+
+	err = vh.authorizeVoter(r, pollUUID, voter, claims)
+	if err != nil {
+		respondWithVoteError(w, err)
+		return
+	}
+
+	var vote Vote
+	err = json.NewDecoder(r.Body).Decode(&vote)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid request body", err)
+		return
+	}
+
+	if len(vote.Ratings) > 0 {
+		scores, err := parseRatings(vote)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "ratings", err.Error(), err)
+			return
+		}
+
+		_, err = ChangeRatingsAndUpdateOptionCounts(r.Context(), vh.cfg, voter, pollUUID, scores)
+		if err != nil {
+			respondWithVoteError(w, err)
+			return
+		}
+		vote.Poll.UserRatings = make(map[string]int32, len(scores))
+		for optionUUID, score := range scores {
+			vote.Poll.UserRatings[optionUUID.String()] = score
+		}
+
+		respondWithJSON(w, http.StatusOK, vote.Poll)
+		return
+	}
+
+	optionUUIDs, err := parseOptionIDs(vote)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "optionIds", err.Error(), err)
+		return
+	}
+
+	votes, err := ChangeVotesAndUpdateOptionCounts(r.Context(), vh.cfg, voter, pollUUID, optionUUIDs)
+	if err != nil {
+		respondWithVoteError(w, err)
+		return
+	}
+	// The replaced ballot counted its first preference only on ranked polls
+	previous := int64(len(vote.Poll.UserVote))
+	if previous > 0 && len(votes) > 0 && votes[0].Rank.Valid {
+		previous = 1
+	}
+	vote.Poll.Votes += ballotVotes(votes) - previous
+	vote.Poll.UserVote = optionUUIDs
+
+	respondWithJSON(w, http.StatusOK, vote.Poll)
+}
+
+// RetractVote removes the caller's ballot from an active poll.
+func (vh *voteHandler) RetractVote(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+	pollUUID, err := uuid.Parse(r.PathValue("pollId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), "Invalid poll ID format", err)
+		return
+	}
+
+	voter, err := vh.resolveVoter(w, r, pollUUID, claims)
+	if err != nil {
+		respondWithVoteError(w, err)
+		return
+	}
+
+	err = vh.authorizeVoter(r, pollUUID, voter, claims)
+	if err != nil {
+		respondWithVoteError(w, err)
+		return
+	}
+
+	err = DeleteVotesAndUpdateOptionCounts(r.Context(), vh.cfg, voter, pollUUID)
+	if err != nil {
+		respondWithVoteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resolveVoter identifies who is voting. Logged-out visitors get a signed
+// guest voter cookie that is reused on later requests. The cookie is only
+// handed out to a first ballot on a poll that accepts guests, a guest without
+// one has no ballot to change or retract.
+func (vh *voteHandler) resolveVoter(w http.ResponseWriter, r *http.Request, pollID uuid.UUID, claims *auth.CustomClaims) (Voter, error) {
+	if claims != nil {
+		userUUID, err := uuid.Parse(claims.Subject)
+		if err != nil {
+			return Voter{}, fmt.Errorf("%w: %v", ErrInvalidSession, err)
+		}
+		return Voter{ID: userUUID}, nil
+	}
+
+	if cookie, err := r.Cookie(auth.GuestVoterCookie); err == nil {
+		guestID, err := auth.ValidateGuestToken(cookie.Value, vh.cfg.GhostvoxSecretKey)
+		if err == nil {
+			return Voter{ID: guestID, Guest: true}, nil
+		}
+	}
+
+	if r.Method != http.MethodPost {
+		return Voter{}, ErrNoGuestSession
+	}
+	poll, err := vh.cfg.Queries.GetPoll(r.Context(), pollID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Voter{}, ErrPollNotFound
+		}
+		return Voter{}, err
+	}
+	if !poll.AllowGuestVotes {
+		return Voter{}, ErrGuestVotingDisabled
+	}
+
+	guestID := uuid.New()
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.GuestVoterCookie,
+		Value:    auth.MakeGuestToken(guestID, vh.cfg.GhostvoxSecretKey),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+		Path:     "/",
+		Domain:   vh.cfg.DOMAIN,
+		Expires:  time.Now().Add(365 * 24 * time.Hour),
+	})
+	return Voter{ID: guestID, Guest: true}, nil
+}
+
+// authorizeVoter checks the voter can see the poll. Guests can vote on public
+// polls and on unlisted polls they hold the share token for.
+func (vh *voteHandler) authorizeVoter(r *http.Request, pollID uuid.UUID, voter Voter, claims *auth.CustomClaims) error {
+	isAdmin := claims != nil && claims.Role == "admin"
+	_, err := authorizePollView(r.Context(), vh.cfg, pollID, voter.userID(), isAdmin, r.URL.Query().Get(shareTokenParam))
+	return err
+}
+
+// parseOptionIDs collects the selected options from a vote body. Older clients
+// send a single optionId, multi-select clients send optionIds.
+func parseOptionIDs(vote Vote) ([]uuid.UUID, error) {
+	ids := vote.OptionIds
+	if len(ids) == 0 && vote.OptionId != "" {
+		ids = []string{vote.OptionId}
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("At least one option is required")
+	}
+
+	seen := make(map[uuid.UUID]bool, len(ids))
+	optionUUIDs := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		optionUUID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, errors.New("Invalid option ID format")
+		}
+		if seen[optionUUID] {
+			return nil, errors.New("Duplicate option selected")
+		}
+		seen[optionUUID] = true
+		optionUUIDs = append(optionUUIDs, optionUUID)
+	}
+	return optionUUIDs, nil
+}
+
+// ballotVotes is how many of a ballot's votes count toward the poll's total.
+// Like options.count, ranked ballots only count their first preference.
+func ballotVotes(votes []database.Vote) int64 {
+	var count int64
+	for _, vote := range votes {
+		if !vote.Rank.Valid || vote.Rank.Int32 == 1 {
+			count++
+		}
+	}
+	return count
+}
+
+// parseRatings collects the per option scores from a rating poll vote body.
+func parseRatings(vote Vote) (map[uuid.UUID]int32, error) {
+	scores := make(map[uuid.UUID]int32, len(vote.Ratings))
+	for _, rating := range vote.Ratings {
+		optionUUID, err := uuid.Parse(rating.OptionId)
+		if err != nil {
+			return nil, errors.New("Invalid option ID format")
+		}
+		if _, ok := scores[optionUUID]; ok {
+			return nil, errors.New("Duplicate option rated")
+		}
+		scores[optionUUID] = rating.Score
+	}
+	return scores, nil
+}
+
+func respondWithVoteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrPollNotFound):
+		respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "Poll not found", err)
+	case errors.Is(err, ErrTooManyChoices):
+		respondWithError(w, http.StatusBadRequest, "optionIds", "Too many options selected", err)
+	case errors.Is(err, ErrInvalidOption):
+		respondWithError(w, http.StatusBadRequest, "optionIds", "Option does not belong to this poll", err)
+	case errors.Is(err, ErrWrongBallot):
+		respondWithError(w, http.StatusBadRequest, "ballot", "Ballot does not match the poll type", err)
+	case errors.Is(err, ErrUnratedOption):
+		respondWithError(w, http.StatusBadRequest, "ratings", "Every option must be rated", err)
+	case errors.Is(err, ErrInvalidScore):
+		respondWithError(w, http.StatusBadRequest, "ratings", "Rating is outside the poll's scale", err)
+	case errors.Is(err, ErrPollNotOpen):
+		respondWithError(w, http.StatusConflict, "status", "Poll has not opened for voting yet", err)
+	case errors.Is(err, ErrPollClosed):
+		respondWithError(w, http.StatusConflict, "status", "Poll is not accepting votes", err)
+	case errors.Is(err, ErrGuestVotingDisabled):
+		respondWithError(w, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized), "Sign in to vote on this poll", err)
+	case errors.Is(err, ErrNoGuestSession):
+		respondWithError(w, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized), "Sign in to change your vote", err)
+	case errors.Is(err, ErrInvalidSession):
+		respondWithError(w, http.StatusBadRequest, "session", "Invalid session", err)
+	case errors.Is(err, ErrVotesLocked):
+		respondWithError(w, http.StatusForbidden, http.StatusText(http.StatusForbidden), "Votes on this poll are locked", err)
+	case errors.Is(err, ErrNoVote):
+		respondWithError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound), "You have not voted on this poll", err)
+	case errors.Is(err, ErrAlreadyVoted):
+		respondWithError(w, http.StatusConflict, http.StatusText(http.StatusConflict), "You have already voted on this poll", err)
+	default:
+		respondWithError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), "Failed to create vote", err)
+	}
+}
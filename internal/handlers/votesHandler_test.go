@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/GhostVox/ghostvox.io-backend/internal/config"
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestParseOptionIDs(t *testing.T) {
+	first := uuid.New()
+	second := uuid.New()
+
+	t.Run("Multiple options", func(t *testing.T) {
+		ids, err := parseOptionIDs(Vote{OptionIds: []string{first.String(), second.String()}})
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(ids) != 2 || ids[0] != first || ids[1] != second {
+			t.Fatalf("expected [%s %s], got %v", first, second, ids)
+		}
+	})
+
+	t.Run("Legacy single option", func(t *testing.T) {
+		ids, err := parseOptionIDs(Vote{OptionId: first.String()})
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(ids) != 1 || ids[0] != first {
+			t.Fatalf("expected [%s], got %v", first, ids)
+		}
+	})
+
+	t.Run("No options", func(t *testing.T) {
+		if _, err := parseOptionIDs(Vote{}); err == nil {
+			t.Fatalf("expected an error for an empty ballot")
+		}
+	})
+
+	t.Run("Duplicate options", func(t *testing.T) {
+		if _, err := parseOptionIDs(Vote{OptionIds: []string{first.String(), first.String()}}); err == nil {
+			t.Fatalf("expected an error for duplicate options")
+		}
+	})
+
+	t.Run("Invalid option ID", func(t *testing.T) {
+		if _, err := parseOptionIDs(Vote{OptionIds: []string{"not-a-uuid"}}); err == nil {
+			t.Fatalf("expected an error for an invalid option ID")
+		}
+	})
+}
+
+func TestBallotVotes(t *testing.T) {
+	rank := func(r int32) sql.NullInt32 {
+		return sql.NullInt32{Int32: r, Valid: true}
+	}
+
+	tests := []struct {
+		name  string
+		votes []database.Vote
+		want  int64
+	}{
+		{name: "Single choice", votes: []database.Vote{{}}, want: 1},
+		{name: "Multiple choice counts each option", votes: []database.Vote{{}, {}, {}}, want: 3},
+		{name: "Ranked ballot counts its first preference", votes: []database.Vote{{Rank: rank(1)}, {Rank: rank(2)}, {Rank: rank(3)}}, want: 1},
+		{name: "No votes", votes: nil, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ballotVotes(tt.votes); got != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolveVoter(t *testing.T) {
+	const secret = "test-secret"
+	returning := uuid.New()
+
+	tests := []struct {
+		name        string
+		method      string
+		allowGuests bool
+		cookie      bool
+		wantErr     error
+		wantCookie  bool
+	}{
+		{name: "First guest ballot", method: http.MethodPost, allowGuests: true, wantCookie: true},
+		{name: "Poll without guest votes", method: http.MethodPost, wantErr: ErrGuestVotingDisabled},
+		{name: "Change without a guest cookie", method: http.MethodPut, allowGuests: true, wantErr: ErrNoGuestSession},
+		{name: "Retract without a guest cookie", method: http.MethodDelete, allowGuests: true, wantErr: ErrNoGuestSession},
+		{name: "Returning guest", method: http.MethodPut, allowGuests: true, cookie: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pollID := uuid.New()
+			db := newFakeDB(t, map[string]fakeResult{
+				"GetPoll": fakePollRow(database.Poll{ID: pollID, UserID: uuid.New(), AllowGuestVotes: tt.allowGuests}),
+			})
+			vh := &voteHandler{cfg: &config.APIConfig{DB: db, Queries: database.New(db), GhostvoxSecretKey: secret}}
+
+			req := httptest.NewRequest(tt.method, "/api/v1/polls/"+pollID.String()+"/vote", nil)
+			if tt.cookie {
+				req.AddCookie(&http.Cookie{Name: auth.GuestVoterCookie, Value: auth.MakeGuestToken(returning, secret)})
+			}
+			rr := httptest.NewRecorder()
+			voter, err := vh.resolveVoter(rr, req, pollID, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if minted := len(rr.Result().Cookies()) > 0; minted != tt.wantCookie {
+				t.Fatalf("expected a new guest cookie %v, got %v", tt.wantCookie, minted)
+			}
+			if tt.cookie && voter.ID != returning {
+				t.Fatalf("expected the returning guest %s, got %s", returning, voter.ID)
+			}
+		})
+	}
+}
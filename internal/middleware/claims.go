@@ -35,6 +35,45 @@ func Authenticator(secretKey string) func(http.Handler) http.HandlerFunc {
 	}
 }
 
+// OptionalAuthenticator adds the claims to the request context when an
+// accessToken cookie is present and lets logged-out visitors through without
+// them. A token that is present but invalid is still rejected so an expired
+// session is not silently treated as a guest.
+func OptionalAuthenticator(secretKey string) func(http.Handler) http.HandlerFunc {
+	return optionalAuthenticator(secretKey, true)
+}
+
+// ViewerAuthenticator is OptionalAuthenticator for read-only routes, where a
+// token that is present but invalid is ignored and the visitor sees the page
+// logged out.
+func ViewerAuthenticator(secretKey string) func(http.Handler) http.HandlerFunc {
+	return optionalAuthenticator(secretKey, false)
+}
+
+func optionalAuthenticator(secretKey string, rejectInvalid bool) func(http.Handler) http.HandlerFunc {
+	return func(next http.Handler) http.HandlerFunc {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cookie, err := r.Cookie("accessToken")
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := auth.ValidateJWT(cookie.Value, secretKey)
+			if err != nil {
+				if rejectInvalid {
+					http.Error(w, "invalid access token", http.StatusUnauthorized)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := context.WithValue(r.Context(), claimsKey, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 func ClaimsFromContext(ctx context.Context) (*auth.CustomClaims, bool) {
 	claims, ok := ctx.Value(claimsKey).(*auth.CustomClaims)
 	return claims, ok
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/auth"
+	"github.com/google/uuid"
+)
+
+func TestOptionalAuthenticator(t *testing.T) {
+	secret := "testsecretkey"
+
+	var gotClaims *auth.CustomClaims
+	handler := OptionalAuthenticator(secret)(OptionalHandler(func(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+		gotClaims = claims
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("Logged out visitor", func(t *testing.T) {
+		gotClaims = nil
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("POST", "/vote", nil))
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+		}
+		if gotClaims != nil {
+			t.Errorf("Expected no claims for a logged out visitor")
+		}
+	})
+
+	t.Run("Signed in user", func(t *testing.T) {
+		gotClaims = nil
+		userID := uuid.New()
+		token, err := auth.GenerateJWTAccessToken(auth.TokenClaimsData{UserID: userID, Role: "user"}, secret, time.Hour)
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+
+		req := httptest.NewRequest("POST", "/vote", nil)
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: token})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+		}
+		if gotClaims == nil || gotClaims.Subject != userID.String() {
+			t.Errorf("Expected claims for user %s, got %v", userID, gotClaims)
+		}
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/vote", nil)
+		req.AddCookie(&http.Cookie{Name: "accessToken", Value: "invalid"})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+	})
+}
+
+func TestViewerAuthenticator(t *testing.T) {
+	secret := "testsecretkey"
+
+	var gotClaims *auth.CustomClaims
+	handler := ViewerAuthenticator(secret)(OptionalHandler(func(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims) {
+		gotClaims = claims
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	expired, err := auth.GenerateJWTAccessToken(auth.TokenClaimsData{UserID: uuid.New(), Role: "user"}, secret, -time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "Invalid token", token: "invalid"},
+		{name: "Expired token", token: expired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotClaims = nil
+			req := httptest.NewRequest("GET", "/polls/comments", nil)
+			req.AddCookie(&http.Cookie{Name: "accessToken", Value: tt.token})
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, rr.Code)
+			}
+			if gotClaims != nil {
+				t.Errorf("Expected a bad token to be treated as a logged out visitor")
+			}
+		})
+	}
+}
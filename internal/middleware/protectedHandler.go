@@ -16,3 +16,12 @@ func (fn ProtectedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	fn(w, r, claims)
 }
+
+// OptionalHandler serves routes that are also open to logged-out visitors,
+// claims is nil when the caller is not signed in.
+type OptionalHandler func(w http.ResponseWriter, r *http.Request, claims *auth.CustomClaims)
+
+func (fn OptionalHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	claims, _ := ClaimsFromContext(r.Context())
+	fn(w, r, claims)
+}
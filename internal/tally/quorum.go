@@ -0,0 +1,29 @@
+package tally
+
+import (
+	"context"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+)
+
+// Outcome reports how a closing poll ended. Polls without a quorum, or whose
+// distinct voters reached it, are decided.
+func Outcome(voters int64, quorum int32) database.PollOutcome {
+	if voters < int64(quorum) {
+		return database.PollOutcomeNoQuorum
+	}
+	return database.PollOutcomeDecided
+}
+
+// PollOutcome counts a poll's voters and returns its outcome, skipping the
+// count for polls without a quorum.
+func PollOutcome(ctx context.Context, q *database.Queries, poll database.Poll) (database.PollOutcome, error) {
+	if poll.Quorum == 0 {
+		return database.PollOutcomeDecided, nil
+	}
+	voters, err := q.CountPollVoters(ctx, poll.ID)
+	if err != nil {
+		return "", err
+	}
+	return Outcome(voters, poll.Quorum), nil
+}
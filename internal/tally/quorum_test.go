@@ -0,0 +1,28 @@
+package tally
+
+import (
+	"testing"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+)
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		name     string
+		voters   int64
+		quorum   int32
+		expected database.PollOutcome
+	}{
+		{"No quorum set", 0, 0, database.PollOutcomeDecided},
+		{"Quorum reached", 10, 10, database.PollOutcomeDecided},
+		{"Short of quorum", 9, 10, database.PollOutcomeNoQuorum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if outcome := Outcome(tt.voters, tt.quorum); outcome != tt.expected {
+				t.Fatalf("expected %q, got %q", tt.expected, outcome)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package tally
+
+import (
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+)
+
+// Count is one option's tally going into Summarize. ReachedAt is the unix
+// time of the option's last counted vote, used by the earliest_vote tie-break.
+type Count struct {
+	OptionID  string
+	Votes     int64
+	Score     float64
+	ReachedAt float64
+}
+
+// Standing is one option's place in a poll's results. Options with the same
+// votes, or the same mean score on rating polls, share a rank.
+type Standing struct {
+	OptionID   string  `json:"optionId"`
+	Votes      int64   `json:"votes"`
+	Percentage float64 `json:"percentage"`
+	Score      float64 `json:"score,omitempty"`
+	Rank       int     `json:"rank"`
+}
+
+// Results is the outcome of a poll. Leaders lists every option tied for
+// first, Winner is empty while that tie is unresolved under the poll's
+// tie-break policy. TotalVotes counts every ballot entry, Turnout the
+// distinct voters.
+type Results struct {
+	Options    []Standing `json:"options"`
+	Leaders    []string   `json:"leaders"`
+	Winner     string     `json:"winner"`
+	TotalVotes int64      `json:"totalVotes"`
+	Turnout    int64      `json:"turnout"`
+
+	tieBreak database.TieBreak
+	reached  map[string]float64
+}
+
+// Summarize ranks counts by votes, or by mean score when byScore is set, and
+// settles the leaders with policy. pick is the creator's choice for polls
+// decided by the creator.
+func Summarize(counts []Count, turnout int64, byScore bool, policy database.TieBreak, pick string) Results {
+	results := Results{
+		Options:  make([]Standing, len(counts)),
+		Leaders:  []string{},
+		tieBreak: policy,
+		Turnout:  turnout,
+		reached:  make(map[string]float64, len(counts)),
+	}
+
+	metric := func(count Count) float64 {
+		if byScore {
+			return count.Score
+		}
+		return float64(count.Votes)
+	}
+
+	sorted := slices.Clone(counts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return metric(sorted[i]) > metric(sorted[j])
+	})
+
+	for _, count := range counts {
+		results.TotalVotes += count.Votes
+	}
+
+	var leaders []string
+	for i, count := range sorted {
+		rank := i + 1
+		if i > 0 && metric(count) == metric(sorted[i-1]) {
+			rank = results.Options[i-1].Rank
+		}
+		if rank == 1 && metric(count) > 0 {
+			leaders = append(leaders, count.OptionID)
+		}
+
+		standing := Standing{OptionID: count.OptionID, Votes: count.Votes, Rank: rank}
+		if byScore {
+			standing.Score = count.Score
+		}
+		if results.TotalVotes > 0 {
+			standing.Percentage = math.Round(float64(count.Votes)*1000/float64(results.TotalVotes)) / 10
+		}
+		results.Options[i] = standing
+
+		if count.ReachedAt > 0 {
+			results.reached[count.OptionID] = count.ReachedAt
+		}
+	}
+
+	results.Settle(leaders, pick)
+	return results
+}
+
+// Settle records leaders and picks the winner. A single leader wins outright,
+// a tie is broken by the poll's tie-break policy or left without a winner.
+func (r *Results) Settle(leaders []string, pick string) {
+	r.Leaders = []string{}
+	if leaders != nil {
+		r.Leaders = leaders
+	}
+	r.Winner = ""
+
+	switch {
+	case len(leaders) == 1:
+		r.Winner = leaders[0]
+	case len(leaders) == 0:
+	case r.tieBreak == database.TieBreakEarliestVote:
+		r.Winner = earliestReached(leaders, r.reached)
+	case r.tieBreak == database.TieBreakCreator:
+		if slices.Contains(leaders, pick) {
+			r.Winner = pick
+		}
+	}
+}
+
+// earliestReached returns the leader whose last counted vote came first, or
+// an empty string when that is still a tie.
+func earliestReached(leaders []string, reached map[string]float64) string {
+	winner, first, tied := "", 0.0, false
+	for _, leader := range leaders {
+		at, ok := reached[leader]
+		if !ok {
+			continue
+		}
+		switch {
+		case winner == "" || at < first:
+			winner, first, tied = leader, at, false
+		case at == first:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return winner
+}
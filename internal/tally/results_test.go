@@ -0,0 +1,98 @@
+package tally
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Run("Single leader wins", func(t *testing.T) {
+		results := Summarize([]Count{
+			{OptionID: "a", Votes: 5},
+			{OptionID: "b", Votes: 3},
+			{OptionID: "c", Votes: 3},
+		}, 11, false, database.TieBreakNone, "")
+		if results.Winner != "a" {
+			t.Fatalf("expected winner a, got %q", results.Winner)
+		}
+		if !reflect.DeepEqual(results.Leaders, []string{"a"}) {
+			t.Fatalf("expected leaders [a], got %v", results.Leaders)
+		}
+		if results.TotalVotes != 11 || results.Turnout != 11 {
+			t.Fatalf("expected 11 votes and turnout, got %d and %d", results.TotalVotes, results.Turnout)
+		}
+	})
+
+	t.Run("Shared ranks and percentages", func(t *testing.T) {
+		results := Summarize([]Count{
+			{OptionID: "a", Votes: 1},
+			{OptionID: "b", Votes: 2},
+			{OptionID: "c", Votes: 1},
+		}, 4, false, database.TieBreakNone, "")
+		expected := []Standing{
+			{OptionID: "b", Votes: 2, Percentage: 50, Rank: 1},
+			{OptionID: "a", Votes: 1, Percentage: 25, Rank: 2},
+			{OptionID: "c", Votes: 1, Percentage: 25, Rank: 2},
+		}
+		if !reflect.DeepEqual(results.Options, expected) {
+			t.Fatalf("expected %v, got %v", expected, results.Options)
+		}
+	})
+
+	t.Run("Tie without a policy", func(t *testing.T) {
+		results := Summarize([]Count{
+			{OptionID: "a", Votes: 4, ReachedAt: 10},
+			{OptionID: "b", Votes: 4, ReachedAt: 20},
+		}, 8, false, database.TieBreakNone, "")
+		if results.Winner != "" {
+			t.Fatalf("expected no winner, got %q", results.Winner)
+		}
+		if !reflect.DeepEqual(results.Leaders, []string{"a", "b"}) {
+			t.Fatalf("expected leaders [a b], got %v", results.Leaders)
+		}
+	})
+
+	t.Run("Earliest vote breaks the tie", func(t *testing.T) {
+		results := Summarize([]Count{
+			{OptionID: "a", Votes: 4, ReachedAt: 20},
+			{OptionID: "b", Votes: 4, ReachedAt: 10},
+		}, 8, false, database.TieBreakEarliestVote, "")
+		if results.Winner != "b" {
+			t.Fatalf("expected winner b, got %q", results.Winner)
+		}
+	})
+
+	t.Run("Creator breaks the tie", func(t *testing.T) {
+		counts := []Count{
+			{OptionID: "a", Votes: 4},
+			{OptionID: "b", Votes: 4},
+			{OptionID: "c", Votes: 1},
+		}
+		if winner := Summarize(counts, 9, false, database.TieBreakCreator, "b").Winner; winner != "b" {
+			t.Fatalf("expected winner b, got %q", winner)
+		}
+		if winner := Summarize(counts, 9, false, database.TieBreakCreator, "c").Winner; winner != "" {
+			t.Fatalf("expected a pick outside the leaders to be ignored, got %q", winner)
+		}
+	})
+
+	t.Run("Highest mean wins", func(t *testing.T) {
+		results := Summarize([]Count{
+			{OptionID: "a", Votes: 3, Score: 3.5},
+			{OptionID: "b", Votes: 3, Score: 4.2},
+			{OptionID: "c", Votes: 3, Score: 1},
+		}, 3, true, database.TieBreakNone, "")
+		if results.Winner != "b" {
+			t.Fatalf("expected winner b, got %q", results.Winner)
+		}
+	})
+
+	t.Run("No votes", func(t *testing.T) {
+		results := Summarize([]Count{{OptionID: "a"}, {OptionID: "b"}}, 0, false, database.TieBreakEarliestVote, "")
+		if results.Winner != "" || len(results.Leaders) != 0 {
+			t.Fatalf("expected no winner or leaders, got %q and %v", results.Winner, results.Leaders)
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package tally
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/GhostVox/ghostvox.io-backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// Round is one row of the instant-runoff elimination table.
+type Round struct {
+	Round      int            `json:"round"`
+	Tallies    map[string]int `json:"tallies"`
+	Exhausted  int            `json:"exhausted"`
+	Eliminated []string       `json:"eliminated"`
+}
+
+// Runoff is the outcome of an instant-runoff count. Winner is empty when the
+// count ends in a tie, in which case Tied lists the remaining options.
+type Runoff struct {
+	Winner string   `json:"winner"`
+	Tied   []string `json:"tied,omitempty"`
+	Rounds []Round  `json:"rounds"`
+}
+
+// InstantRunoff counts ranked ballots. Each round every ballot counts toward
+// its highest ranked option still in the race. An option with a majority of
+// the continuing ballots wins, otherwise the options with the fewest votes are
+// eliminated and the ballots are counted again.
+func InstantRunoff(options []string, ballots [][]string) Runoff {
+	result := Runoff{Rounds: []Round{}}
+
+	active := make(map[string]bool, len(options))
+	for _, option := range options {
+		active[option] = true
+	}
+
+	for round := 1; len(active) > 0; round++ {
+		tallies := make(map[string]int, len(active))
+		for option := range active {
+			tallies[option] = 0
+		}
+
+		exhausted := 0
+		for _, ballot := range ballots {
+			counted := false
+			for _, choice := range ballot {
+				if active[choice] {
+					tallies[choice]++
+					counted = true
+					break
+				}
+			}
+			if !counted {
+				exhausted++
+			}
+		}
+
+		current := Round{Round: round, Tallies: tallies, Exhausted: exhausted, Eliminated: []string{}}
+		continuing := len(ballots) - exhausted
+
+		leader, most := "", -1
+		fewest := -1
+		for _, option := range sortedKeys(active) {
+			if tallies[option] > most {
+				leader, most = option, tallies[option]
+			}
+			if fewest == -1 || tallies[option] < fewest {
+				fewest = tallies[option]
+			}
+		}
+
+		if continuing > 0 && most*2 > continuing {
+			result.Winner = leader
+			result.Rounds = append(result.Rounds, current)
+			return result
+		}
+
+		var losers []string
+		for _, option := range sortedKeys(active) {
+			if tallies[option] == fewest {
+				losers = append(losers, option)
+			}
+		}
+
+		// Every remaining option is tied for last, nobody can be eliminated
+		if len(losers) == len(active) {
+			if len(losers) == 1 && continuing > 0 {
+				result.Winner = losers[0]
+			} else {
+				result.Tied = losers
+			}
+			result.Rounds = append(result.Rounds, current)
+			return result
+		}
+
+		for _, option := range losers {
+			delete(active, option)
+		}
+		current.Eliminated = losers
+		result.Rounds = append(result.Rounds, current)
+	}
+
+	return result
+}
+
+// RankedResult returns the instant-runoff result for a ranked poll. Archived
+// polls use the tally frozen by the cron job, open polls are counted live.
+func RankedResult(ctx context.Context, q *database.Queries, pollID uuid.UUID) (Runoff, error) {
+	frozen, err := q.GetPollResultByPollID(ctx, pollID)
+	if err == nil {
+		result := Runoff{}
+		if err := json.Unmarshal(frozen.Tally, &result); err != nil {
+			return Runoff{}, err
+		}
+		return result, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return Runoff{}, err
+	}
+
+	return CountRanked(ctx, q, pollID)
+}
+
+// CountRanked runs an instant-runoff count over the ballots currently stored
+// for a poll.
+func CountRanked(ctx context.Context, q *database.Queries, pollID uuid.UUID) (Runoff, error) {
+	options, err := q.GetOptionsByPollIDs(ctx, []uuid.UUID{pollID})
+	if err != nil {
+		return Runoff{}, err
+	}
+	optionIDs := make([]string, len(options))
+	for i, option := range options {
+		optionIDs[i] = option.ID.String()
+	}
+
+	rows, err := q.GetRankedBallotsByPollID(ctx, pollID)
+	if err != nil {
+		return Runoff{}, err
+	}
+
+	// Rows are ordered by voter then rank, so each voter's choices are contiguous
+	var ballots [][]string
+	var voter uuid.UUID
+	for i, row := range rows {
+		if i == 0 || row.VoterID != voter {
+			ballots = append(ballots, []string{})
+			voter = row.VoterID
+		}
+		ballots[len(ballots)-1] = append(ballots[len(ballots)-1], row.OptionID.String())
+	}
+
+	return InstantRunoff(optionIDs, ballots), nil
+}
+
+// FreezeRanked stores the final runoff so archived polls no longer recount
+// their ballots on every read.
+func FreezeRanked(ctx context.Context, q *database.Queries, pollID uuid.UUID) error {
+	result, err := CountRanked(ctx, q, pollID)
+	if err != nil {
+		return err
+	}
+
+	rounds, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	winner := uuid.NullUUID{}
+	if result.Winner != "" {
+		winner.UUID, err = uuid.Parse(result.Winner)
+		if err != nil {
+			return err
+		}
+		winner.Valid = true
+	}
+
+	return q.UpsertPollResult(ctx, database.UpsertPollResultParams{
+		PollID:         pollID,
+		WinnerOptionID: winner,
+		Tally:          rounds,
+	})
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,73 @@
+package tally
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInstantRunoff(t *testing.T) {
+	options := []string{"a", "b", "c"}
+
+	t.Run("First round majority", func(t *testing.T) {
+		result := InstantRunoff(options, [][]string{{"a"}, {"a", "b"}, {"b"}})
+		if result.Winner != "a" {
+			t.Fatalf("expected winner a, got %q", result.Winner)
+		}
+		if len(result.Rounds) != 1 {
+			t.Fatalf("expected 1 round, got %d", len(result.Rounds))
+		}
+	})
+
+	t.Run("Winner after redistribution", func(t *testing.T) {
+		ballots := [][]string{
+			{"a", "b"},
+			{"a", "c"},
+			{"b", "a"},
+			{"b", "a"},
+			{"c", "b"},
+		}
+		result := InstantRunoff(options, ballots)
+		if result.Winner != "b" {
+			t.Fatalf("expected winner b, got %q", result.Winner)
+		}
+		if len(result.Rounds) != 2 {
+			t.Fatalf("expected 2 rounds, got %d", len(result.Rounds))
+		}
+		if !reflect.DeepEqual(result.Rounds[0].Eliminated, []string{"c"}) {
+			t.Fatalf("expected c eliminated in round 1, got %v", result.Rounds[0].Eliminated)
+		}
+		if result.Rounds[1].Tallies["b"] != 3 {
+			t.Fatalf("expected b to have 3 votes in round 2, got %d", result.Rounds[1].Tallies["b"])
+		}
+	})
+
+	t.Run("Exhausted ballots", func(t *testing.T) {
+		ballots := [][]string{
+			{"a"},
+			{"a"},
+			{"b"},
+			{"b"},
+			{"c"},
+		}
+		result := InstantRunoff(options, ballots)
+		if result.Winner != "" {
+			t.Fatalf("expected no winner, got %q", result.Winner)
+		}
+		if !reflect.DeepEqual(result.Tied, []string{"a", "b"}) {
+			t.Fatalf("expected a and b tied, got %v", result.Tied)
+		}
+		if result.Rounds[1].Exhausted != 1 {
+			t.Fatalf("expected 1 exhausted ballot, got %d", result.Rounds[1].Exhausted)
+		}
+	})
+
+	t.Run("No ballots", func(t *testing.T) {
+		result := InstantRunoff(options, nil)
+		if result.Winner != "" {
+			t.Fatalf("expected no winner, got %q", result.Winner)
+		}
+		if len(result.Rounds) != 1 {
+			t.Fatalf("expected 1 round, got %d", len(result.Rounds))
+		}
+	})
+}
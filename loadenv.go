@@ -12,31 +12,34 @@ import (
 
 // EnvConfig holds all environment configuration
 type EnvConfig struct {
-	DBURL                 string
-	Platform              string
-	GhostvoxSecretKey     string
-	AccessTokenExp        time.Duration
-	RefreshTokenExp       time.Duration
-	GoogleClientID        string
-	GoogleClientSecret    string
-	GoogleRedirectURI     string
-	GithubClientID        string
-	GithubClientSecret    string
-	GithubRedirectURI     string
-	Mode                  string
-	UseHTTPS              string
-	AccessOrigin          string
-	CronCheckExpiredPolls string
-	CertFile              string
-	KeyFile               string
-	AWSRegion             string
-	AWSBucket             string
-	AWSAccessKeyID        string
-	AWSSecretAccessKey    string
-	IPRateLimit           rate.Limit
-	IPRateBurst           int
-	IPLastSeen            time.Duration
-	DOMAIN                string
+	DBURL                  string
+	Platform               string
+	GhostvoxSecretKey      string
+	AccessTokenExp         time.Duration
+	RefreshTokenExp        time.Duration
+	GoogleClientID         string
+	GoogleClientSecret     string
+	GoogleRedirectURI      string
+	GithubClientID         string
+	GithubClientSecret     string
+	GithubRedirectURI      string
+	Mode                   string
+	UseHTTPS               string
+	AccessOrigin           string
+	CronCheckExpiredPolls  string
+	CronOpenScheduledPolls string
+	CronPurgeDeletedPolls  string
+	DeletedPollRetention   time.Duration
+	CertFile               string
+	KeyFile                string
+	AWSRegion              string
+	AWSBucket              string
+	AWSAccessKeyID         string
+	AWSSecretAccessKey     string
+	IPRateLimit            rate.Limit
+	IPRateBurst            int
+	IPLastSeen             time.Duration
+	DOMAIN                 string
 }
 
 // LoadEnv loads environment variables and returns a config struct
@@ -94,6 +97,14 @@ func LoadEnv() (*EnvConfig, error) {
 	mode := getRequiredEnv("MODE")
 	accessOrigin := getRequiredEnv("ACCESS_ORIGIN")
 	cronCheckExpiredPolls := getRequiredEnv("CRON_CHECK_FOR_EXPIRED_POLLS")
+	cronOpenScheduledPolls := os.Getenv("CRON_OPEN_SCHEDULED_POLLS")
+	if cronOpenScheduledPolls == "" {
+		cronOpenScheduledPolls = "@every 1m"
+	}
+	cronPurgeDeletedPolls := os.Getenv("CRON_PURGE_DELETED_POLLS")
+	if cronPurgeDeletedPolls == "" {
+		cronPurgeDeletedPolls = "@daily"
+	}
 	DOMAIN := getRequiredEnv("DOMAIN")
 
 	// Parse durations
@@ -147,31 +158,43 @@ func LoadEnv() (*EnvConfig, error) {
 		log.Fatalf("Invalid IP last seen duration: %v", err)
 	}
 
+	deletedPollRetentionStr := os.Getenv("DELETED_POLL_RETENTION")
+	if deletedPollRetentionStr == "" {
+		deletedPollRetentionStr = "720h"
+	}
+	deletedPollRetention, err := time.ParseDuration(deletedPollRetentionStr)
+	if err != nil || deletedPollRetention <= 0 {
+		log.Fatalf("Invalid deleted poll retention: %v", deletedPollRetentionStr)
+	}
+
 	return &EnvConfig{
-		DBURL:                 dbURL,
-		Platform:              platform,
-		GhostvoxSecretKey:     secretKey,
-		AccessTokenExp:        accessTokenExp,
-		RefreshTokenExp:       refreshTokenExp,
-		GoogleClientID:        googleClientID,
-		GoogleClientSecret:    googleClientSecret,
-		GoogleRedirectURI:     googleRedirectURI,
-		GithubClientID:        githubClientID,
-		GithubClientSecret:    githubClientSecret,
-		GithubRedirectURI:     githubRedirectURI,
-		Mode:                  mode,
-		UseHTTPS:              https,
-		AccessOrigin:          accessOrigin,
-		CronCheckExpiredPolls: cronCheckExpiredPolls,
-		CertFile:              certFile,
-		KeyFile:               keyFile,
-		AWSRegion:             awsRegion,
-		AWSBucket:             awsBucket,
-		AWSAccessKeyID:        awsAccessKeyID,
-		AWSSecretAccessKey:    awsSecretAccessKey,
-		IPRateLimit:           ipRateLimit,
-		IPRateBurst:           ipRateBurst,
-		IPLastSeen:            ipLastSeen,
-		DOMAIN:                DOMAIN,
+		DBURL:                  dbURL,
+		Platform:               platform,
+		GhostvoxSecretKey:      secretKey,
+		AccessTokenExp:         accessTokenExp,
+		RefreshTokenExp:        refreshTokenExp,
+		GoogleClientID:         googleClientID,
+		GoogleClientSecret:     googleClientSecret,
+		GoogleRedirectURI:      googleRedirectURI,
+		GithubClientID:         githubClientID,
+		GithubClientSecret:     githubClientSecret,
+		GithubRedirectURI:      githubRedirectURI,
+		Mode:                   mode,
+		UseHTTPS:               https,
+		AccessOrigin:           accessOrigin,
+		CronCheckExpiredPolls:  cronCheckExpiredPolls,
+		CronOpenScheduledPolls: cronOpenScheduledPolls,
+		CronPurgeDeletedPolls:  cronPurgeDeletedPolls,
+		DeletedPollRetention:   deletedPollRetention,
+		CertFile:               certFile,
+		KeyFile:                keyFile,
+		AWSRegion:              awsRegion,
+		AWSBucket:              awsBucket,
+		AWSAccessKeyID:         awsAccessKeyID,
+		AWSSecretAccessKey:     awsSecretAccessKey,
+		IPRateLimit:            ipRateLimit,
+		IPRateBurst:            ipRateBurst,
+		IPLastSeen:             ipLastSeen,
+		DOMAIN:                 DOMAIN,
 	}, nil
 }
@@ -56,23 +56,24 @@ func main() {
 	}
 	//Configure the API struct to pass around
 	cfg := &config.APIConfig{
-		DB:                db,
-		Queries:           dbConnection,
-		Platform:          envConfig.Platform,
-		Port:              port,
-		AccessTokenExp:    envConfig.AccessTokenExp,
-		RefreshTokenExp:   envConfig.RefreshTokenExp,
-		GhostvoxSecretKey: envConfig.GhostvoxSecretKey,
-		Mode:              envConfig.Mode,
-		UseHTTPS:          envConfig.UseHTTPS,
-		AccessOrigin:      envConfig.AccessOrigin,
-		AwsS3Bucket:       envConfig.AWSBucket,
-		AwsRegion:         envConfig.AWSRegion,
-		DOMAIN:            envConfig.DOMAIN,
+		DB:                   db,
+		Queries:              dbConnection,
+		Platform:             envConfig.Platform,
+		Port:                 port,
+		AccessTokenExp:       envConfig.AccessTokenExp,
+		RefreshTokenExp:      envConfig.RefreshTokenExp,
+		GhostvoxSecretKey:    envConfig.GhostvoxSecretKey,
+		Mode:                 envConfig.Mode,
+		UseHTTPS:             envConfig.UseHTTPS,
+		AccessOrigin:         envConfig.AccessOrigin,
+		AwsS3Bucket:          envConfig.AWSBucket,
+		AwsRegion:            envConfig.AWSRegion,
+		DOMAIN:               envConfig.DOMAIN,
+		DeletedPollRetention: envConfig.DeletedPollRetention,
 	}
 
 	//Configure Cron
-	CronCFG := cron.NewCronConfig(envConfig.CronCheckExpiredPolls)
+	CronCFG := cron.NewCronConfig(envConfig.CronCheckExpiredPolls, envConfig.CronOpenScheduledPolls, envConfig.CronPurgeDeletedPolls)
 
 	// OAuth2 configuration
 	googleOAuthConfig := &oauth2.Config{
@@ -105,6 +106,9 @@ func main() {
 
 	// Create an authorization middleware instance
 	authMiddleware := mw.Authenticator(cfg.GhostvoxSecretKey)
+	// Routes open to logged-out visitors, claims are attached when present
+	optionalAuthMiddleware := mw.OptionalAuthenticator(cfg.GhostvoxSecretKey)
+	viewerAuthMiddleware := mw.ViewerAuthenticator(cfg.GhostvoxSecretKey)
 
 	rateLimiter := mw.NewIPRateLimiter(envConfig.IPRateLimit, envConfig.IPRateBurst, envConfig.IPLastSeen)
 
@@ -115,14 +119,21 @@ func main() {
 	pollHandler := handlers.NewPollHandler(cfg, filter)
 	commentHandler := handlers.NewCommentHandler(cfg, filter)
 	voteHandler := handlers.NewVoteHandler(cfg)
-	optionHandler := handlers.NewOptionHandler(cfg)
+	optionHandler := handlers.NewOptionHandler(cfg, filter)
 	authHandler := handlers.NewAuthHandler(cfg)
 	googleHandler := handlers.NewGoogleHandler(cfg, googleOAuthConfig)
 	githubHandler := handlers.NewGithubHandler(cfg, githubOAuthConfig)
 	adminHandler := handlers.NewAdminHandler(cfg)
+	categoryHandler := handlers.NewCategoryHandler(cfg)
+	tagHandler := handlers.NewTagHandler(cfg)
 	awsS3Handler := handlers.NewAWSS3Handler(cfg, s3Client)
 	userHandler := handlers.NewUserHandler(cfg, awsS3Handler)
 
+	// "import" runs a bulk poll import from the command line instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImport(cfg, pollHandler.ImportPollFile, os.Args[2:]))
+	}
+
 	// Define Protected routes
 	updateUserAvatarHandler := mw.ProtectedHandler(awsS3Handler.UpdateUserAvatar)
 	createCommentHandler := mw.ProtectedHandler(commentHandler.CreatePollComment)
@@ -130,15 +141,48 @@ func main() {
 	getFinishedPollsHandler := mw.ProtectedHandler(pollHandler.GetAllFinishedPolls)
 	getActivePollsHandler := mw.ProtectedHandler(pollHandler.GetAllActivePolls)
 	getRecentPollsHandler := mw.ProtectedHandler(pollHandler.GetRecentPolls)
+	searchPollsHandler := mw.ProtectedHandler(pollHandler.SearchPolls)
 	updatePollHandler := mw.ProtectedHandler(pollHandler.UpdatePoll)
 	createPollHandler := mw.ProtectedHandler(pollHandler.CreatePoll)
 	deletePollHandler := mw.ProtectedHandler(pollHandler.DeletePoll)
-	getPollByIDHandler := mw.ProtectedHandler(pollHandler.GetPollByID)
+	closePollHandler := mw.ProtectedHandler(pollHandler.ClosePoll)
+	reopenPollHandler := mw.ProtectedHandler(pollHandler.ReopenPoll)
+	extendPollHandler := mw.ProtectedHandler(pollHandler.ExtendPoll)
+	addOptionHandler := mw.ProtectedHandler(optionHandler.AddOption)
+	renameOptionHandler := mw.ProtectedHandler(optionHandler.RenameOption)
+	reorderOptionsHandler := mw.ProtectedHandler(optionHandler.ReorderOptions)
+	deleteOptionHandler := mw.ProtectedHandler(optionHandler.DeleteOption)
+	getPollByIDHandler := mw.OptionalHandler(pollHandler.GetPollByID)
+	getAllowlistHandler := mw.ProtectedHandler(pollHandler.GetAllowlist)
+	setAllowlistHandler := mw.ProtectedHandler(pollHandler.SetAllowlist)
+	getEditorsHandler := mw.ProtectedHandler(pollHandler.GetEditors)
+	setEditorsHandler := mw.ProtectedHandler(pollHandler.SetEditors)
+	createDraftHandler := mw.ProtectedHandler(pollHandler.CreateDraft)
+	saveDraftHandler := mw.ProtectedHandler(pollHandler.SaveDraft)
+	publishDraftHandler := mw.ProtectedHandler(pollHandler.PublishDraft)
+	getMyDraftsHandler := mw.ProtectedHandler(pollHandler.GetMyDrafts)
+	clonePollHandler := mw.ProtectedHandler(pollHandler.ClonePoll)
+	saveTemplateHandler := mw.ProtectedHandler(pollHandler.SaveTemplate)
+	getTemplatesHandler := mw.ProtectedHandler(pollHandler.GetTemplates)
+	createPollFromTemplateHandler := mw.ProtectedHandler(pollHandler.CreatePollFromTemplate)
+	breakTieHandler := mw.ProtectedHandler(pollHandler.BreakTie)
+	getPollTimelineHandler := mw.ProtectedHandler(pollHandler.GetPollTimeline)
+	exportPollHandler := mw.ProtectedHandler(pollHandler.ExportPoll)
+	importPollsHandler := mw.ProtectedHandler(pollHandler.ImportPolls)
+	listDeletedPollsHandler := mw.ProtectedHandler(pollHandler.ListDeletedPolls)
+	restorePollHandler := mw.ProtectedHandler(pollHandler.RestorePoll)
 	getUserStatsHandler := mw.ProtectedHandler(userHandler.GetUserStats)
 	updateUserHandler := mw.ProtectedHandler(userHandler.UpdateUser)
 	addUserNameHandler := mw.ProtectedHandler(userHandler.AddUserName)
 	deleteUserHandler := mw.ProtectedHandler(userHandler.DeleteUser)
 
+	// Define routes that also accept guests
+	voteOnPollHandler := mw.OptionalHandler(voteHandler.VoteOnPoll)
+	changeVoteHandler := mw.OptionalHandler(voteHandler.ChangeVote)
+	retractVoteHandler := mw.OptionalHandler(voteHandler.RetractVote)
+	getUsersPollsHandler := mw.OptionalHandler(pollHandler.GetUsersPolls)
+	getPollCommentsHandler := mw.OptionalHandler(commentHandler.GetAllPollComments)
+
 	mux := http.NewServeMux()
 
 	wrappedMux := rateLimiter.Middleware(mw.CorsMiddleware(mux, envConfig.AccessOrigin))
@@ -154,17 +198,51 @@ func main() {
 
 	mux.HandleFunc("GET /api/v1/polls/recent", mw.LoggingMiddleware(authMiddleware(getRecentPollsHandler))) // in use
 
-	mux.HandleFunc("GET /api/v1/polls/{pollId}", mw.LoggingMiddleware(authMiddleware(getPollByIDHandler))) // in use
+	mux.HandleFunc("GET /api/v1/polls/search", mw.LoggingMiddleware(authMiddleware(searchPollsHandler)))
+
+	mux.HandleFunc("GET /api/v1/polls/{pollId}", mw.LoggingMiddleware(viewerAuthMiddleware(getPollByIDHandler))) // in use
 
-	mux.HandleFunc("GET /api/v1/polls/{pollId}/comments", mw.LoggingMiddleware(commentHandler.GetAllPollComments))
+	mux.HandleFunc("GET /api/v1/polls/{pollId}/comments", mw.LoggingMiddleware(viewerAuthMiddleware(getPollCommentsHandler)))
 
-	mux.HandleFunc("GET /api/v1/users/{userId}/polls", mw.LoggingMiddleware(pollHandler.GetUsersPolls)) // in use
+	mux.HandleFunc("GET /api/v1/users/{userId}/polls", mw.LoggingMiddleware(viewerAuthMiddleware(getUsersPollsHandler))) // in use
 
 	mux.HandleFunc("PUT /api/v1/polls/{pollId}", mw.LoggingMiddleware(authMiddleware(updatePollHandler)))
 
 	mux.HandleFunc("POST /api/v1/polls", mw.LoggingMiddleware(authMiddleware(createPollHandler))) // in use
 
-	mux.HandleFunc("POST /api/v1/polls/{pollId}/vote", mw.LoggingMiddleware(voteHandler.VoteOnPoll))
+	mux.HandleFunc("POST /api/v1/polls/drafts", mw.LoggingMiddleware(authMiddleware(createDraftHandler)))
+
+	mux.HandleFunc("PUT /api/v1/polls/{pollId}/draft", mw.LoggingMiddleware(authMiddleware(saveDraftHandler)))
+
+	mux.HandleFunc("POST /api/v1/polls/{pollId}/publish", mw.LoggingMiddleware(authMiddleware(publishDraftHandler)))
+
+	mux.HandleFunc("POST /api/v1/polls/{pollId}/clone", mw.LoggingMiddleware(authMiddleware(clonePollHandler)))
+
+	mux.HandleFunc("POST /api/v1/polls/{pollId}/close", mw.LoggingMiddleware(authMiddleware(closePollHandler)))
+
+	mux.HandleFunc("POST /api/v1/polls/{pollId}/reopen", mw.LoggingMiddleware(authMiddleware(reopenPollHandler)))
+
+	mux.HandleFunc("POST /api/v1/polls/{pollId}/extend", mw.LoggingMiddleware(authMiddleware(extendPollHandler)))
+
+	mux.HandleFunc("GET /api/v1/polls/{pollId}/allowlist", mw.LoggingMiddleware(authMiddleware(getAllowlistHandler)))
+
+	mux.HandleFunc("PUT /api/v1/polls/{pollId}/allowlist", mw.LoggingMiddleware(authMiddleware(setAllowlistHandler)))
+
+	mux.HandleFunc("GET /api/v1/polls/{pollId}/editors", mw.LoggingMiddleware(authMiddleware(getEditorsHandler)))
+
+	mux.HandleFunc("PUT /api/v1/polls/{pollId}/editors", mw.LoggingMiddleware(authMiddleware(setEditorsHandler)))
+
+	mux.HandleFunc("POST /api/v1/polls/{pollId}/tiebreak", mw.LoggingMiddleware(authMiddleware(breakTieHandler)))
+
+	mux.HandleFunc("GET /api/v1/polls/{pollId}/timeline", mw.LoggingMiddleware(authMiddleware(getPollTimelineHandler)))
+
+	mux.HandleFunc("GET /api/v1/polls/{pollId}/export", mw.LoggingMiddleware(authMiddleware(exportPollHandler)))
+
+	mux.HandleFunc("POST /api/v1/polls/{pollId}/vote", mw.LoggingMiddleware(optionalAuthMiddleware(voteOnPollHandler)))
+
+	mux.HandleFunc("PUT /api/v1/polls/{pollId}/vote", mw.LoggingMiddleware(optionalAuthMiddleware(changeVoteHandler)))
+
+	mux.HandleFunc("DELETE /api/v1/polls/{pollId}/vote", mw.LoggingMiddleware(optionalAuthMiddleware(retractVoteHandler)))
 
 	mux.HandleFunc("POST /api/v1/polls/{pollId}/comments", mw.LoggingMiddleware(authMiddleware(createCommentHandler)))
 
@@ -172,6 +250,15 @@ func main() {
 
 	mux.HandleFunc("DELETE /api/v1/polls/{pollId}", mw.LoggingMiddleware(authMiddleware(deletePollHandler)))
 	// End of poll routes
+
+	// Template routes
+	mux.HandleFunc("GET /api/v1/templates", mw.LoggingMiddleware(authMiddleware(getTemplatesHandler)))
+
+	mux.HandleFunc("POST /api/v1/templates", mw.LoggingMiddleware(authMiddleware(saveTemplateHandler)))
+
+	mux.HandleFunc("POST /api/v1/templates/{templateId}/polls", mw.LoggingMiddleware(authMiddleware(createPollFromTemplateHandler)))
+	// End of template routes
+
 	// OAuth routes
 	mux.HandleFunc("GET /api/v1/auth/google/login", mw.LoggingMiddleware(googleHandler.GoogleLoginHandler))       // in use
 	mux.HandleFunc("GET /api/v1/auth/google/callback", mw.LoggingMiddleware(googleHandler.GoogleCallbackHandler)) // in use
@@ -189,7 +276,14 @@ func main() {
 
 	mux.HandleFunc("GET /api/v1/admin/users", mw.AdminRole(cfg, mw.LoggingMiddleware(adminHandler.GetAllUsers)).ServeHTTP)
 
+	mux.HandleFunc("POST /api/v1/admin/polls/import", mw.AdminRole(cfg, mw.LoggingMiddleware(authMiddleware(importPollsHandler))).ServeHTTP)
+
+	mux.HandleFunc("GET /api/v1/admin/polls/trash", mw.AdminRole(cfg, mw.LoggingMiddleware(authMiddleware(listDeletedPollsHandler))).ServeHTTP)
+
+	mux.HandleFunc("POST /api/v1/admin/polls/{pollId}/restore", mw.AdminRole(cfg, mw.LoggingMiddleware(authMiddleware(restorePollHandler))).ServeHTTP)
+
 	// User public routes
+	mux.HandleFunc("GET /api/v1/users/me/drafts", mw.LoggingMiddleware(authMiddleware(getMyDraftsHandler)))
 	mux.HandleFunc("GET /api/v1/users/stats", mw.LoggingMiddleware(authMiddleware(getUserStatsHandler)))
 	mux.HandleFunc("PUT /api/v1/users/profile", mw.LoggingMiddleware(authMiddleware(updateUserHandler)))
 	mux.HandleFunc("PUT /api/v1/users/profile/avatar", mw.LoggingMiddleware(authMiddleware(updateUserAvatarHandler)))
@@ -200,9 +294,25 @@ func main() {
 
 	// Options Routes
 
-	mux.HandleFunc("DELETE /api/v1/polls/{pollId}/options/{optionId}", mw.LoggingMiddleware(optionHandler.DeleteOption))
+	mux.HandleFunc("POST /api/v1/polls/{pollId}/options", mw.LoggingMiddleware(authMiddleware(addOptionHandler)))
+	mux.HandleFunc("PUT /api/v1/polls/{pollId}/options/order", mw.LoggingMiddleware(authMiddleware(reorderOptionsHandler)))
+	mux.HandleFunc("PATCH /api/v1/polls/{pollId}/options/{optionId}", mw.LoggingMiddleware(authMiddleware(renameOptionHandler)))
+	mux.HandleFunc("DELETE /api/v1/polls/{pollId}/options/{optionId}", mw.LoggingMiddleware(authMiddleware(deleteOptionHandler)))
 	// End of options routes
 
+	// Categories routes
+	mux.HandleFunc("GET /api/v1/categories", mw.LoggingMiddleware(categoryHandler.ListCategories))
+
+	mux.HandleFunc("GET /api/v1/admin/categories", mw.AdminRole(cfg, mw.LoggingMiddleware(categoryHandler.AdminListCategories)).ServeHTTP)
+	mux.HandleFunc("POST /api/v1/admin/categories", mw.AdminRole(cfg, mw.LoggingMiddleware(categoryHandler.CreateCategory)).ServeHTTP)
+	mux.HandleFunc("PUT /api/v1/admin/categories/{slug}", mw.AdminRole(cfg, mw.LoggingMiddleware(categoryHandler.UpdateCategory)).ServeHTTP)
+	mux.HandleFunc("DELETE /api/v1/admin/categories/{slug}", mw.AdminRole(cfg, mw.LoggingMiddleware(categoryHandler.DeleteCategory)).ServeHTTP)
+	// End of categories routes
+
+	// Tags routes
+	mux.HandleFunc("GET /api/v1/tags", mw.LoggingMiddleware(tagHandler.AutocompleteTags))
+	// End of tags routes
+
 	//Redirect from the root page to API documentation
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/" {
@@ -0,0 +1,47 @@
+-- name: CreateCategory :one
+-- used by categoryhandler.CreateCategory
+INSERT INTO categories (slug, name, description, active)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetCategoryBySlug :one
+-- used by pollhandler.CreatePoll and pollhandler.UpdatePoll to validate the category
+SELECT * FROM categories
+WHERE slug = $1;
+
+-- name: ListCategoriesWithCounts :many
+-- used by categoryhandler.ListCategories, inactive categories are only listed for admins
+-- the counts only include public polls so they reveal nothing about hidden ones
+SELECT
+    categories.slug as Slug,
+    categories.name as Name,
+    categories.description as Description,
+    categories.active as Active,
+    COUNT(polls.id) FILTER (WHERE polls.status = 'Active') as ActivePolls,
+    COUNT(polls.id) FILTER (WHERE polls.status = 'Archived') as FinishedPolls
+FROM
+    categories
+LEFT JOIN polls ON polls.category = categories.slug AND polls.deleted_at IS NULL AND polls.visibility = 'public'
+WHERE
+    categories.active OR sqlc.arg(include_inactive)::boolean
+GROUP BY
+    categories.slug
+ORDER BY
+    categories.name;
+
+-- name: UpdateCategory :one
+-- used by categoryhandler.UpdateCategory
+UPDATE categories
+SET
+    name = $2,
+    description = $3,
+    active = $4,
+    updated_at = now()
+WHERE
+    slug = $1
+RETURNING *;
+
+-- name: DeleteCategory :execrows
+-- used by categoryhandler.DeleteCategory, fails while polls still use the category
+DELETE FROM categories
+WHERE slug = $1;
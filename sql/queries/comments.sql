@@ -10,12 +10,14 @@ WHERE poll_id = ANY($1::uuid[])
 GROUP BY poll_id;
 
 -- name: GetAllCommentsByPollID :many
- -- in Use in commenthandler.GetAllPollComments
+ -- in Use in commenthandler.GetAllPollComments, pages by (created_at, id) starting after the cursor
 SELECT comments.*, users.user_name as userName, users.picture_url as avatar_url
 FROM comments
 JOIN users ON comments.user_id = users.id
-WHERE poll_id = $1
-Order By comments.created_at DESC;
+WHERE poll_id = sqlc.arg(poll_id)
+    AND (sqlc.narg(cursor_created_at)::timestamp IS NULL OR (comments.created_at, comments.id) < (sqlc.narg(cursor_created_at)::timestamp, sqlc.narg(cursor_id)::uuid))
+Order By comments.created_at DESC, comments.id DESC
+LIMIT sqlc.arg(page_size);
 
 -- name: CreateComment :one
 -- in Use in commenthandler.CreateComment
@@ -1,22 +1,81 @@
 -- name: CreateOptions :execrows
--- in use by transaction createPollWithOptions
-INSERT INTO options (poll_id, name)
-VALUES ($1, UNNEST($2::text[]))
+-- in use by transaction createPollWithOptions, positions follow the order given
+INSERT INTO options (poll_id, name, position)
+SELECT $1, new_options.name, new_options.position
+FROM UNNEST($2::text[]) WITH ORDINALITY AS new_options (name, position)
 RETURNING id, name, created_at, updated_at, poll_id;
 
+-- name: CreateOption :one
+-- in use by transaction AddOptionToPoll, appends the option after the existing ones
+INSERT INTO options (poll_id, name, position)
+VALUES ($1, $2, (SELECT COALESCE(MAX(position), 0) + 1 FROM options WHERE poll_id = $1))
+RETURNING *;
+
 -- name: GetOptionsByPollIDs :many
 -- used by pollhandler.processPollData
 SELECT * FROM options
-WHERE poll_id = ANY($1::uuid[]);
+WHERE poll_id = ANY($1::uuid[])
+ORDER BY poll_id, position;
+
+-- name: GetOptionForPoll :one
+-- in use by the option edit transactions
+SELECT * FROM options
+WHERE id = $1 AND poll_id = $2;
+
+-- name: OptionHasVotes :one
+-- in use by the option edit transactions. Looks at the ballots themselves,
+-- options.count only holds first preferences on ranked polls
+SELECT (
+    EXISTS (SELECT 1 FROM votes WHERE votes.option_id = $1)
+    OR EXISTS (SELECT 1 FROM ratings WHERE ratings.option_id = $1)
+)::boolean as has_votes;
+
+-- name: RecountOptions :exec
+-- in use by transaction RemovePollOption after ranked ballots are re-ranked,
+-- counts first preferences like castBallot does
+UPDATE options
+SET
+    count = (SELECT COUNT(*) FROM votes WHERE votes.option_id = options.id AND (votes.rank IS NULL OR votes.rank = 1)),
+    updated_at = now()
+WHERE poll_id = $1;
+
+-- name: RenameOption :one
+-- in use by transaction RenamePollOption
+UPDATE options
+SET name = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetOptionPosition :exec
+-- in use by transaction ReorderPollOptions
+UPDATE options
+SET position = $2, updated_at = now()
+WHERE id = $1;
 
 -- name: UpdateOptionCount :one
--- in use by transaction createVoteAndUpdateOptionCount
+-- in use by transaction createVotesAndUpdateOptionCounts
 UPDATE options
 SET count = count + 1, updated_at = now()
-WHERE id = $1
+WHERE id = $1 AND poll_id = $2
 RETURNING id, name, created_at, updated_at, poll_id;
 
+-- name: DecrementOptionCount :exec
+-- in use by transaction retractBallot
+UPDATE options
+SET count = count - 1, updated_at = now()
+WHERE id = $1 AND count > 0;
+
 -- name: DeleteOption :exec
--- used by optionHandler.deleteOption
+-- in use by transaction RemovePollOption
 DELETE FROM options
 WHERE id = $1;
+
+-- name: DeletePollOptions :exec
+-- in use by transaction SaveDraft, drafts have no votes so their options are replaced as a whole
+DELETE FROM options
+WHERE poll_id = $1;
+
+-- name: CountOptionsInPoll :one
+-- in use by transaction createVotesAndUpdateOptionCounts
+SELECT COUNT(*) FROM options
+WHERE poll_id = $1 AND id = ANY($2::uuid[]);
@@ -0,0 +1,40 @@
+-- name: GetPollAccess :one
+-- used by handlers.authorizePollView, viewer_id is NULL for guests
+SELECT
+    polls.user_id as OwnerId,
+    polls.visibility as Visibility,
+    polls.status as Status,
+    poll_allows_viewer(polls.id, sqlc.narg(viewer_id)::uuid)::boolean as Allowed
+FROM
+    polls
+WHERE
+    polls.id = sqlc.arg(poll_id) AND polls.deleted_at IS NULL;
+
+-- name: GetPollAllowlist :many
+-- used by pollhandler.GetAllowlist
+SELECT
+    *
+FROM
+    poll_allowlist
+WHERE
+    poll_id = $1
+ORDER BY
+    created_at,
+    id;
+
+-- name: DeletePollAllowlist :exec
+-- in use by transaction SetPollAllowlist
+DELETE FROM poll_allowlist
+WHERE poll_id = $1;
+
+-- name: AddPollAllowlistUsers :exec
+-- in use by transactions CreatePollWithOptions and SetPollAllowlist
+INSERT INTO poll_allowlist (poll_id, user_id)
+SELECT sqlc.arg(poll_id), UNNEST(sqlc.arg(user_ids)::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: AddPollAllowlistEmails :exec
+-- in use by transactions CreatePollWithOptions and SetPollAllowlist, emails must be lowercase
+INSERT INTO poll_allowlist (poll_id, email)
+SELECT sqlc.arg(poll_id), UNNEST(sqlc.arg(emails)::text[])
+ON CONFLICT DO NOTHING;
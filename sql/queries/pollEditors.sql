@@ -0,0 +1,32 @@
+-- name: GetPollEditors :many
+-- used by pollhandler.GetEditors and transaction SetPollEditors
+SELECT
+    *
+FROM
+    poll_editors
+WHERE
+    poll_id = $1
+ORDER BY
+    created_at,
+    user_id;
+
+-- name: IsPollEditor :one
+-- used by handlers.authorizePollChange
+SELECT EXISTS(
+    SELECT 1
+    FROM
+        poll_editors
+    WHERE
+        poll_id = $1 AND user_id = $2
+    ) as editor;
+
+-- name: DeletePollEditors :exec
+-- in use by transaction SetPollEditors
+DELETE FROM poll_editors
+WHERE poll_id = $1;
+
+-- name: AddPollEditors :exec
+-- in use by transactions CreatePollWithOptions and SetPollEditors
+INSERT INTO poll_editors (poll_id, user_id)
+SELECT sqlc.arg(poll_id), UNNEST(sqlc.arg(user_ids)::uuid[])
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,17 @@
+-- name: UpsertPollResult :exec
+-- used by cron when archiving a poll to freeze its final tally
+INSERT INTO poll_results (poll_id, winner_option_id, tally)
+VALUES ($1, $2, $3)
+ON CONFLICT (poll_id) DO UPDATE
+SET winner_option_id = EXCLUDED.winner_option_id,
+    tally = EXCLUDED.tally;
+
+-- name: DeletePollResult :exec
+-- used by the reopen transaction, the poll will be counted live again
+DELETE FROM poll_results
+WHERE poll_id = $1;
+
+-- name: GetPollResultByPollID :one
+-- used by tally.RankedResult
+SELECT * FROM poll_results
+WHERE poll_id = $1;
@@ -0,0 +1,32 @@
+-- name: CreatePollTemplate :one
+-- used by pollhandler.SaveTemplate
+INSERT INTO
+    poll_templates (user_id, name, title_pattern, description, category, options, duration_days, poll_type, max_choices, rating_max, is_global)
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING
+    *;
+
+-- name: GetPollTemplate :one
+-- used by pollhandler.CreatePollFromTemplate, users can use global templates
+-- and their own
+SELECT
+    *
+FROM
+    poll_templates
+WHERE
+    id = sqlc.arg(id) AND (is_global OR user_id = sqlc.arg(user_id));
+
+-- name: GetPollTemplates :many
+-- used by pollhandler.GetTemplates, lists global templates and the user's own,
+-- newest first and paged by (created_at, id) starting after the cursor
+SELECT
+    *
+FROM
+    poll_templates
+WHERE
+    (is_global OR user_id = sqlc.arg(user_id))
+    AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+        OR (created_at, id) < (sqlc.narg(cursor_created_at)::timestamp, sqlc.narg(cursor_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
@@ -1,166 +1,407 @@
 -- name: CreatePoll :one
 -- used by transactions createPollWithOptions
 INSERT INTO
-    polls (user_id, title, category, description, expires_at, status)
+    polls (user_id, title, category, description, expires_at, status, max_choices, poll_type, rating_max, votes_locked, allow_guest_votes, starts_at, visibility, results_visibility, tie_break, quorum, quorum_extension_days, cloned_from)
 VALUES
-    ($1, $2, $3, $4, $5, $6)
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 RETURNING
     *;
 
 -- name: GetPollsByUser :many
--- used by pollhandler.GetPollsByUser
+-- used by pollhandler.GetUsersPolls, ordered by the requested sort mode and paged by
+-- (sort key, id) starting after the cursor. Other viewers only see the creator's
+-- public polls and the private polls they are allowed on.
+WITH feed AS (
+    SELECT
+        polls.id,
+        (CASE sqlc.arg(sort)::text
+            WHEN 'newest' THEN extract(epoch FROM polls.created_at)
+            WHEN 'ending_soon' THEN -extract(epoch FROM polls.expires_at)
+            WHEN 'most_voted' THEN (SELECT COUNT(*) FROM votes WHERE votes.poll_id = polls.id)
+            WHEN 'most_commented' THEN (SELECT COUNT(*) FROM comments WHERE comments.poll_id = polls.id)
+            WHEN 'trending' THEN (SELECT COUNT(*) FROM votes WHERE votes.poll_id = polls.id AND votes.created_at > sqlc.arg(trending_since)::timestamp)
+            ELSE extract(epoch FROM polls.expires_at)
+        END)::float8 as sort_key
+    FROM
+        polls
+    WHERE
+        polls.user_id = sqlc.arg(user_id) AND (sqlc.narg(category)::text IS NULL OR polls.category = sqlc.narg(category))
+        AND polls.deleted_at IS NULL AND polls.status <> 'Draft'
+        AND (polls.visibility = 'public' OR polls.user_id = sqlc.narg(viewer_id)::uuid
+            OR (polls.visibility = 'private' AND poll_allows_viewer(polls.id, sqlc.narg(viewer_id)::uuid)))
+        AND (cardinality(sqlc.arg(tags)::text[]) = 0 OR (
+            SELECT COUNT(*) FROM poll_tags JOIN tags ON tags.id = poll_tags.tag_id
+            WHERE poll_tags.poll_id = polls.id AND tags.name = ANY(sqlc.arg(tags)::text[])
+        ) >= CASE WHEN sqlc.arg(match_all_tags)::boolean THEN cardinality(sqlc.arg(tags)::text[]) ELSE 1 END)
+)
 SELECT
-    polls.id as PollId,
-    polls.title as Title,
-    polls.category as Category,
-    polls.description as Description,
-    polls.expires_at as ExpiresAt,
-    polls.status as Status,
-    polls.created_at as CreatedAt,
-    polls.updated_at as UpdatedAt,
-    users.first_name as CreatorFirstName,
-    users.last_name as CreatorLastName,
-    COUNT(DISTINCT votes.id) as votes,
-    COUNT(DISTINCT comments.id) as comments,
-    (SELECT json_agg(options.*) FROM options WHERE options.poll_id = polls.id) as Options,
-    (SELECT option_id FROM votes WHERE votes.poll_id = polls.id AND votes.user_id = $1 LIMIT 1) as UserVote
+    sqlc.embed(poll_details),
+    COALESCE((SELECT array_agg(votes.option_id ORDER BY votes.rank) FROM votes WHERE votes.poll_id = poll_details.id AND votes.user_id = sqlc.arg(user_id)), '{}')::uuid[] as UserVote,
+    COALESCE((SELECT json_object_agg(ratings.option_id, ratings.score) FROM ratings WHERE ratings.poll_id = poll_details.id AND ratings.user_id = sqlc.arg(user_id)), '{}')::json as UserRatings,
+    feed.sort_key as SortKey
 FROM
-    polls
-JOIN users ON polls.user_id = users.id
-LEFT JOIN votes ON polls.id = votes.poll_id
-LEFT JOIN comments ON polls.id = comments.poll_id
+    poll_details
+JOIN feed ON feed.id = poll_details.id
 WHERE
-    polls.user_id = $1 AND polls.category LIKE $2
-GROUP BY
-    polls.id,
-    users.first_name,
-    users.last_name
-LIMIT $3 OFFSET $4;
+    sqlc.narg(cursor_key)::float8 IS NULL OR (feed.sort_key, poll_details.id) < (sqlc.narg(cursor_key)::float8, sqlc.narg(cursor_id)::uuid)
+ORDER BY feed.sort_key DESC, poll_details.id DESC
+LIMIT sqlc.arg(page_size);
 
 
 -- name: UpdatePollStatus :one
--- used by cron
+-- used by cron, archiving records whether the poll reached its quorum
 UPDATE
     polls
 SET
     status = $2,
+    outcome = $3,
     updated_at = now()
 WHERE
     id = $1 RETURNING *;
 
 -- name: GetExpiredPollsToUpdate :many
 -- used by cron
-Select * from polls where expires_at < now() and status = 'Active';
+Select * from polls where expires_at < now() and status = 'Active' and deleted_at IS NULL;
+
+-- name: OpenScheduledPolls :many
+-- used by cron, opens Inactive polls whose start time has passed
+UPDATE polls
+SET status = 'Active', updated_at = now()
+WHERE status = 'Inactive' AND starts_at <= now() AND deleted_at IS NULL
+RETURNING *;
 
 -- name: GetAllPollsByStatusList :many
--- used by pollhandler.GetAllfinishedpolls and pollhandler.GetAllActivePolls
+-- used by pollhandler.GetAllfinishedpolls and pollhandler.GetAllActivePolls, ordered by the
+-- requested sort mode and paged by (sort key, id) starting after the cursor. Unlisted
+-- polls are left out unless the viewer created them.
+WITH feed AS (
+    SELECT
+        polls.id,
+        (CASE sqlc.arg(sort)::text
+            WHEN 'newest' THEN extract(epoch FROM polls.created_at)
+            WHEN 'ending_soon' THEN -extract(epoch FROM polls.expires_at)
+            WHEN 'most_voted' THEN (SELECT COUNT(*) FROM votes WHERE votes.poll_id = polls.id)
+            WHEN 'most_commented' THEN (SELECT COUNT(*) FROM comments WHERE comments.poll_id = polls.id)
+            WHEN 'trending' THEN (SELECT COUNT(*) FROM votes WHERE votes.poll_id = polls.id AND votes.created_at > sqlc.arg(trending_since)::timestamp)
+            ELSE extract(epoch FROM polls.expires_at)
+        END)::float8 as sort_key
+    FROM
+        polls
+    WHERE
+        polls.status = sqlc.arg(status) AND (sqlc.narg(category)::text IS NULL OR polls.category = sqlc.narg(category))
+        AND polls.deleted_at IS NULL
+        AND (polls.visibility = 'public' OR polls.user_id = sqlc.arg(user_id)
+            OR (polls.visibility = 'private' AND poll_allows_viewer(polls.id, sqlc.arg(user_id))))
+        AND (cardinality(sqlc.arg(tags)::text[]) = 0 OR (
+            SELECT COUNT(*) FROM poll_tags JOIN tags ON tags.id = poll_tags.tag_id
+            WHERE poll_tags.poll_id = polls.id AND tags.name = ANY(sqlc.arg(tags)::text[])
+        ) >= CASE WHEN sqlc.arg(match_all_tags)::boolean THEN cardinality(sqlc.arg(tags)::text[]) ELSE 1 END)
+)
 SELECT
-    polls.id as PollId,
-    polls.title as Title,
-    polls.category as Category,
-    polls.description as Description,
-    polls.expires_at as ExpiresAt,
-    polls.status as Status,
-    polls.created_at as CreatedAt,
-    polls.updated_at as UpdatedAt,
-    users.first_name as CreatorFirstName,
-    users.last_name as CreatorLastName,
-    COUNT(DISTINCT votes.id) as votes,
-    COUNT(DISTINCT comments.id) as comments,
-    (SELECT json_agg(options.*) FROM options WHERE options.poll_id = polls.id) as Options,
-    (SELECT votes.option_id FROM votes WHERE votes.poll_id = polls.id AND votes.user_id = $5 LIMIT 1) as UserVote
+    sqlc.embed(poll_details),
+    COALESCE((SELECT array_agg(votes.option_id ORDER BY votes.rank) FROM votes WHERE votes.poll_id = poll_details.id AND votes.user_id = sqlc.arg(user_id)), '{}')::uuid[] as UserVote,
+    COALESCE((SELECT json_object_agg(ratings.option_id, ratings.score) FROM ratings WHERE ratings.poll_id = poll_details.id AND ratings.user_id = sqlc.arg(user_id)), '{}')::json as UserRatings,
+    feed.sort_key as SortKey
+FROM
+    poll_details
+JOIN feed ON feed.id = poll_details.id
+WHERE
+    sqlc.narg(cursor_key)::float8 IS NULL OR (feed.sort_key, poll_details.id) < (sqlc.narg(cursor_key)::float8, sqlc.narg(cursor_id)::uuid)
+ORDER BY feed.sort_key DESC, poll_details.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: SearchPolls :many
+-- used by pollhandler.SearchPolls, best matches first with the matching text highlighted
+WITH matches AS (
+    SELECT
+        poll_search.poll_id,
+        ts_rank(poll_search.document, query) as rank,
+        ts_headline('english', poll_search.body, query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') as snippet
+    FROM
+        poll_search,
+        websearch_to_tsquery('english', sqlc.arg(query)) query
+    WHERE
+        poll_search.document @@ query
+)
+SELECT
+    sqlc.embed(poll_details),
+    COALESCE((SELECT array_agg(votes.option_id ORDER BY votes.rank) FROM votes WHERE votes.poll_id = poll_details.id AND votes.user_id = sqlc.arg(user_id)), '{}')::uuid[] as UserVote,
+    COALESCE((SELECT json_object_agg(ratings.option_id, ratings.score) FROM ratings WHERE ratings.poll_id = poll_details.id AND ratings.user_id = sqlc.arg(user_id)), '{}')::json as UserRatings,
+    matches.rank as Rank,
+    matches.snippet as Snippet
 FROM
+    poll_details
+JOIN matches ON matches.poll_id = poll_details.id
+WHERE
+    (sqlc.narg(category)::text IS NULL OR poll_details.category = sqlc.narg(category))
+    AND poll_details.status <> 'Draft'
+    AND (poll_details.visibility = 'public' OR poll_details.creator_id = sqlc.arg(user_id)
+        OR (poll_details.visibility = 'private' AND poll_allows_viewer(poll_details.id, sqlc.arg(user_id))))
+    AND (sqlc.narg(status)::poll_status IS NULL OR poll_details.status = sqlc.narg(status))
+    AND (cardinality(sqlc.arg(tags)::text[]) = 0 OR (
+        SELECT COUNT(*) FROM poll_tags JOIN tags ON tags.id = poll_tags.tag_id
+        WHERE poll_tags.poll_id = poll_details.id AND tags.name = ANY(sqlc.arg(tags)::text[])
+    ) >= CASE WHEN sqlc.arg(match_all_tags)::boolean THEN cardinality(sqlc.arg(tags)::text[]) ELSE 1 END)
+ORDER BY matches.rank DESC, poll_details.expires_at DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: ExtendPollForQuorum :one
+-- used by cron, a poll short of its quorum is extended at most once
+UPDATE
     polls
-JOIN users ON polls.user_id = users.id
-LEFT JOIN votes ON polls.id = votes.poll_id
-LEFT JOIN comments ON polls.id = comments.poll_id
-WHERE
-    polls.status = $1 AND polls.category LIKE($2)
-GROUP BY
-    polls.id,
-    users.id,
-    users.first_name,
-    users.last_name
-ORDER BY polls.expires_at DESC
-LIMIT $3 OFFSET $4;
+SET
+    expires_at = $2,
+    quorum_extended = true,
+    updated_at = now()
+WHERE
+    id = $1 RETURNING *;
+
+-- name: CountPollVoters :one
+-- distinct voters across ballots and ratings, compared against the quorum
+SELECT
+    COUNT(DISTINCT voter)
+FROM (
+    SELECT COALESCE(votes.user_id, votes.guest_id) as voter FROM votes WHERE votes.poll_id = $1
+    UNION
+    SELECT COALESCE(ratings.user_id, ratings.guest_id) FROM ratings WHERE ratings.poll_id = $1
+) voters;
+
+-- name: GetPollForVote :one
+-- used by the vote and poll lifecycle transactions and the cron close, locks the poll row so
+-- concurrent ballots and status changes are validated one at a time
+SELECT
+    *
+FROM
+    polls
+WHERE
+    id = $1 AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: GetPoll :one
+-- used by pollhandler.ClonePoll and votehandler.resolveVoter, visibility is checked
+-- by the caller with GetPollAccess
+SELECT
+    *
+FROM
+    polls
+WHERE
+    id = $1 AND deleted_at IS NULL;
 
 -- name: GetPollByID :one
--- name: GetPollByID :one
+-- visibility is checked by the caller with GetPollAccess
 SELECT
-  polls.id as PollId,
-  polls.title as Title,
-  polls.category as Category,
-  polls.description as Description,
-  polls.expires_at as ExpiresAt,
-  polls.status as Status,
-  polls.created_at as CreatedAt,
-  polls.updated_at as UpdatedAt,
-  users.first_name as CreatorFirstName,
-  users.last_name as CreatorLastName,
-  COUNT(DISTINCT votes.id) as votes,
-  COUNT(DISTINCT comments.id) as comments,
-  (SELECT json_agg(options.*) FROM options WHERE options.poll_id = polls.id) as Options,
-  (SELECT votes.option_id FROM votes WHERE votes.poll_id = polls.id AND votes.user_id = $2 LIMIT 1) as UserVote
+  sqlc.embed(poll_details),
+  COALESCE((SELECT array_agg(votes.option_id ORDER BY votes.rank) FROM votes WHERE votes.poll_id = poll_details.id AND votes.user_id = $2), '{}')::uuid[] as UserVote,
+  COALESCE((SELECT json_object_agg(ratings.option_id, ratings.score) FROM ratings WHERE ratings.poll_id = poll_details.id AND ratings.user_id = $2), '{}')::json as UserRatings
 FROM
-  polls
-  LEFT JOIN users ON polls.user_id = users.id
-  LEFT JOIN votes ON polls.id = votes.poll_id
-  LEFT JOIN comments ON polls.id = comments.poll_id
-WHERE
-  polls.id = $1
-GROUP BY
-  polls.id,
-  users.id,
-  users.first_name,
-  users.last_name;
+  poll_details
+WHERE
+  poll_details.id = $1;
 
 -- name: GetRecentPolls :many
+-- used by pollhandler.GetRecentPolls, ordered by the requested sort mode
+WITH feed AS (
+    SELECT
+        polls.id,
+        (CASE sqlc.arg(sort)::text
+            WHEN 'newest' THEN extract(epoch FROM polls.created_at)
+            WHEN 'ending_soon' THEN -extract(epoch FROM polls.expires_at)
+            WHEN 'most_voted' THEN (SELECT COUNT(*) FROM votes WHERE votes.poll_id = polls.id)
+            WHEN 'most_commented' THEN (SELECT COUNT(*) FROM comments WHERE comments.poll_id = polls.id)
+            WHEN 'trending' THEN (SELECT COUNT(*) FROM votes WHERE votes.poll_id = polls.id AND votes.created_at > sqlc.arg(trending_since)::timestamp)
+            ELSE extract(epoch FROM polls.expires_at)
+        END)::float8 as sort_key
+    FROM
+        polls
+    WHERE
+        polls.deleted_at IS NULL AND polls.status <> 'Draft'
+        AND (polls.visibility = 'public' OR polls.user_id = sqlc.arg(user_id)
+            OR (polls.visibility = 'private' AND poll_allows_viewer(polls.id, sqlc.arg(user_id))))
+        AND (cardinality(sqlc.arg(tags)::text[]) = 0 OR (
+            SELECT COUNT(*) FROM poll_tags JOIN tags ON tags.id = poll_tags.tag_id
+            WHERE poll_tags.poll_id = polls.id AND tags.name = ANY(sqlc.arg(tags)::text[])
+        ) >= CASE WHEN sqlc.arg(match_all_tags)::boolean THEN cardinality(sqlc.arg(tags)::text[]) ELSE 1 END)
+)
 SELECT
-    polls.id as PollId,
-    polls.title as Title,
-    polls.category as Category,
-    polls.description as Description,
-    polls.expires_at as ExpiresAt,
-    polls.status as Status,
-    polls.created_at as CreatedAt,
-    polls.updated_at as UpdatedAt,
-    users.first_name as CreatorFirstName,
-    users.last_name as CreatorLastName,
-    count(distinct votes.id) as votes,
-    count(distinct comments.id) as comments,
-    (SELECT json_agg(options.*) FROM options WHERE options.poll_id = polls.id) as Options,
-     (SELECT votes.option_id FROM votes WHERE votes.poll_id = polls.id AND votes.user_id = $1 LIMIT 1) as UserVote
-FROM polls
-JOIN users ON polls.user_id = users.id
-LEFT JOIN votes ON polls.id = votes.poll_id
-LEFT JOIN comments ON polls.id = comments.poll_id
-GROUP BY polls.id, users.first_name, users.last_name
-ORDER BY polls.expires_at DESC
-LIMIT 10;
+    sqlc.embed(poll_details),
+    COALESCE((SELECT array_agg(votes.option_id ORDER BY votes.rank) FROM votes WHERE votes.poll_id = poll_details.id AND votes.user_id = sqlc.arg(user_id)), '{}')::uuid[] as UserVote,
+    COALESCE((SELECT json_object_agg(ratings.option_id, ratings.score) FROM ratings WHERE ratings.poll_id = poll_details.id AND ratings.user_id = sqlc.arg(user_id)), '{}')::json as UserRatings,
+    feed.sort_key as SortKey
+FROM
+    poll_details
+JOIN feed ON feed.id = poll_details.id
+ORDER BY feed.sort_key DESC, poll_details.id DESC
+LIMIT sqlc.arg(page_size);
 
 --not used yet
 -- name: GetAllPolls :many
 SELECT
     *
 FROM
-    polls;
+    polls
+WHERE
+    visibility = 'public' AND deleted_at IS NULL AND status <> 'Draft';
 
 -- name: UpdatePoll :one
+-- used by transaction UpdatePollDetails, status changes go through UpdatePollLifecycle
 UPDATE
     polls
 SET
-    user_id = coalesce($1, user_id),
     title = coalesce($2, title),
-    category = coalesce($3, category),
+    category = coalesce(NULLIF($3, ''), category),
     description = coalesce($4, description),
     expires_at = coalesce($5, expires_at),
-    status = coalesce($6, status),
+    visibility = coalesce(NULLIF($6::text, '')::poll_visibility, visibility),
+    results_visibility = coalesce(NULLIF($7::text, '')::results_visibility, results_visibility),
+    tie_break = coalesce(NULLIF($8::text, '')::tie_break, tie_break),
+    quorum = CASE WHEN $9::int < 0 THEN quorum ELSE $9::int END,
+    quorum_extension_days = CASE WHEN $10::int < 0 THEN quorum_extension_days ELSE $10::int END,
     updated_at = now()
 WHERE
-    id = $7 RETURNING *;
+    id = $1 AND deleted_at IS NULL RETURNING *;
 
--- name: DeletePoll :exec
+-- name: UpdatePollLifecycle :one
+-- used by the close, reopen and extend transactions, a reopened poll drops
+-- the creator's tie-break pick and its outcome since the leaders may change
+UPDATE
+    polls
+SET
+    status = $2,
+    expires_at = $3,
+    outcome = $4,
+    tie_winner_option_id = CASE WHEN $2 = 'Archived' THEN tie_winner_option_id END,
+    updated_at = now()
+WHERE
+    id = $1 RETURNING *;
+
+-- name: DeletePoll :execrows
+-- used by pollhandler.DeletePoll, the poll stays in the trash until
+-- PurgeDeletedPolls removes it with its options, votes and comments
+UPDATE
+    polls
+SET
+    deleted_at = now(),
+    updated_at = now()
+WHERE
+    id = $1 AND deleted_at IS NULL;
+
+-- name: GetDeletedPolls :many
+-- used by pollhandler.ListDeletedPolls, most recently deleted first and paged
+-- by (deleted_at, id) starting after the cursor
+SELECT
+    polls.id as PollId,
+    polls.title as Title,
+    polls.category as Category,
+    polls.status as Status,
+    polls.user_id as CreatorId,
+    users.first_name as CreatorFirstName,
+    users.last_name as CreatorLastName,
+    polls.created_at as CreatedAt,
+    polls.deleted_at::timestamp as DeletedAt
+FROM
+    polls
+JOIN users ON polls.user_id = users.id
+WHERE
+    polls.deleted_at IS NOT NULL
+    AND (sqlc.narg(cursor_deleted_at)::timestamp IS NULL
+        OR (polls.deleted_at, polls.id) < (sqlc.narg(cursor_deleted_at)::timestamp, sqlc.narg(cursor_id)::uuid))
+ORDER BY polls.deleted_at DESC, polls.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: RestorePoll :execrows
+-- used by pollhandler.RestorePoll
+UPDATE
+    polls
+SET
+    deleted_at = NULL,
+    updated_at = now()
+WHERE
+    id = $1 AND deleted_at IS NOT NULL;
+
+-- name: PurgeDeletedPolls :execrows
+-- used by cron, removes polls deleted before the retention cutoff for good
 DELETE FROM
     polls
+WHERE
+    deleted_at < $1;
+
+-- name: RaiseRankedMaxChoices :exec
+-- used by transaction AddOptionToPoll after the option is added, ranked polls
+-- that let voters rank every option keep doing so
+UPDATE
+    polls
+SET
+    max_choices = max_choices + 1,
+    updated_at = now()
+WHERE
+    id = $1 AND poll_type = 'Ranked'
+    AND max_choices = (SELECT COUNT(*) FROM options WHERE options.poll_id = $1) - 1;
+
+-- name: ClampMaxChoices :exec
+-- used by transaction RemovePollOption, ballots can't choose more options
+-- than the poll has left
+UPDATE
+    polls
+SET
+    max_choices = (SELECT COUNT(*) FROM options WHERE options.poll_id = $1),
+    updated_at = now()
+WHERE
+    id = $1 AND max_choices > (SELECT COUNT(*) FROM options WHERE options.poll_id = $1);
+
+-- name: SetPollTieWinner :one
+-- records the creator's pick among tied leaders, the caller checks the option is tied
+UPDATE
+    polls
+SET
+    tie_winner_option_id = $2,
+    updated_at = now()
 WHERE
     id = $1 RETURNING *;
+
+-- name: GetDraftsByUser :many
+-- used by pollhandler.GetMyDrafts, most recently edited first and paged by
+-- (updated_at, id) starting after the cursor
+SELECT
+    polls.id as PollId,
+    polls.title as Title,
+    polls.description as Description,
+    polls.category as Category,
+    polls.poll_type as PollType,
+    COALESCE((SELECT array_agg(options.name ORDER BY options.position) FROM options WHERE options.poll_id = polls.id), '{}')::text[] as Options,
+    COALESCE((SELECT array_agg(tags.name ORDER BY tags.name) FROM poll_tags JOIN tags ON tags.id = poll_tags.tag_id WHERE poll_tags.poll_id = polls.id), '{}')::text[] as Tags,
+    polls.created_at as CreatedAt,
+    polls.updated_at as UpdatedAt
+FROM
+    polls
+WHERE
+    polls.user_id = sqlc.arg(user_id) AND polls.status = 'Draft' AND polls.deleted_at IS NULL
+    AND (sqlc.narg(cursor_updated_at)::timestamp IS NULL
+        OR (polls.updated_at, polls.id) < (sqlc.narg(cursor_updated_at)::timestamp, sqlc.narg(cursor_id)::uuid))
+ORDER BY polls.updated_at DESC, polls.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: UpdateDraft :one
+-- used by the SaveDraft and PublishDraft transactions, a draft is saved as a
+-- whole and publishing sets its status
+UPDATE
+    polls
+SET
+    title = $2,
+    description = $3,
+    category = $4,
+    status = $5,
+    starts_at = $6,
+    expires_at = $7,
+    max_choices = $8,
+    poll_type = $9,
+    rating_max = $10,
+    votes_locked = $11,
+    allow_guest_votes = $12,
+    visibility = $13,
+    results_visibility = $14,
+    tie_break = $15,
+    quorum = $16,
+    quorum_extension_days = $17,
+    updated_at = now()
+WHERE
+    id = $1 AND status = 'Draft' AND deleted_at IS NULL RETURNING *;
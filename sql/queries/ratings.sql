@@ -0,0 +1,15 @@
+-- name: CreateRating :one
+-- in use in transaction castRatings, exactly one of user_id and guest_id is set
+INSERT INTO ratings (poll_id, option_id, user_id, guest_id, score)
+VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: CountVoterRatingsByPollID :one
+-- in use in transaction CreateRatingsAndUpdateOptionCounts, voter_id is a user or guest ID
+SELECT COUNT(*) FROM ratings
+WHERE poll_id = sqlc.arg(poll_id) AND (user_id = sqlc.arg(voter_id)::uuid OR guest_id = sqlc.arg(voter_id)::uuid);
+
+-- name: DeleteVoterRatingsByPollID :many
+-- in use in transaction retractBallot, returns the removed rows so option counts can be decremented
+DELETE FROM ratings
+WHERE poll_id = sqlc.arg(poll_id) AND (user_id = sqlc.arg(voter_id)::uuid OR guest_id = sqlc.arg(voter_id)::uuid)
+RETURNING *;
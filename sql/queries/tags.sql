@@ -0,0 +1,48 @@
+-- name: UpsertTags :exec
+-- in use by transactions CreatePollWithOptions and UpdatePollDetails
+INSERT INTO tags (name)
+SELECT UNNEST(sqlc.arg(names)::text[])
+ON CONFLICT (name) DO NOTHING;
+
+-- name: AddPollTags :exec
+-- in use by transactions CreatePollWithOptions and UpdatePollDetails, the tags must exist
+INSERT INTO poll_tags (poll_id, tag_id)
+SELECT sqlc.arg(poll_id), tags.id
+FROM tags
+WHERE tags.name = ANY(sqlc.arg(names)::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeletePollTags :exec
+-- in use by transaction UpdatePollDetails
+DELETE FROM poll_tags
+WHERE poll_id = $1;
+
+-- name: GetPollTagNames :many
+-- in use by transaction PublishDraft
+SELECT
+    tags.name
+FROM
+    poll_tags
+JOIN tags ON tags.id = poll_tags.tag_id
+WHERE
+    poll_tags.poll_id = $1
+ORDER BY
+    tags.name;
+
+-- name: SearchTagsByPrefix :many
+-- used by taghandler.AutocompleteTags, most used tags first
+SELECT
+    tags.name as Name,
+    COUNT(polls.id) as Polls
+FROM
+    tags
+LEFT JOIN poll_tags ON poll_tags.tag_id = tags.id
+LEFT JOIN polls ON polls.id = poll_tags.poll_id AND polls.deleted_at IS NULL AND polls.status <> 'Draft'
+WHERE
+    tags.name LIKE sqlc.arg(prefix)::text || '%'
+GROUP BY
+    tags.id
+ORDER BY
+    Polls DESC,
+    tags.name
+LIMIT sqlc.arg(page_size);
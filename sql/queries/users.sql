@@ -60,9 +60,11 @@ WHERE
 
 -- name: GetUserStats :one
 SELECT
-    (SELECT COUNT(*) FROM polls WHERE polls.user_id = $1) as total_polls,
-    (SELECT COUNT(*) FROM comments WHERE comments.poll_id IN (SELECT id FROM polls WHERE polls.user_id = $1)) as total_comments,
-    (SELECT COUNT(*) FROM votes WHERE votes.poll_id IN (SELECT id FROM polls WHERE polls.user_id = $1)) as total_votes
+    (SELECT COUNT(*) FROM polls WHERE polls.user_id = $1 AND polls.deleted_at IS NULL AND polls.status <> 'Draft') as total_polls,
+    (SELECT COUNT(*) FROM comments WHERE comments.poll_id IN (SELECT id FROM polls WHERE polls.user_id = $1 AND polls.deleted_at IS NULL)) as total_comments,
+    (SELECT COUNT(*) FROM votes WHERE votes.poll_id IN (SELECT id FROM polls WHERE polls.user_id = $1 AND polls.deleted_at IS NULL)) as total_votes,
+    (SELECT COUNT(*) FROM polls WHERE polls.user_id = $1 AND polls.deleted_at IS NULL AND polls.outcome = 'decided') as decided_polls,
+    (SELECT COUNT(*) FROM polls WHERE polls.user_id = $1 AND polls.deleted_at IS NULL AND polls.outcome = 'no_quorum') as no_quorum_polls
 FROM users
 WHERE users.id = $1;
 
@@ -1,7 +1,7 @@
 -- name: CreateVote :one
--- in use in transaction CreateVoteAndUpdateOptionCount
-INSERT INTO votes (poll_id, option_id, user_id)
-VALUES ($1, $2, $3) RETURNING *;
+-- in use in transaction castBallot, exactly one of user_id and guest_id is set
+INSERT INTO votes (poll_id, option_id, user_id, guest_id, rank)
+VALUES ($1, $2, $3, $4, $5) RETURNING *;
 
 -- name: GetTotalVotesByPollIDs :many
 -- used by pollhandler.processPollData
@@ -21,3 +21,78 @@ SELECT * FROM votes WHERE option_id = $1;
 
 -- name: GetVotesByUserID :many
 SELECT * FROM votes WHERE user_id = $1;
+
+-- name: CountVoterVotesByPollID :one
+-- in use in transaction CreateVotesAndUpdateOptionCounts, voter_id is a user or guest ID
+SELECT COUNT(*) FROM votes
+WHERE poll_id = sqlc.arg(poll_id) AND (user_id = sqlc.arg(voter_id)::uuid OR guest_id = sqlc.arg(voter_id)::uuid);
+
+-- name: GetRankedBallotsByPollID :many
+-- used by tally.CountRanked, one row per ranked choice in ballot order
+SELECT COALESCE(user_id, guest_id)::uuid as voter_id, option_id FROM votes
+WHERE poll_id = $1
+ORDER BY voter_id, rank;
+
+-- name: DeleteVoterVotesByPollID :many
+-- in use in transaction retractBallot, returns the removed rows so option counts can be decremented
+DELETE FROM votes
+WHERE poll_id = sqlc.arg(poll_id) AND (user_id = sqlc.arg(voter_id)::uuid OR guest_id = sqlc.arg(voter_id)::uuid)
+RETURNING *;
+
+-- name: GetPollTimeline :many
+-- used by pollhandler.GetPollTimeline, ranked ballots count their first preference
+-- and rating polls each rating. Retracted votes are gone so they are not replayed
+SELECT
+    date_trunc(sqlc.arg(bucket)::text, ballots.created_at)::timestamp as bucket,
+    ballots.option_id,
+    COUNT(*) as votes
+FROM (
+    SELECT votes.option_id, votes.created_at FROM votes
+    WHERE votes.poll_id = sqlc.arg(poll_id) AND (votes.rank IS NULL OR votes.rank = 1)
+    UNION ALL
+    SELECT ratings.option_id, ratings.created_at FROM ratings
+    WHERE ratings.poll_id = sqlc.arg(poll_id)
+) ballots
+GROUP BY
+    bucket,
+    ballots.option_id
+ORDER BY
+    bucket,
+    ballots.option_id;
+
+-- name: GetPollBallotsPage :many
+-- used by pollhandler.ExportPoll, pages votes and ratings by (created_at, id) after the cursor.
+-- rank is 0 for unranked votes and ratings, score is 0 for votes
+SELECT
+    ballots.id,
+    ballots.voter_id,
+    ballots.option_id,
+    ballots.rank,
+    ballots.score,
+    ballots.created_at
+FROM (
+    SELECT votes.id, COALESCE(votes.user_id, votes.guest_id)::uuid as voter_id, votes.option_id, COALESCE(votes.rank, 0)::int as rank, 0::int as score, votes.created_at
+    FROM votes WHERE votes.poll_id = sqlc.arg(poll_id)
+    UNION ALL
+    SELECT ratings.id, COALESCE(ratings.user_id, ratings.guest_id)::uuid, ratings.option_id, 0, ratings.score, ratings.created_at
+    FROM ratings WHERE ratings.poll_id = sqlc.arg(poll_id)
+) ballots
+WHERE
+    sqlc.narg(cursor_created_at)::timestamp IS NULL
+    OR (ballots.created_at, ballots.id) > (sqlc.narg(cursor_created_at)::timestamp, sqlc.narg(cursor_id)::uuid)
+ORDER BY
+    ballots.created_at,
+    ballots.id
+LIMIT sqlc.arg(page_size);
+
+-- name: RerankBallots :exec
+-- in use by transaction RemovePollOption, closes the gap a removed option
+-- leaves in ranked ballots so every ballot's first choice has rank 1 again
+UPDATE votes
+SET rank = ranked.new_rank
+FROM (
+    SELECT votes.id, (row_number() OVER (PARTITION BY COALESCE(votes.user_id, votes.guest_id) ORDER BY votes.rank))::int as new_rank
+    FROM votes
+    WHERE votes.poll_id = $1 AND votes.rank IS NOT NULL
+) ranked
+WHERE votes.id = ranked.id AND votes.rank <> ranked.new_rank;
@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE polls
+ADD COLUMN max_choices INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE votes
+DROP CONSTRAINT votes_unique;
+
+ALTER TABLE votes
+ADD CONSTRAINT votes_unique_option UNIQUE (poll_id, user_id, option_id);
+
+CREATE INDEX idx_votes_poll_user ON votes (poll_id, user_id);
+
+-- +goose Down
+DROP INDEX idx_votes_poll_user;
+
+ALTER TABLE votes
+DROP CONSTRAINT votes_unique_option;
+
+ALTER TABLE votes
+ADD CONSTRAINT votes_unique UNIQUE (poll_id, user_id);
+
+ALTER TABLE polls
+DROP COLUMN max_choices;
@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TYPE poll_type AS ENUM ('Standard', 'Ranked');
+
+ALTER TABLE polls
+ADD COLUMN poll_type poll_type NOT NULL DEFAULT 'Standard';
+
+-- Position of the option on a ranked ballot, NULL for standard polls
+ALTER TABLE votes
+ADD COLUMN rank INTEGER DEFAULT NULL;
+
+-- Final tally frozen by the cron job when a poll is archived
+CREATE TABLE poll_results (
+    poll_id UUID PRIMARY KEY,
+    winner_option_id UUID DEFAULT NULL,
+    tally JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT now (),
+    CONSTRAINT poll_results_poll_id FOREIGN KEY (poll_id) REFERENCES polls (id) ON DELETE CASCADE,
+    CONSTRAINT poll_results_winner_option_id FOREIGN KEY (winner_option_id) REFERENCES options (id) ON DELETE SET NULL
+);
+
+-- +goose Down
+DROP TABLE poll_results;
+
+ALTER TABLE votes
+DROP COLUMN rank;
+
+ALTER TABLE polls
+DROP COLUMN poll_type;
+
+DROP TYPE poll_type;
@@ -0,0 +1,53 @@
+-- +goose Up
+ALTER TYPE poll_type ADD VALUE 'Rating';
+
+-- Highest score a voter can give an option, ratings start at 1
+ALTER TABLE polls
+ADD COLUMN rating_max INTEGER NOT NULL DEFAULT 5;
+
+CREATE TABLE ratings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    poll_id UUID NOT NULL,
+    option_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    score INTEGER NOT NULL CHECK (score >= 1),
+    created_at TIMESTAMP NOT NULL DEFAULT now (),
+    CONSTRAINT ratings_poll_id FOREIGN KEY (poll_id) REFERENCES polls (id) ON DELETE CASCADE,
+    CONSTRAINT ratings_option_id FOREIGN KEY (option_id) REFERENCES options (id) ON DELETE CASCADE,
+    CONSTRAINT ratings_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT ratings_unique UNIQUE (option_id, user_id)
+);
+
+CREATE INDEX idx_ratings_poll_id ON ratings (poll_id);
+
+CREATE VIEW poll_rating_stats AS
+SELECT
+    ratings.poll_id,
+    ratings.option_id,
+    COUNT(*) AS ratings,
+    AVG(ratings.score)::float8 AS mean,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY ratings.score)::float8 AS median,
+    (
+        SELECT jsonb_object_agg(scores.score, scores.total)
+        FROM (
+            SELECT r.score, COUNT(*) AS total
+            FROM ratings r
+            WHERE r.option_id = ratings.option_id
+            GROUP BY r.score
+        ) scores
+    ) AS distribution
+FROM
+    ratings
+GROUP BY
+    ratings.poll_id,
+    ratings.option_id;
+
+-- +goose Down
+DROP VIEW poll_rating_stats;
+
+DROP TABLE ratings;
+
+ALTER TABLE polls
+DROP COLUMN rating_max;
+
+-- Postgres cannot drop a value from an enum, 'Rating' stays on poll_type
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Creators can lock votes so ballots cannot be changed or retracted
+ALTER TABLE polls
+ADD COLUMN votes_locked BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE polls
+DROP COLUMN votes_locked;
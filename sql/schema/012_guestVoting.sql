@@ -0,0 +1,66 @@
+-- +goose Up
+-- Polls can opt in to ballots from logged-out visitors identified by a signed
+-- anonymous voter cookie
+ALTER TABLE polls
+ADD COLUMN allow_guest_votes BOOLEAN NOT NULL DEFAULT false;
+
+-- A ballot belongs to either a user or a guest
+ALTER TABLE votes
+ALTER COLUMN user_id DROP NOT NULL;
+
+ALTER TABLE votes
+ADD COLUMN guest_id UUID DEFAULT NULL;
+
+ALTER TABLE votes
+ADD CONSTRAINT votes_voter CHECK (num_nonnulls (user_id, guest_id) = 1);
+
+ALTER TABLE votes
+ADD CONSTRAINT votes_unique_guest_option UNIQUE (poll_id, guest_id, option_id);
+
+CREATE INDEX idx_votes_poll_guest ON votes (poll_id, guest_id);
+
+ALTER TABLE ratings
+ALTER COLUMN user_id DROP NOT NULL;
+
+ALTER TABLE ratings
+ADD COLUMN guest_id UUID DEFAULT NULL;
+
+ALTER TABLE ratings
+ADD CONSTRAINT ratings_voter CHECK (num_nonnulls (user_id, guest_id) = 1);
+
+ALTER TABLE ratings
+ADD CONSTRAINT ratings_unique_guest UNIQUE (option_id, guest_id);
+
+-- +goose Down
+DELETE FROM ratings WHERE guest_id IS NOT NULL;
+
+ALTER TABLE ratings
+DROP CONSTRAINT ratings_unique_guest;
+
+ALTER TABLE ratings
+DROP CONSTRAINT ratings_voter;
+
+ALTER TABLE ratings
+DROP COLUMN guest_id;
+
+ALTER TABLE ratings
+ALTER COLUMN user_id SET NOT NULL;
+
+DELETE FROM votes WHERE guest_id IS NOT NULL;
+
+DROP INDEX idx_votes_poll_guest;
+
+ALTER TABLE votes
+DROP CONSTRAINT votes_unique_guest_option;
+
+ALTER TABLE votes
+DROP CONSTRAINT votes_voter;
+
+ALTER TABLE votes
+DROP COLUMN guest_id;
+
+ALTER TABLE votes
+ALTER COLUMN user_id SET NOT NULL;
+
+ALTER TABLE polls
+DROP COLUMN allow_guest_votes;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Scheduled polls are created Inactive and opened by cron once starts_at passes
+ALTER TABLE polls
+ADD COLUMN starts_at TIMESTAMP NOT NULL DEFAULT now ();
+
+UPDATE polls SET starts_at = created_at;
+
+CREATE INDEX idx_poll_status_starts_at ON polls (status, starts_at);
+
+-- +goose Down
+DROP INDEX idx_poll_status_starts_at;
+
+ALTER TABLE polls
+DROP COLUMN starts_at;
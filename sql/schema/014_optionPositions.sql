@@ -0,0 +1,20 @@
+-- +goose Up
+-- Explicit display order for options, 1 is shown first
+ALTER TABLE options
+ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+UPDATE options
+SET position = ordered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY poll_id ORDER BY created_at, id) AS position
+    FROM options
+) ordered
+WHERE options.id = ordered.id;
+
+CREATE INDEX idx_options_poll_position ON options (poll_id, position);
+
+-- +goose Down
+DROP INDEX idx_options_poll_position;
+
+ALTER TABLE options
+DROP COLUMN position;
@@ -0,0 +1,80 @@
+-- +goose Up
+-- Search document for each poll. Option names live in their own table so the
+-- document is kept up to date by triggers instead of a generated column.
+CREATE TABLE poll_search (
+    poll_id UUID PRIMARY KEY,
+    body TEXT NOT NULL,
+    document TSVECTOR NOT NULL,
+    CONSTRAINT poll_search_poll_id FOREIGN KEY (poll_id) REFERENCES polls (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_poll_search_document ON poll_search USING GIN (document);
+
+-- Titles rank above option names, which rank above descriptions
+-- +goose StatementBegin
+CREATE FUNCTION refresh_poll_search(target UUID) RETURNS void AS $$
+BEGIN
+    INSERT INTO poll_search (poll_id, body, document)
+    SELECT
+        polls.id,
+        concat_ws(' ', polls.title, polls.description, string_agg(options.name, ' ' ORDER BY options.position)),
+        setweight(to_tsvector('english', polls.title), 'A') ||
+        setweight(to_tsvector('english', coalesce(string_agg(options.name, ' '), '')), 'B') ||
+        setweight(to_tsvector('english', polls.description), 'C')
+    FROM
+        polls
+    LEFT JOIN options ON options.poll_id = polls.id
+    WHERE
+        polls.id = target
+    GROUP BY
+        polls.id
+    ON CONFLICT (poll_id) DO UPDATE
+    SET body = EXCLUDED.body, document = EXCLUDED.document;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION polls_search_trigger() RETURNS trigger AS $$
+BEGIN
+    PERFORM refresh_poll_search(NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION options_search_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM refresh_poll_search(OLD.poll_id);
+    ELSE
+        PERFORM refresh_poll_search(NEW.poll_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER polls_search_refresh
+AFTER INSERT OR UPDATE OF title, description ON polls
+FOR EACH ROW EXECUTE FUNCTION polls_search_trigger();
+
+CREATE TRIGGER options_search_refresh
+AFTER INSERT OR UPDATE OF name OR DELETE ON options
+FOR EACH ROW EXECUTE FUNCTION options_search_trigger();
+
+SELECT refresh_poll_search(id) FROM polls;
+
+-- +goose Down
+DROP TRIGGER options_search_refresh ON options;
+
+DROP TRIGGER polls_search_refresh ON polls;
+
+DROP FUNCTION options_search_trigger;
+
+DROP FUNCTION polls_search_trigger;
+
+DROP FUNCTION refresh_poll_search;
+
+DROP TABLE poll_search;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Listings page by (expires_at, id) and (created_at, id) newest first
+CREATE INDEX idx_poll_status_expires_at ON polls (status, expires_at DESC, id DESC);
+
+CREATE INDEX idx_poll_user_expires_at ON polls (user_id, expires_at DESC, id DESC);
+
+CREATE INDEX idx_comments_poll_created_at ON comments (poll_id, created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX idx_comments_poll_created_at;
+
+DROP INDEX idx_poll_user_expires_at;
+
+DROP INDEX idx_poll_status_expires_at;
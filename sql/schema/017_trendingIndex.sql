@@ -0,0 +1,6 @@
+-- +goose Up
+-- Trending feeds count each poll's votes cast in a recent window
+CREATE INDEX idx_votes_poll_created_at ON votes (poll_id, created_at);
+
+-- +goose Down
+DROP INDEX idx_votes_poll_created_at;
@@ -0,0 +1,40 @@
+-- +goose Up
+CREATE TABLE categories (
+    slug TEXT PRIMARY KEY CHECK (slug ~ '^[a-z0-9]+(-[a-z0-9]+)*$'),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT now (),
+    updated_at TIMESTAMP NOT NULL DEFAULT now ()
+);
+
+-- Fold the free-text categories already on polls into slugs, "Tech" and
+-- "tech" become one category named after the most common spelling
+UPDATE polls
+SET category = 'general'
+WHERE trim(both '-' FROM regexp_replace(lower(category), '[^a-z0-9]+', '-', 'g')) = '';
+
+INSERT INTO categories (slug, name)
+SELECT
+    trim(both '-' FROM regexp_replace(lower(category), '[^a-z0-9]+', '-', 'g')),
+    mode() WITHIN GROUP (ORDER BY category)
+FROM
+    polls
+GROUP BY
+    1;
+
+UPDATE polls
+SET category = trim(both '-' FROM regexp_replace(lower(category), '[^a-z0-9]+', '-', 'g'));
+
+INSERT INTO categories (slug, name)
+VALUES ('general', 'General')
+ON CONFLICT (slug) DO NOTHING;
+
+ALTER TABLE polls
+ADD CONSTRAINT polls_category FOREIGN KEY (category) REFERENCES categories (slug);
+
+-- +goose Down
+ALTER TABLE polls
+DROP CONSTRAINT polls_category;
+
+DROP TABLE categories;
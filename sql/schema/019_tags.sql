@@ -0,0 +1,25 @@
+-- +goose Up
+-- Tags are stored normalized: lowercase words joined by dashes
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    name TEXT NOT NULL UNIQUE CHECK (name ~ '^[a-z0-9]+(-[a-z0-9]+)*$'),
+    created_at TIMESTAMP NOT NULL DEFAULT now ()
+);
+
+-- text_pattern_ops lets LIKE 'prefix%' use the index for autocomplete
+CREATE INDEX idx_tags_name_prefix ON tags (name text_pattern_ops);
+
+CREATE TABLE poll_tags (
+    poll_id UUID NOT NULL,
+    tag_id UUID NOT NULL,
+    PRIMARY KEY (poll_id, tag_id),
+    CONSTRAINT poll_tags_poll_id FOREIGN KEY (poll_id) REFERENCES polls (id) ON DELETE CASCADE,
+    CONSTRAINT poll_tags_tag_id FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_poll_tags_tag_id ON poll_tags (tag_id);
+
+-- +goose Down
+DROP TABLE poll_tags;
+
+DROP TABLE tags;
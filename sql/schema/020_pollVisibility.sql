@@ -0,0 +1,52 @@
+-- +goose Up
+-- Public polls are listed everywhere, unlisted polls are only reachable with a
+-- signed share token and private polls only by the users on their allowlist
+CREATE TYPE poll_visibility AS ENUM ('public', 'unlisted', 'private');
+
+ALTER TABLE polls
+ADD COLUMN visibility poll_visibility NOT NULL DEFAULT 'public';
+
+-- An allowlist entry names a user, or an email so people can be invited before
+-- they sign up. Emails are stored lowercase.
+CREATE TABLE poll_allowlist (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    poll_id UUID NOT NULL,
+    user_id UUID DEFAULT NULL,
+    email TEXT DEFAULT NULL CHECK (email = lower(email)),
+    created_at TIMESTAMP NOT NULL DEFAULT now (),
+    CONSTRAINT poll_allowlist_entry CHECK (num_nonnulls (user_id, email) = 1),
+    CONSTRAINT poll_allowlist_poll_id FOREIGN KEY (poll_id) REFERENCES polls (id) ON DELETE CASCADE,
+    CONSTRAINT poll_allowlist_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_poll_allowlist_user ON poll_allowlist (poll_id, user_id)
+WHERE
+    user_id IS NOT NULL;
+
+CREATE UNIQUE INDEX idx_poll_allowlist_email ON poll_allowlist (poll_id, email)
+WHERE
+    email IS NOT NULL;
+
+-- poll_allows_viewer reports whether a user is on a poll's allowlist, by ID or
+-- by the email they signed up with
+-- +goose StatementBegin
+CREATE FUNCTION poll_allows_viewer (target UUID, viewer UUID) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1
+        FROM poll_allowlist
+        JOIN users ON users.id = viewer
+        WHERE poll_allowlist.poll_id = target
+        AND (poll_allowlist.user_id = users.id OR poll_allowlist.email = lower(users.email))
+    );
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION poll_allows_viewer;
+
+DROP TABLE poll_allowlist;
+
+ALTER TABLE polls
+DROP COLUMN visibility;
+
+DROP TYPE poll_visibility;
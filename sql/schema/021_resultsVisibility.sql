@@ -0,0 +1,13 @@
+-- +goose Up
+-- Creators can hide counts and the winner until the viewer has voted or the
+-- poll has closed, so early results don't sway later voters
+CREATE TYPE results_visibility AS ENUM ('always', 'after_vote', 'after_close');
+
+ALTER TABLE polls
+ADD COLUMN results_visibility results_visibility NOT NULL DEFAULT 'always';
+
+-- +goose Down
+ALTER TABLE polls
+DROP COLUMN results_visibility;
+
+DROP TYPE results_visibility;
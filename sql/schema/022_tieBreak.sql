@@ -0,0 +1,16 @@
+-- +goose Up
+-- How a poll is decided when its leaders are tied. earliest_vote picks the
+-- tied option whose last counted vote came first, creator lets the poll's
+-- creator pick one of the tied options once the poll has closed
+CREATE TYPE tie_break AS ENUM ('none', 'earliest_vote', 'creator');
+
+ALTER TABLE polls
+ADD COLUMN tie_break tie_break NOT NULL DEFAULT 'none',
+ADD COLUMN tie_winner_option_id UUID REFERENCES options (id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE polls
+DROP COLUMN tie_winner_option_id,
+DROP COLUMN tie_break;
+
+DROP TYPE tie_break;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- A poll needs quorum distinct voters to count as decided, 0 means any
+-- turnout will do. Creators can opt in to one automatic extension of
+-- quorum_extension_days when a poll expires short of its quorum
+CREATE TYPE poll_outcome AS ENUM ('decided', 'no_quorum');
+
+ALTER TABLE polls
+ADD COLUMN quorum INTEGER NOT NULL DEFAULT 0 CHECK (quorum >= 0),
+ADD COLUMN quorum_extension_days INTEGER NOT NULL DEFAULT 0 CHECK (quorum_extension_days >= 0),
+ADD COLUMN quorum_extended BOOLEAN NOT NULL DEFAULT false,
+ADD COLUMN outcome poll_outcome;
+
+-- Polls archived before quorums existed were decided by whoever voted
+UPDATE polls SET outcome = 'decided' WHERE status = 'Archived';
+
+-- +goose Down
+ALTER TABLE polls
+DROP COLUMN outcome,
+DROP COLUMN quorum_extended,
+DROP COLUMN quorum_extension_days,
+DROP COLUMN quorum;
+
+DROP TYPE poll_outcome;
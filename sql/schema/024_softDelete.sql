@@ -0,0 +1,13 @@
+-- +goose Up
+-- Deleted polls are kept with their options, votes and comments until a cron
+-- job purges them after the retention period, admins can restore them until then
+ALTER TABLE polls
+ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_poll_deleted_at ON polls (deleted_at DESC, id DESC) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX idx_poll_deleted_at;
+
+ALTER TABLE polls
+DROP COLUMN deleted_at;
@@ -0,0 +1,46 @@
+-- +goose Up
+-- Co-editors may change a poll's details, options and lifecycle alongside its
+-- creator. Deleting the poll and managing who can see or edit it stays with
+-- the creator and admins.
+CREATE TABLE poll_editors (
+    poll_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now (),
+    PRIMARY KEY (poll_id, user_id),
+    CONSTRAINT poll_editors_poll_id FOREIGN KEY (poll_id) REFERENCES polls (id) ON DELETE CASCADE,
+    CONSTRAINT poll_editors_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_poll_editors_user ON poll_editors (user_id);
+
+-- Editors can always see the private polls they edit
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION poll_allows_viewer (target UUID, viewer UUID) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1
+        FROM poll_allowlist
+        JOIN users ON users.id = viewer
+        WHERE poll_allowlist.poll_id = target
+        AND (poll_allowlist.user_id = users.id OR poll_allowlist.email = lower(users.email))
+    ) OR EXISTS (
+        SELECT 1
+        FROM poll_editors
+        WHERE poll_editors.poll_id = target AND poll_editors.user_id = viewer
+    );
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION poll_allows_viewer (target UUID, viewer UUID) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1
+        FROM poll_allowlist
+        JOIN users ON users.id = viewer
+        WHERE poll_allowlist.poll_id = target
+        AND (poll_allowlist.user_id = users.id OR poll_allowlist.email = lower(users.email))
+    );
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+DROP TABLE poll_editors;
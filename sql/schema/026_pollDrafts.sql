@@ -0,0 +1,20 @@
+-- +goose Up
+-- Drafts are incomplete polls only their creator can see. They skip most
+-- validation until they are published and become Active.
+ALTER TYPE poll_status ADD VALUE 'Draft';
+
+-- +goose Down
+-- Enum values can't be dropped, so the type is rebuilt without Draft
+DELETE FROM polls
+WHERE status = 'Draft';
+
+ALTER TYPE poll_status RENAME TO poll_status_old;
+
+CREATE TYPE poll_status AS ENUM ('Active', 'Inactive', 'Archived');
+
+ALTER TABLE polls
+ALTER COLUMN status DROP DEFAULT,
+ALTER COLUMN status TYPE poll_status USING status::text::poll_status,
+ALTER COLUMN status SET DEFAULT 'Active';
+
+DROP TYPE poll_status_old;
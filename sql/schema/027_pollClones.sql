@@ -0,0 +1,9 @@
+-- +goose Up
+-- A cloned poll can keep a link back to the poll it was copied from, the link
+-- is dropped when the source is purged
+ALTER TABLE polls
+ADD COLUMN cloned_from UUID REFERENCES polls (id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE polls
+DROP COLUMN cloned_from;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Templates hold everything needed to create a poll again. Title patterns may
+-- contain placeholders filled in when a poll is created, global templates are
+-- published by admins and offered to everyone
+CREATE TABLE poll_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    title_pattern TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL,
+    options TEXT[] NOT NULL,
+    duration_days INTEGER NOT NULL CHECK (duration_days >= 1),
+    poll_type poll_type NOT NULL DEFAULT 'Standard',
+    max_choices INTEGER NOT NULL DEFAULT 1,
+    rating_max INTEGER NOT NULL DEFAULT 5,
+    is_global BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT now (),
+    CONSTRAINT poll_templates_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT poll_templates_category FOREIGN KEY (category) REFERENCES categories (slug)
+);
+
+CREATE INDEX idx_poll_templates_user ON poll_templates (user_id, created_at DESC, id DESC);
+
+CREATE INDEX idx_poll_templates_global ON poll_templates (created_at DESC, id DESC) WHERE is_global;
+
+-- +goose Down
+DROP TABLE poll_templates;
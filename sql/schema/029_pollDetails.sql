@@ -0,0 +1,50 @@
+-- +goose Up
+-- Everything a poll response shows that is the same for every viewer. The poll
+-- queries select it with sqlc.embed and add the viewer's own ballot, so each of
+-- them returns the same row shape
+CREATE VIEW poll_details AS
+SELECT
+    polls.id,
+    polls.title,
+    polls.category,
+    polls.description,
+    polls.expires_at,
+    polls.status,
+    polls.max_choices,
+    polls.poll_type,
+    polls.rating_max,
+    polls.votes_locked,
+    polls.allow_guest_votes,
+    polls.starts_at,
+    polls.visibility,
+    polls.results_visibility,
+    polls.user_id AS creator_id,
+    polls.tie_break,
+    polls.tie_winner_option_id AS tie_winner,
+    polls.quorum,
+    polls.outcome,
+    polls.cloned_from,
+    polls.created_at,
+    polls.updated_at,
+    users.first_name AS creator_first_name,
+    users.last_name AS creator_last_name,
+    (SELECT COUNT(*) FROM votes WHERE votes.poll_id = polls.id AND (votes.rank IS NULL OR votes.rank = 1)) AS votes,
+    (SELECT COUNT(*) FROM comments WHERE comments.poll_id = polls.id) AS comments,
+    (SELECT COUNT(DISTINCT COALESCE(votes.user_id, votes.guest_id)) FROM votes WHERE votes.poll_id = polls.id) AS voters,
+    (SELECT json_agg(options.* ORDER BY options.position) FROM options WHERE options.poll_id = polls.id) AS options,
+    COALESCE((SELECT json_agg(poll_rating_stats.*) FROM poll_rating_stats WHERE poll_rating_stats.poll_id = polls.id), '[]')::json AS rating_stats,
+    (SELECT poll_results.winner_option_id FROM poll_results WHERE poll_results.poll_id = polls.id) AS final_winner,
+    COALESCE((SELECT json_object_agg(reached.option_id, reached.at) FROM (
+        SELECT votes.option_id, extract(epoch FROM MAX(votes.created_at)) AS at FROM votes WHERE votes.poll_id = polls.id AND (votes.rank IS NULL OR votes.rank = 1) GROUP BY votes.option_id
+        UNION ALL
+        SELECT ratings.option_id, extract(epoch FROM MAX(ratings.created_at)) FROM ratings WHERE ratings.poll_id = polls.id GROUP BY ratings.option_id
+    ) reached), '{}')::json AS reached_at,
+    COALESCE((SELECT array_agg(tags.name ORDER BY tags.name) FROM poll_tags JOIN tags ON tags.id = poll_tags.tag_id WHERE poll_tags.poll_id = polls.id), '{}')::text[] AS tags
+FROM
+    polls
+    JOIN users ON users.id = polls.user_id
+WHERE
+    polls.deleted_at IS NULL;
+
+-- +goose Down
+DROP VIEW poll_details;